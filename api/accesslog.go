@@ -0,0 +1,145 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"math"
+	"math/rand"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// statusWriter is a response writer saving status and size of response
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	size   int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.size += n
+	return n, err
+}
+
+// routeName returns name of the matched route or empty string
+func routeName(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		return route.GetName()
+	}
+	return ""
+}
+
+// requestKey returns key of request from path or the first key from query
+func requestKey(r *http.Request) string {
+	if key, ok := mux.Vars(r)["key"]; ok {
+		return key
+	}
+	return r.URL.Query().Get("key")
+}
+
+// AccessLogEntry is a struct for JSON access log record
+type AccessLogEntry struct {
+	Time    time.Time `json:"time"`
+	Method  string    `json:"method"`
+	Route   string    `json:"route"`
+	Key     string    `json:"key,omitempty"`
+	Status  int       `json:"status"`
+	Size    int       `json:"size"`
+	Latency int64     `json:"latency_us"`
+	Client  string    `json:"client"`
+}
+
+// accessLogContextKey is a request context key of access log entry
+type accessLogContextKey struct{}
+
+// setLogClient sets client of request access log entry, it's called by middlewares identifying clients
+func setLogClient(r *http.Request, name string) {
+	if e, ok := r.Context().Value(accessLogContextKey{}).(*AccessLogEntry); ok {
+		e.Client = name
+	}
+}
+
+// AccessLogger writes JSON access log of requests, one object per line.
+// Successful requests are sampled, errors are always written
+type AccessLogger struct {
+	// sample are bits of fraction of written successful requests, it's first to be aligned for atomic operations
+	sample uint64
+
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewAccessLogger returns logger writing to w fraction sample of successful requests
+func NewAccessLogger(w io.Writer, sample float64) *AccessLogger {
+	l := &AccessLogger{enc: json.NewEncoder(w)}
+	l.SetSample(sample)
+	return l
+}
+
+// SetSample changes fraction of written successful requests
+func (l *AccessLogger) SetSample(sample float64) {
+	atomic.StoreUint64(&l.sample, math.Float64bits(sample))
+}
+
+// sampled returns true if request with status must be written
+func (l *AccessLogger) sampled(status int) bool {
+	if status >= http.StatusBadRequest {
+		return true
+	}
+	return rand.Float64() < math.Float64frombits(atomic.LoadUint64(&l.sample))
+}
+
+// Middleware writes access log of requests, it must be the first middleware to log rejected requests.
+// Nil logger writes nothing
+func (l *AccessLogger) Middleware(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		e := &AccessLogEntry{
+			Time:   time.Now(),
+			Method: r.Method,
+			Route:  routeName(r),
+			Key:    requestKey(r),
+			Client: clientID(r),
+		}
+		sw := &statusWriter{ResponseWriter: w}
+
+		next.ServeHTTP(sw, r.WithContext(context.WithValue(r.Context(), accessLogContextKey{}, e)))
+
+		if sw.status == 0 {
+			sw.status = http.StatusOK
+		}
+		if !l.sampled(sw.status) {
+			return
+		}
+
+		e.Status = sw.status
+		e.Size = sw.size
+		e.Latency = time.Since(e.Time).Nanoseconds() / int64(time.Microsecond)
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if err := l.enc.Encode(e); err != nil {
+			log.Print(err)
+		}
+	})
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// accessLogEntries decodes access log written to buf and resets it
+func accessLogEntries(t *testing.T, buf *bytes.Buffer) []AccessLogEntry {
+	var entries []AccessLogEntry
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var e AccessLogEntry
+		if err := dec.Decode(&e); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, e)
+	}
+	buf.Reset()
+	return entries
+}
+
+func TestAccessLogger_Middleware(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewAccessLogger(&buf, 0)
+	router := newTestRouter(t, l.Middleware)
+
+	// test successful requests are sampled and errors are always written
+	serve(router, http.MethodPost, "/set/k", `{"value":"v"}`)
+	serve(router, http.MethodGet, "/get/missing", "")
+	entries := accessLogEntries(t, &buf)
+	if len(entries) != 1 {
+		t.Fatalf("expected error only, got %+v", entries)
+	}
+	if e := entries[0]; e.Method != http.MethodGet || e.Route != "get" || e.Key != "missing" || e.Status != http.StatusNotFound ||
+		e.Size == 0 || e.Client != "192.0.2.1" || e.Time.IsZero() {
+		t.Errorf("wrong entry %+v", e)
+	}
+
+	l.SetSample(1)
+	serve(router, http.MethodGet, "/get/k", "")
+	serve(router, http.MethodGet, "/exists?key=k", "")
+	entries = accessLogEntries(t, &buf)
+	if len(entries) != 2 || entries[0].Status != http.StatusOK || entries[1].Route != "exists" || entries[1].Key != "k" {
+		t.Errorf("wrong entries %+v", entries)
+	}
+}
+
+func TestAccessLogger_Client(t *testing.T) {
+	var buf bytes.Buffer
+	acl := newTestACL(t, Token{Name: "app", Token: "app", Rules: []Rule{{Permission: PermissionRead}}})
+	router := newTestRouter(t, NewAccessLogger(&buf, 1).Middleware, acl.Middleware)
+
+	// test client is named by ACL and rejected requests are written
+	serveAs(router, "app", http.MethodGet, "/keys", "")
+	serveAs(router, "app", http.MethodPost, "/set/k", `{"value":"v"}`)
+	serveAs(router, "", http.MethodGet, "/keys", "")
+	entries := accessLogEntries(t, &buf)
+	if len(entries) != 3 {
+		t.Fatalf("wrong entries %+v", entries)
+	}
+	for i, expected := range []struct {
+		client string
+		status int
+	}{
+		{"app", http.StatusOK},
+		{"app", http.StatusForbidden},
+		{"192.0.2.1", http.StatusUnauthorized},
+	} {
+		if e := entries[i]; e.Client != expected.client || e.Status != expected.status {
+			t.Errorf("expected %+v, got %+v", expected, e)
+		}
+	}
+}
@@ -0,0 +1,411 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Permission is an access level granted by ACL rule, higher level includes lower ones
+type Permission int
+
+// Permissions
+const (
+	PermissionNone Permission = iota
+	PermissionRead
+	PermissionWrite
+	PermissionAdmin
+)
+
+var permissionNames = map[string]Permission{
+	"read":  PermissionRead,
+	"write": PermissionWrite,
+	"admin": PermissionAdmin,
+}
+
+func (p *Permission) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+
+	perm, ok := permissionNames[name]
+	if !ok {
+		return fmt.Errorf("unknown permission %q", name)
+	}
+	*p = perm
+	return nil
+}
+
+// Rule grants permission for commands on keys with prefix in the database, empty commands match all commands.
+// Empty database is the default one, "*" matches all databases.
+// Commands without keys (keys, flushdb, indexes, etc.) require a rule with empty prefix
+type Rule struct {
+	DB         string     `json:"db"`
+	Prefix     string     `json:"prefix"`
+	Permission Permission `json:"permission"`
+	Commands   []string   `json:"commands"`
+}
+
+// allDatabases is a rule database matching all databases
+const allDatabases = "*"
+
+// matchDB returns true if rule is applied to the database, empty name is the default database
+func (rule *Rule) matchDB(db string) bool {
+	if rule.DB == allDatabases {
+		return true
+	}
+
+	ruleDB := rule.DB
+	if ruleDB == "" {
+		ruleDB = defaultDatabase
+	}
+	if db == "" {
+		db = defaultDatabase
+	}
+	return ruleDB == db
+}
+
+// Token is a client identity with its ACL rules. Client is identified by bearer token
+// or by verified TLS certificate with common name equal to the token name, so token can be empty
+type Token struct {
+	Name  string `json:"name"`
+	Token string `json:"token"`
+	Rules []Rule `json:"rules"`
+}
+
+// allowed returns true if any rule grants permission for command on key of the database
+func (t *Token) allowed(db, command string, perm Permission, key string) bool {
+	for _, rule := range t.Rules {
+		if rule.Permission < perm || !rule.matchDB(db) || !strings.HasPrefix(key, rule.Prefix) {
+			continue
+		}
+		if len(rule.Commands) == 0 {
+			return true
+		}
+		for _, c := range rule.Commands {
+			if c == command {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasDatabase returns true if any rule is applied to the database
+func (t *Token) hasDatabase(db string) bool {
+	for _, rule := range t.Rules {
+		if rule.matchDB(db) {
+			return true
+		}
+	}
+	return false
+}
+
+// command is an ACL description of API route
+type command struct {
+	perm Permission
+	// keyless commands affect the whole keyspace
+	keyless bool
+	// sources are read keys passed in JSON payload as "keys"
+	sources bool
+}
+
+// commands are ACL descriptions by route names, unknown routes require admin permission for the whole keyspace
+var commands = map[string]command{
+	"keys":          {perm: PermissionRead, keyless: true},
+	"stats":         {perm: PermissionRead, keyless: true},
+	"get":           {perm: PermissionRead},
+	"set":           {perm: PermissionWrite},
+	"getraw":        {perm: PermissionRead},
+	"setraw":        {perm: PermissionWrite},
+	"remove":        {perm: PermissionWrite},
+	"expire":        {perm: PermissionWrite},
+	"hget":          {perm: PermissionRead},
+	"hset":          {perm: PermissionWrite},
+	"hdel":          {perm: PermissionWrite},
+	"hexpire":       {perm: PermissionWrite},
+	"httl":          {perm: PermissionRead},
+	"hpersist":      {perm: PermissionWrite},
+	"zadd":          {perm: PermissionWrite},
+	"zrem":          {perm: PermissionWrite},
+	"zscore":        {perm: PermissionRead},
+	"zrank":         {perm: PermissionRead},
+	"zrange":        {perm: PermissionRead},
+	"zrangebyscore": {perm: PermissionRead},
+	"zincrby":       {perm: PermissionWrite},
+	"zpopmin":       {perm: PermissionWrite},
+	"sadd":          {perm: PermissionWrite},
+	"srem":          {perm: PermissionWrite},
+	"sismember":     {perm: PermissionRead},
+	"smembers":      {perm: PermissionRead},
+	"sinter":        {perm: PermissionRead},
+	"sunion":        {perm: PermissionRead},
+	"sdiff":         {perm: PermissionRead},
+	"lpush":         {perm: PermissionWrite},
+	"rpush":         {perm: PermissionWrite},
+	"lpop":          {perm: PermissionWrite},
+	"rpop":          {perm: PermissionWrite},
+	"blpop":         {perm: PermissionWrite},
+	"brpop":         {perm: PermissionWrite},
+	"llen":          {perm: PermissionRead},
+	"lrange":        {perm: PermissionRead},
+	"xadd":          {perm: PermissionWrite},
+	"xlen":          {perm: PermissionRead},
+	"xrange":        {perm: PermissionRead},
+	"xread":         {perm: PermissionRead},
+	"xgroupcreate":  {perm: PermissionWrite},
+	"xreadgroup":    {perm: PermissionWrite},
+	"xack":          {perm: PermissionWrite},
+	"xpending":      {perm: PermissionRead},
+	"pfadd":         {perm: PermissionWrite},
+	"pfcount":       {perm: PermissionRead},
+	"pfmerge":       {perm: PermissionWrite, sources: true},
+	"setbit":        {perm: PermissionWrite},
+	"getbit":        {perm: PermissionRead},
+	"bitcount":      {perm: PermissionRead},
+	"bitpos":        {perm: PermissionRead},
+	"bitop":         {perm: PermissionWrite, sources: true},
+	"geoadd":        {perm: PermissionWrite},
+	"geopos":        {perm: PermissionRead},
+	"geodist":       {perm: PermissionRead},
+	"geosearch":     {perm: PermissionRead},
+	"jsonget":       {perm: PermissionRead},
+	"jsonset":       {perm: PermissionWrite},
+	"jsondel":       {perm: PermissionWrite},
+	"jsonarrappend": {perm: PermissionWrite},
+	"jsonnumincrby": {perm: PermissionWrite},
+	"indexcreate":   {perm: PermissionAdmin, keyless: true},
+	"indexdrop":     {perm: PermissionAdmin, keyless: true},
+	"indexquery":    {perm: PermissionRead, keyless: true},
+	"exists":        {perm: PermissionRead},
+	"type":          {perm: PermissionRead},
+	"dbsize":        {perm: PermissionRead, keyless: true},
+	"rename":        {perm: PermissionWrite},
+	"copy":          {perm: PermissionWrite},
+	"flushdb":       {perm: PermissionAdmin, keyless: true},
+	"flushall":      {perm: PermissionAdmin, keyless: true},
+	"metrics":       {perm: PermissionAdmin, keyless: true},
+	"configget":     {perm: PermissionAdmin, keyless: true},
+	"configset":     {perm: PermissionAdmin, keyless: true},
+	"slowlog":       {perm: PermissionAdmin, keyless: true},
+	"slowlogreset":  {perm: PermissionAdmin, keyless: true},
+}
+
+var (
+	errorUnauthorized = errors.New("missing or invalid token")
+	errorForbidden    = errors.New("access denied")
+)
+
+// tokenContextKey is a request context key of authenticated token
+type tokenContextKey struct{}
+
+// ClientName returns token name of client authenticated by ACL middleware or empty string
+func ClientName(r *http.Request) string {
+	if t, ok := r.Context().Value(tokenContextKey{}).(*Token); ok {
+		return t.Name
+	}
+	return ""
+}
+
+// ACL is a set of client identities with access rules
+type ACL struct {
+	// tokens are indexed by hash, so lookup time doesn't depend on token prefix
+	tokens map[[sha256.Size]byte]*Token
+	names  map[string]*Token
+}
+
+// NewACL returns ACL of tokens, names must be unique
+func NewACL(tokens []Token) (*ACL, error) {
+	acl := &ACL{
+		tokens: make(map[[sha256.Size]byte]*Token, len(tokens)),
+		names:  make(map[string]*Token, len(tokens)),
+	}
+	for i := range tokens {
+		t := &tokens[i]
+		if t.Name == "" {
+			return nil, errors.New("empty token name")
+		}
+		if _, ok := acl.names[t.Name]; ok {
+			return nil, fmt.Errorf("duplicate token name %q", t.Name)
+		}
+		acl.names[t.Name] = t
+
+		if t.Token == "" {
+			continue
+		}
+		sum := sha256.Sum256([]byte(t.Token))
+		if _, ok := acl.tokens[sum]; ok {
+			return nil, fmt.Errorf("duplicate token %q", t.Name)
+		}
+		acl.tokens[sum] = t
+	}
+	return acl, nil
+}
+
+// LoadACL reads ACL from JSON file like {"tokens":[{"name":"app","token":"secret","rules":[...]}]}
+func LoadACL(path string) (*ACL, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var conf struct {
+		Tokens []Token `json:"tokens"`
+	}
+	if err = json.Unmarshal(data, &conf); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return NewACL(conf.Tokens)
+}
+
+// token returns token by Authorization header or by verified client certificate if the header is missing,
+// returns nil if client is unknown
+func (a *ACL) token(r *http.Request) *Token {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		if !strings.HasPrefix(auth, "Bearer ") {
+			return nil
+		}
+		return a.tokens[sha256.Sum256([]byte(auth[len("Bearer "):]))]
+	}
+
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+		return a.names[r.TLS.VerifiedChains[0][0].Subject.CommonName]
+	}
+	return nil
+}
+
+// authorize returns denied key or errorForbidden if token has no access to the routed request
+func (a *ACL) authorize(t *Token, r *http.Request) (string, error) {
+	name := ""
+	if route := mux.CurrentRoute(r); route != nil {
+		name = route.GetName()
+	}
+
+	db := mux.Vars(r)["db"]
+	cmd, ok := commands[name]
+	if !ok {
+		cmd = command{perm: PermissionAdmin, keyless: true}
+	}
+
+	if cmd.keyless {
+		if !t.allowed(db, name, cmd.perm, "") {
+			return "", errorForbidden
+		}
+		return "", nil
+	}
+
+	vars := mux.Vars(r)
+	keys := r.URL.Query()["key"]
+	for _, v := range []string{"key", "newkey"} {
+		if key, ok := vars[v]; ok {
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range keys {
+		if !t.allowed(db, name, cmd.perm, key) {
+			return key, errorForbidden
+		}
+	}
+
+	if !cmd.sources {
+		return "", nil
+	}
+
+	// payload is read to check source keys and restored for the handler
+	payload, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return "", errorWrongPayload
+	}
+	r.Body.Close()
+	r.Body = ioutil.NopCloser(bytes.NewReader(payload))
+
+	var params struct {
+		Keys []string `json:"keys"`
+	}
+	if err := readJSON(bytes.NewReader(payload), &params); err != nil {
+		return "", errorWrongPayload
+	}
+	for _, key := range params.Keys {
+		if !t.allowed(db, name, PermissionRead, key) {
+			return key, errorForbidden
+		}
+	}
+	return "", nil
+}
+
+// unauthorized writes error of missing or invalid token
+func unauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="keyval"`)
+	writeError(w, "", errorUnauthorized)
+}
+
+// AuthorizeDatabase checks bearer token of request and its access to the database selected by path,
+// it's used before routing, so databases aren't created for clients without rules in them. Nil ACL allows all requests
+func (a *ACL) AuthorizeDatabase(next http.Handler) http.Handler {
+	if a == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t := a.token(r)
+		if t == nil {
+			unauthorized(w)
+			return
+		}
+		if !t.hasDatabase(mux.Vars(r)["db"]) {
+			writeError(w, "", errorForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Identify stores token of authenticated client in request context without rejecting requests,
+// so middlewares before Middleware like rate limiter identify clients by token name. Nil ACL identifies nobody
+func (a *ACL) Identify(next http.Handler) http.Handler {
+	if a == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if t := a.token(r); t != nil {
+			setLogClient(r, t.Name)
+			r = r.WithContext(context.WithValue(r.Context(), tokenContextKey{}, t))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Middleware checks bearer token of request and its access to the routed command and keys.
+// Nil ACL allows all requests
+func (a *ACL) Middleware(next http.Handler) http.Handler {
+	if a == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t := a.token(r)
+		if t == nil {
+			unauthorized(w)
+			return
+		}
+		setLogClient(r, t.Name)
+
+		if key, err := a.authorize(t, r); err != nil {
+			writeError(w, key, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), tokenContextKey{}, t)))
+	})
+}
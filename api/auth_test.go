@@ -0,0 +1,185 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/alexxeis/keyval/storage"
+	"github.com/gorilla/mux"
+)
+
+// newTestACL returns ACL of tokens or fails test
+func newTestACL(t *testing.T, tokens ...Token) *ACL {
+	acl, err := NewACL(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return acl
+}
+
+// serveAs makes request with bearer token to router and returns response status
+func serveAs(router http.Handler, token, method, target, body string) int {
+	r := newRequest(method, target, body)
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	return serveRequest(router, r).Code
+}
+
+func TestACL_Databases(t *testing.T) {
+	acl := newTestACL(t,
+		Token{Name: "default", Token: "default", Rules: []Rule{{Permission: PermissionWrite}}},
+		Token{Name: "users", Token: "users", Rules: []Rule{{DB: "users", Permission: PermissionWrite}}},
+		Token{Name: "all", Token: "all", Rules: []Rule{{DB: "*", Prefix: "k", Permission: PermissionRead}}},
+	)
+
+	s := storage.NewStorage(0)
+	defer s.Shutdown()
+	router := mux.NewRouter()
+	router.Use(acl.Middleware)
+	NewHandler(s).Register(router.PathPrefix(DatabasePrefix).Subrouter())
+	NewHandler(s).Register(router.PathPrefix("/api").Subrouter())
+
+	tests := []struct {
+		token  string
+		method string
+		target string
+		status int
+	}{
+		{"default", http.MethodGet, "/api/get/k", http.StatusNotFound},
+		{"default", http.MethodGet, "/api/db/0/get/k", http.StatusNotFound},
+		{"default", http.MethodGet, "/api/db/users/get/k", http.StatusForbidden},
+		{"users", http.MethodGet, "/api/get/k", http.StatusForbidden},
+		{"users", http.MethodGet, "/api/db/users/get/k", http.StatusNotFound},
+		{"users", http.MethodGet, "/api/db/other/get/k", http.StatusForbidden},
+		{"all", http.MethodGet, "/api/get/k", http.StatusNotFound},
+		{"all", http.MethodGet, "/api/db/other/get/k", http.StatusNotFound},
+		{"all", http.MethodGet, "/api/db/other/get/a", http.StatusForbidden},
+		{"all", http.MethodPost, "/api/db/other/remove/k", http.StatusForbidden},
+	}
+	for _, test := range tests {
+		if status := serveAs(router, test.token, test.method, test.target, ""); status != test.status {
+			t.Errorf("%s %s %s: expected %d, got %d", test.token, test.method, test.target, test.status, status)
+		}
+	}
+}
+
+func TestACL_Middleware(t *testing.T) {
+	acl := newTestACL(t,
+		Token{Name: "admin", Token: "admin", Rules: []Rule{{Permission: PermissionAdmin}}},
+		Token{Name: "app", Token: "app", Rules: []Rule{
+			{Prefix: "user:", Permission: PermissionWrite},
+			{Permission: PermissionRead, Commands: []string{"get", "exists"}},
+		}},
+		Token{Name: "reader", Token: "reader", Rules: []Rule{{Permission: PermissionRead}}},
+		// client identified by certificate only
+		Token{Name: "svc", Rules: []Rule{{Prefix: "svc:", Permission: PermissionWrite}}},
+	)
+
+	router := newTestRouter(t, acl.Middleware)
+	router.HandleFunc("/unknown", func(w http.ResponseWriter, r *http.Request) {}).Name("unknown")
+
+	tests := []struct {
+		name   string
+		token  string
+		method string
+		target string
+		body   string
+		status int
+	}{
+		{"no token", "", http.MethodGet, "/get/user:1", "", http.StatusUnauthorized},
+		{"wrong token", "wrong", http.MethodGet, "/get/user:1", "", http.StatusUnauthorized},
+
+		{"key with prefix", "app", http.MethodPost, "/set/user:1", `{"value":"v"}`, http.StatusOK},
+		{"key without prefix", "app", http.MethodPost, "/set/other", `{"value":"v"}`, http.StatusForbidden},
+		{"command of rule", "app", http.MethodGet, "/get/other", "", http.StatusNotFound},
+		{"command out of rule", "app", http.MethodGet, "/hget/other/f", "", http.StatusForbidden},
+		{"read permission", "reader", http.MethodGet, "/get/user:1", "", http.StatusOK},
+		{"write permission", "reader", http.MethodPost, "/remove/user:1", "", http.StatusForbidden},
+
+		{"keyless with empty prefix", "reader", http.MethodGet, "/keys", "", http.StatusOK},
+		{"keyless without empty prefix", "app", http.MethodGet, "/dbsize", "", http.StatusForbidden},
+		{"keyless admin command", "reader", http.MethodPost, "/flushdb", "", http.StatusForbidden},
+		{"keyless admin command of admin", "admin", http.MethodGet, "/dbsize", "", http.StatusOK},
+
+		{"new key with prefix", "app", http.MethodPost, "/copy/user:1/user:2", "", http.StatusOK},
+		{"new key without prefix", "app", http.MethodPost, "/copy/user:1/other", "", http.StatusForbidden},
+		{"query keys", "app", http.MethodGet, "/exists?key=user:1&key=other", "", http.StatusOK},
+		{"query keys without prefix", "app", http.MethodGet, "/sinter?key=user:1&key=other", "", http.StatusForbidden},
+		{"query keys with prefix", "app", http.MethodGet, "/sinter?key=user:s1&key=user:s2", "", http.StatusOK},
+
+		{"sources with prefix", "app", http.MethodPost, "/pfmerge/user:p", `{"keys":["user:a"]}`, http.StatusOK},
+		{"sources without prefix", "app", http.MethodPost, "/pfmerge/user:p", `{"keys":["user:a","other"]}`, http.StatusForbidden},
+		{"sources readable by admin", "admin", http.MethodPost, "/bitop/or/d", `{"keys":["other"]}`, http.StatusOK},
+		{"sources with trailing data", "app", http.MethodPost, "/bitop/or/user:d", `{"keys":["other"]} x`, http.StatusBadRequest},
+		{"sources with trailing value", "app", http.MethodPost, "/pfmerge/user:p", `{"keys":["user:a"]}{"keys":["other"]}`, http.StatusBadRequest},
+		{"wrong sources", "app", http.MethodPost, "/bitop/or/user:d", `{"keys":`, http.StatusBadRequest},
+
+		{"unknown route", "reader", http.MethodGet, "/unknown", "", http.StatusForbidden},
+		{"unknown route of admin", "admin", http.MethodGet, "/unknown", "", http.StatusOK},
+	}
+	for _, test := range tests {
+		if status := serveAs(router, test.token, test.method, test.target, test.body); status != test.status {
+			t.Errorf("%s: %s %s expected %d, got %d", test.name, test.method, test.target, test.status, status)
+		}
+	}
+
+	// test client is identified by certificate common name without Authorization header
+	withCert := func(cn, token, target string) int {
+		r := newRequest(http.MethodPost, target, `{"value":"v"}`)
+		r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: cn}}}}}
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		return serveRequest(router, r).Code
+	}
+	if status := withCert("svc", "", "/set/svc:1"); status != http.StatusOK {
+		t.Errorf("expected access by certificate, got %d", status)
+	}
+	if status := withCert("svc", "", "/set/user:1"); status != http.StatusForbidden {
+		t.Errorf("expected forbidden key of certificate client, got %d", status)
+	}
+	if status := withCert("unknown", "", "/set/svc:1"); status != http.StatusUnauthorized {
+		t.Errorf("expected unknown certificate client, got %d", status)
+	}
+	// token has priority over certificate
+	if status := withCert("svc", "reader", "/set/svc:1"); status != http.StatusForbidden {
+		t.Errorf("expected token client, got %d", status)
+	}
+}
+
+func TestLoadACL(t *testing.T) {
+	dir := t.TempDir()
+	write := func(data string) string {
+		path := filepath.Join(dir, "acl.json")
+		if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	acl, err := LoadACL(write(`{"tokens":[{"name":"app","token":"secret","rules":[{"db":"*","prefix":"user:","permission":"write","commands":["set"]}]}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rule := acl.names["app"].Rules[0]
+	if rule.DB != "*" || rule.Prefix != "user:" || rule.Permission != PermissionWrite || len(rule.Commands) != 1 {
+		t.Errorf("wrong rule %+v", rule)
+	}
+
+	for name, data := range map[string]string{
+		"unknown permission": `{"tokens":[{"name":"app","rules":[{"permission":"root"}]}]}`,
+		"empty name":         `{"tokens":[{"token":"secret"}]}`,
+		"duplicate name":     `{"tokens":[{"name":"app","token":"a"},{"name":"app","token":"b"}]}`,
+		"duplicate token":    `{"tokens":[{"name":"a","token":"secret"},{"name":"b","token":"secret"}]}`,
+	} {
+		if _, err := LoadACL(write(data)); err == nil {
+			t.Errorf("no error for %s", name)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/alexxeis/keyval/storage"
+	"github.com/gorilla/mux"
+)
+
+// Bit is a struct for JSON bit object
+type Bit struct {
+	Bit int `json:"bit"`
+}
+
+// BitopParams is a struct for JSON bitopParams object
+type BitopParams struct {
+	Keys []string `json:"keys"`
+}
+
+// Position is a struct for JSON position object
+type Position struct {
+	Position int `json:"position"`
+}
+
+// parseBit returns bit value by 0 or 1
+func parseBit(v int) (bool, error) {
+	if v != 0 && v != 1 {
+		return false, errorWrongBit
+	}
+	return v == 1, nil
+}
+
+// bitInt returns 0 or 1 by bit value
+func bitInt(bit bool) int {
+	if bit {
+		return 1
+	}
+	return 0
+}
+
+// pathOffset returns bit offset from path variables
+func pathOffset(vars map[string]string) (int, error) {
+	offset, err := strconv.Atoi(vars["offset"])
+	if err != nil {
+		return 0, storage.ErrorBitOffset
+	}
+	return offset, nil
+}
+
+func (h *handler) Setbit(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	offset, err := pathOffset(vars)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	var params Bit
+	if err = readJSON(r.Body, &params); err != nil {
+		writeError(w, key, errorWrongPayload)
+		return
+	}
+
+	bit, err := parseBit(params.Bit)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	old, err := h.storage.Setbit(r.Context(), key, offset, bit)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeContent(w, Bit{bitInt(old)})
+}
+
+func (h *handler) Getbit(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	offset, err := pathOffset(vars)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	bit, err := h.storage.Getbit(r.Context(), key, offset)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeContent(w, Bit{bitInt(bit)})
+}
+
+func (h *handler) Bitcount(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	start, err := queryInt(r, "start", 0)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	end, err := queryInt(r, "end", -1)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	n, err := h.storage.Bitcount(r.Context(), key, start, end)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeContent(w, Count{n})
+}
+
+func (h *handler) Bitpos(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	v, err := strconv.Atoi(vars["bit"])
+	if err != nil {
+		writeError(w, key, errorWrongBit)
+		return
+	}
+
+	bit, err := parseBit(v)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	start, err := queryInt(r, "start", 0)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	end, err := queryInt(r, "end", -1)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	pos, err := h.storage.Bitpos(r.Context(), key, bit, start, end)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeContent(w, Position{pos})
+}
+
+func (h *handler) Bitop(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	var params BitopParams
+	if err := readJSON(r.Body, &params); err != nil || len(params.Keys) == 0 {
+		writeError(w, key, errorWrongPayload)
+		return
+	}
+
+	n, err := h.storage.Bitop(r.Context(), storage.BitOp(vars["op"]), key, params.Keys...)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeContent(w, Count{n})
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/alexxeis/keyval/api"
+)
+
+// bitInt returns 0 or 1 by bit value
+func bitInt(bit bool) int {
+	if bit {
+		return 1
+	}
+	return 0
+}
+
+// rangeQuery returns query string with byte range
+func rangeQuery(start, end int) string {
+	return "?" + url.Values{
+		"start": {strconv.Itoa(start)},
+		"end":   {strconv.Itoa(end)},
+	}.Encode()
+}
+
+func (c *Client) Setbit(ctx context.Context, key string, offset int, bit bool) (bool, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, "/setbit/"+url.PathEscape(key)+"/"+strconv.Itoa(offset), api.Bit{Bit: bitInt(bit)})
+	if err != nil {
+		return false, err
+	}
+
+	res := &api.Bit{}
+	err = c.process(req, res)
+	return res.Bit == 1, err
+}
+
+func (c *Client) Getbit(ctx context.Context, key string, offset int) (bool, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/getbit/"+url.PathEscape(key)+"/"+strconv.Itoa(offset), nil)
+	if err != nil {
+		return false, err
+	}
+
+	res := &api.Bit{}
+	err = c.process(req, res)
+	return res.Bit == 1, err
+}
+
+func (c *Client) Bitcount(ctx context.Context, key string, start, end int) (int, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/bitcount/"+url.PathEscape(key)+rangeQuery(start, end), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	cnt := &api.Count{}
+	err = c.process(req, cnt)
+	return cnt.Count, err
+}
+
+func (c *Client) Bitpos(ctx context.Context, key string, bit bool, start, end int) (int, error) {
+	path := "/bitpos/" + url.PathEscape(key) + "/" + strconv.Itoa(bitInt(bit)) + rangeQuery(start, end)
+	req, err := c.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	res := &api.Position{}
+	err = c.process(req, res)
+	return res.Position, err
+}
+
+// Bitop stores result of "and", "or", "xor" or "not" operation over values by keys in dest, returns its length
+func (c *Client) Bitop(ctx context.Context, op, dest string, keys ...string) (int, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, "/bitop/"+url.PathEscape(op)+"/"+url.PathEscape(dest), api.BitopParams{Keys: keys})
+	if err != nil {
+		return 0, err
+	}
+
+	cnt := &api.Count{}
+	err = c.process(req, cnt)
+	return cnt.Count, err
+}
@@ -0,0 +1,92 @@
+package client_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alexxeis/keyval/api"
+)
+
+func TestClient_Setbit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/setbit/k/7" {
+			t.Error("wrong url:", r.URL.String())
+		}
+
+		var params api.Bit
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			t.Error(err)
+		}
+		if params.Bit != 1 {
+			t.Error("wrong bit ", params.Bit)
+		}
+		w.Write([]byte(`{"bit":0}`))
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	old, err := c.Setbit(ctx, "k", 7, true)
+	if err != nil {
+		t.Error(err)
+	}
+	if old {
+		t.Error("expected previous bit 0")
+	}
+}
+
+func TestClient_Bitpos(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/bitpos/k/0?end=-1&start=2" {
+			t.Error("wrong url:", r.URL.String())
+		}
+		w.Write([]byte(`{"position":17}`))
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	pos, err := c.Bitpos(ctx, "k", false, 2, -1)
+	if err != nil {
+		t.Error(err)
+	}
+	if pos != 17 {
+		t.Error("position expected 17, got ", pos)
+	}
+}
+
+func TestClient_Bitop(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/bitop/and/dest" {
+			t.Error("wrong url:", r.URL.String())
+		}
+
+		var params api.BitopParams
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			t.Error(err)
+		}
+		if len(params.Keys) != 2 || params.Keys[0] != "k1" || params.Keys[1] != "k2" {
+			t.Error("wrong keys ", params.Keys)
+		}
+		w.Write([]byte(`{"count":3}`))
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	n, err := c.Bitop(ctx, "and", "dest", "k1", "k2")
+	if err != nil {
+		t.Error(err)
+	}
+	if n != 3 {
+		t.Error("count expected 3, got ", n)
+	}
+}
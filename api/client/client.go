@@ -2,53 +2,298 @@ package client
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
 	"encoding/json"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/alexxeis/keyval/api"
+	"github.com/alexxeis/keyval/tracing"
+)
+
+const (
+	// DefaultBaseURL is an API URL of local server with default settings
+	DefaultBaseURL = "http://localhost:8000/api"
+	// DefaultUserAgent is a user agent of requests if it isn't set by option
+	DefaultUserAgent = "keyval-go-client"
 )
 
-// Client is an API client
+// Client is an API client, it's safe for concurrent use
 type Client struct {
-	host       string
+	baseURL    string
 	userAgent  string
+	token      string
 	httpClient *http.Client
+	tlsConfig  *tls.Config
+	timeout    time.Duration
+	retry      RetryPolicy
+}
+
+// Option is an optional client setting
+type Option func(*Client)
+
+// WithBaseURL sets API URL like https://keyval.example.com/api, paths of commands are appended to it
+func WithBaseURL(u string) Option {
+	return func(c *Client) {
+		c.baseURL = u
+	}
+}
+
+// WithUserAgent sets user agent of requests
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.userAgent = ua
+	}
+}
+
+// WithHTTPClient sets HTTP client of requests, client with transport of NewTransport is used by default
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithTLSConfig sets TLS configuration of client connections, e.g. root CAs or client certificate for mutual TLS.
+// HTTP client is copied with a new transport of NewTransport
+func WithTLSConfig(config *tls.Config) Option {
+	return func(c *Client) {
+		c.tlsConfig = config
+	}
+}
+
+// WithTimeout limits duration of every attempt of request including reading of response,
+// blocking commands aren't limited as they wait for data up to their own timeout
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+// WithRetry sets retry policy of idempotent requests, requests aren't retried by default
+func WithRetry(p RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = p
+	}
 }
 
-// NewClient returns new API client
-func NewClient(h string, ua string, c *http.Client) *Client {
-	return &Client{
-		host:       h,
-		userAgent:  ua,
-		httpClient: c,
+// NewTransport returns HTTP transport tuned for API requests: idle connections to the server are kept
+// for concurrent requests instead of two by default, dial and TLS handshake are limited by timeouts
+func NewTransport() *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ExpectContinueTimeout: time.Second,
 	}
 }
 
-// newRequest creates new HTTP request
-func (c *Client) newRequest(method, path string, body interface{}) (*http.Request, error) {
-	var buf io.ReadWriter
+// NewClient returns new API client of DefaultBaseURL configured by options
+func NewClient(opts ...Option) (*Client, error) {
+	c := &Client{
+		baseURL:   DefaultBaseURL,
+		userAgent: DefaultUserAgent,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	u, err := url.Parse(c.baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("keyval: wrong base URL: %v", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+		return nil, fmt.Errorf("keyval: wrong base URL %q", c.baseURL)
+	}
+	c.baseURL = strings.TrimSuffix(u.String(), "/")
+
+	if c.httpClient == nil {
+		c.httpClient = &http.Client{Transport: NewTransport()}
+	}
+	if c.tlsConfig != nil {
+		hc := *c.httpClient
+		t := NewTransport()
+		t.TLSClientConfig = c.tlsConfig
+		hc.Transport = t
+		c.httpClient = &hc
+	}
+	return c, nil
+}
+
+// WithToken returns client authenticated with bearer token sharing HTTP client with c
+func (c *Client) WithToken(token string) *Client {
+	tc := *c
+	tc.token = token
+	return &tc
+}
+
+// setHeaders sets common headers of API request
+func (c *Client) setHeaders(req *http.Request) {
+	req.Header.Set("User-Agent", c.userAgent)
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+}
+
+// newRequest creates new HTTP request cancelled with ctx, path must have escaped segments
+func (c *Client) newRequest(ctx context.Context, method, path string, body interface{}) (*http.Request, error) {
+	var buf io.Reader
 	if body != nil {
-		buf = new(bytes.Buffer)
-		if err := json.NewEncoder(buf).Encode(body); err != nil {
+		b := new(bytes.Buffer)
+		if err := json.NewEncoder(b).Encode(body); err != nil {
 			return nil, err
 		}
+		buf = b
 	}
 
-	req, err := http.NewRequest(method, c.host+path, buf)
+	req, err := http.NewRequest(method, c.baseURL+path, buf)
 	if err != nil {
 		return nil, err
 	}
+	req = req.WithContext(ctx)
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("User-Agent", c.userAgent)
+	c.setHeaders(req)
+
+	return req, nil
+}
+
+// newRawRequest creates new HTTP request with binary body cancelled with ctx
+func (c *Client) newRawRequest(ctx context.Context, method, path string, body io.Reader, contentType string) (*http.Request, error) {
+	req, err := http.NewRequest(method, c.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	c.setHeaders(req)
 
 	return req, nil
 }
 
+// blockingKey is a context key marking requests of blocking commands
+type blockingKey struct{}
+
+// blocking returns ctx of blocking command request, it isn't limited by client timeout
+func blocking(ctx context.Context) context.Context {
+	return context.WithValue(ctx, blockingKey{}, true)
+}
+
+// do makes HTTP request to API retrying it by policy and checks response status, caller must close response body
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	ctx, span := tracing.StartClient(req.Context(), "keyval "+req.Method)
+	if span != nil {
+		defer span.End()
+		span.SetAttribute("http.method", req.Method)
+		span.SetAttribute("http.url", req.URL.String())
+		req = req.WithContext(ctx)
+	}
+	tracing.Inject(req.Context(), req.Header)
+
+	attempt := 1
+	defer func() {
+		if attempt > 1 {
+			span.SetAttribute("http.resend_count", attempt-1)
+		}
+	}()
+
+	for ; ; attempt++ {
+		resp, err := c.send(req)
+		if err == nil {
+			span.SetAttribute("http.status_code", resp.StatusCode)
+			if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+				return resp, nil
+			}
+		}
+
+		delay, retry := c.retry.delay(req, resp, err, attempt)
+		if resp != nil {
+			err = newError(resp)
+			resp.Body.Close()
+		}
+		if retry {
+			next, rerr := rewind(req, delay)
+			if rerr == nil {
+				req = next
+				continue
+			}
+			err = rerr
+		}
+		span.SetError(err)
+		return nil, err
+	}
+}
+
+// send makes one attempt of request limited by client timeout
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	if c.timeout <= 0 || req.Context().Value(blockingKey{}) != nil {
+		return c.httpClient.Do(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), c.timeout)
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// rewind waits for delay and returns copy of request with reset body for the next attempt,
+// it returns error of request context if it's done while waiting
+func rewind(req *http.Request, delay time.Duration) (*http.Request, error) {
+	t := time.NewTimer(delay)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+
+	next := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		next.Body = body
+	}
+	return next, nil
+}
+
+// cancelBody is a response body cancelling context of attempt on close
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
 // process makes HTTP requests to API
 func (c *Client) process(req *http.Request, v interface{}) error {
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return err
 	}
@@ -60,3 +305,17 @@ func (c *Client) process(req *http.Request, v interface{}) error {
 
 	return json.NewDecoder(resp.Body).Decode(v)
 }
+
+// newError returns API error by unsuccessful response
+func newError(resp *http.Response) error {
+	var e api.Error
+	// body can be empty or not JSON, status is enough in this case
+	_ = json.NewDecoder(resp.Body).Decode(&e)
+
+	return &Error{
+		StatusCode: resp.StatusCode,
+		Code:       e.Code,
+		Message:    e.Message,
+		Key:        e.Key,
+	}
+}
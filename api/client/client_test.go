@@ -1,16 +1,37 @@
 package client_test
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/alexxeis/keyval/api"
 	"github.com/alexxeis/keyval/api/client"
+	"github.com/alexxeis/keyval/tracing"
 )
 
+var ctx = context.Background()
+
+// newClient returns client of test server with options
+func newClient(t *testing.T, server *httptest.Server, opts ...client.Option) *client.Client {
+	c, err := client.NewClient(append([]client.Option{
+		client.WithBaseURL(server.URL),
+		client.WithUserAgent("go-client"),
+		client.WithHTTPClient(server.Client()),
+	}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
 func TestClient_Keys(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
@@ -23,8 +44,8 @@ func TestClient_Keys(t *testing.T) {
 	}))
 	defer server.Close()
 
-	c := client.NewClient(server.URL, "go-client", server.Client())
-	keys, err := c.Keys()
+	c := newClient(t, server)
+	keys, err := c.Keys(ctx)
 	if err != nil {
 		t.Error(err)
 	}
@@ -40,6 +61,29 @@ func TestClient_Keys(t *testing.T) {
 	}
 }
 
+func TestClient_Stats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/stats" {
+			t.Error("wrong url:", r.URL.String())
+		}
+		w.Write([]byte(`{"keys":3,"compressed":1,"raw_size":100,"compressed_size":10}`))
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	st, err := c.Stats(ctx)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if st.Keys != 3 || st.Compressed != 1 || st.RawSize != 100 || st.CompressedSize != 10 {
+		t.Errorf("wrong stats %+v", st)
+	}
+}
+
 func TestClient_Get(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
@@ -52,8 +96,8 @@ func TestClient_Get(t *testing.T) {
 	}))
 	defer server.Close()
 
-	c := client.NewClient(server.URL, "go-client", server.Client())
-	val, err := c.Get("k")
+	c := newClient(t, server)
+	val, err := c.Get(ctx, "k")
 	if err != nil {
 		t.Error(err)
 	}
@@ -89,12 +133,12 @@ func TestClient_Set(t *testing.T) {
 	}))
 	defer server.Close()
 
-	c := client.NewClient(server.URL, "go-client", server.Client())
+	c := newClient(t, server)
 	params := &api.SetParams{
 		Value: "v",
 		Ttl:   10,
 	}
-	if err := c.Set("k", params); err != nil {
+	if err := c.Set(ctx, "k", params); err != nil {
 		t.Error(err)
 	}
 }
@@ -125,11 +169,69 @@ func TestClient_Expire(t *testing.T) {
 	}))
 	defer server.Close()
 
-	c := client.NewClient(server.URL, "go-client", server.Client())
+	c := newClient(t, server)
 	ttl := &api.Ttl{Ttl: 10}
-	if err := c.Expire("k", ttl); err != nil {
+	if err := c.Expire(ctx, "k", ttl); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestClient_SetRaw(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/raw/k?ttl=10" {
+			t.Error("wrong url:", r.URL.String())
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "image/png" {
+			t.Error("wrong content type ", ct)
+		}
+
+		payload, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		r.Body.Close()
+
+		if !bytes.Equal(payload, []byte{0, 255}) {
+			t.Error("wrong payload ", payload)
+		}
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	if err := c.SetRaw(ctx, "k", bytes.NewReader([]byte{0, 255}), "image/png", 10*time.Millisecond); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestClient_GetRaw(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/raw/k" {
+			t.Error("wrong url:", r.URL.String())
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte{0, 255})
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	var buf bytes.Buffer
+	ct, err := c.GetRaw(ctx, "k", &buf)
+	if err != nil {
 		t.Error(err)
 	}
+
+	if !bytes.Equal(buf.Bytes(), []byte{0, 255}) {
+		t.Error("wrong value ", buf.Bytes())
+	}
+	if ct != "image/png" {
+		t.Error("content type expected image/png, got ", ct)
+	}
 }
 
 func TestClient_Remove(t *testing.T) {
@@ -143,8 +245,8 @@ func TestClient_Remove(t *testing.T) {
 	}))
 	defer server.Close()
 
-	c := client.NewClient(server.URL, "go-client", server.Client())
-	if err := c.Remove("k"); err != nil {
+	c := newClient(t, server)
+	if err := c.Remove(ctx, "k"); err != nil {
 		t.Error(err)
 	}
 }
@@ -161,8 +263,8 @@ func TestClient_Hget(t *testing.T) {
 	}))
 	defer server.Close()
 
-	c := client.NewClient(server.URL, "go-client", server.Client())
-	val, err := c.Hget("k", "f")
+	c := newClient(t, server)
+	val, err := c.Hget(ctx, "k", "f")
 	if err != nil {
 		t.Error(err)
 	}
@@ -198,8 +300,8 @@ func TestClient_Hset(t *testing.T) {
 	}))
 	defer server.Close()
 
-	c := client.NewClient(server.URL, "go-client", server.Client())
-	if err := c.Hset("k", "f", "v"); err != nil {
+	c := newClient(t, server)
+	if err := c.Hset(ctx, "k", "f", "v"); err != nil {
 		t.Error(err)
 	}
 }
@@ -215,8 +317,352 @@ func TestClient_Hdel(t *testing.T) {
 	}))
 	defer server.Close()
 
-	c := client.NewClient(server.URL, "go-client", server.Client())
-	if err := c.Hdel("k", "f"); err != nil {
+	c := newClient(t, server)
+	if err := c.Hdel(ctx, "k", "f"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestClient_Hexpire(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/hexpire/k/f" {
+			t.Error("wrong url:", r.URL.String())
+		}
+
+		payload, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		r.Body.Close()
+
+		var ttl api.Ttl
+		if err = json.Unmarshal(payload, &ttl); err != nil {
+			t.Error(err)
+		}
+
+		if ttl.Ttl != 10 {
+			t.Error("wrong payload ", string(payload))
+		}
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	if err := c.Hexpire(ctx, "k", "f", &api.Ttl{Ttl: 10}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestClient_Httl(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/httl/k/f" {
+			t.Error("wrong url:", r.URL.String())
+		}
+		w.Write([]byte(`{"ttl":10}`))
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	ttl, err := c.Httl(ctx, "k", "f")
+	if err != nil {
+		t.Error(err)
+	}
+	if ttl.Ttl != 10 {
+		t.Error("ttl expected 10, got ", ttl.Ttl)
+	}
+}
+
+func TestClient_Hpersist(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/hpersist/k/f" {
+			t.Error("wrong url:", r.URL.String())
+		}
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	if err := c.Hpersist(ctx, "k", "f"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestClient_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.String() {
+		case "/get/k":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":"not_found","message":"no such key","key":"k"}`))
+		case "/hget/k/f":
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"code":"wrong_type","message":"wrong kind of value","key":"k"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+
+	// test not found
+	_, err := c.Get(ctx, "k")
+	if !client.IsNotFound(err) {
+		t.Error("expected not found error, got ", err)
+	}
+	e, ok := err.(*client.Error)
+	if !ok {
+		t.Fatal("expected *client.Error, got ", err)
+	}
+	if e.Code != "not_found" || e.Message != "no such key" || e.Key != "k" {
+		t.Errorf("wrong error %+v", e)
+	}
+
+	// test wrong type
+	if _, err = c.Hget(ctx, "k", "f"); !client.IsWrongType(err) {
+		t.Error("expected wrong type error, got ", err)
+	}
+
+	// test empty body
+	if err = c.Remove(ctx, "k"); !client.IsBadRequest(err) {
+		t.Error("expected bad request error, got ", err)
+	}
+}
+
+func TestClient_DB(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/db/users/flushdb" {
+			t.Error("wrong url:", r.URL.String())
+		}
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	if err := c.DB("users").Flushdb(ctx); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestClient_WithToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("Authorization") {
+		case "Bearer secret":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"code":"forbidden","message":"access denied","key":"k"}`))
+		case "":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			t.Error("wrong authorization ", r.Header.Get("Authorization"))
+		}
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	if _, err := c.Get(ctx, "k"); !client.IsUnauthorized(err) {
+		t.Error("expected unauthorized error, got ", err)
+	}
+
+	// test token is kept by database client
+	if _, err := c.WithToken("secret").DB("users").Get(ctx, "k"); !client.IsForbidden(err) {
+		t.Error("expected forbidden error, got ", err)
+	}
+}
+
+func TestClient_WithTLSConfig(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"value":"v"}`))
+	}))
+	defer server.Close()
+
+	// test server certificate isn't trusted by default
+	c, err := client.NewClient(client.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get(ctx, "k"); err == nil {
+		t.Error("expected certificate error")
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	c, err = client.NewClient(client.WithBaseURL(server.URL), client.WithTLSConfig(&tls.Config{RootCAs: pool}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := c.Get(ctx, "k"); err != nil || v != "v" {
+		t.Errorf("expected value = v, got %s (%v)", v, err)
+	}
+}
+
+func TestClient_Metrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/metrics" {
+			t.Error("wrong url:", r.URL.String())
+		}
+		w.Write([]byte(`{"clients":2,"allowed":10,"rate_limited":3,"too_large":1}`))
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	m, err := c.Metrics(ctx)
+	if err != nil {
+		t.Error(err)
+	}
+	if m.Clients != 2 || m.Allowed != 10 || m.RateLimited != 3 || m.TooLarge != 1 {
+		t.Errorf("wrong metrics %+v", m)
+	}
+}
+
+func TestClient_ConfigGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/config?key=limits.rate" {
+			t.Error("wrong url:", r.URL.String())
+		}
+		w.Write([]byte(`{"limits.rate":"10"}`))
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	values, err := c.ConfigGet(ctx, "limits.rate")
+	if err != nil {
 		t.Error(err)
 	}
+	if len(values) != 1 || values["limits.rate"] != "10" {
+		t.Errorf("wrong values %v", values)
+	}
+}
+
+func TestClient_ConfigSet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/config" {
+			t.Error("wrong url:", r.URL.String())
+		}
+
+		var values map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&values); err != nil {
+			t.Error(err)
+		}
+		if len(values) != 1 || values["storage.clean_interval"] != "5s" {
+			t.Errorf("wrong values %v", values)
+		}
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	if err := c.ConfigSet(ctx, map[string]string{"storage.clean_interval": "5s"}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestClient_Slowlog(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/slowlog?count=1" {
+			t.Error("wrong url:", r.URL.String())
+		}
+		w.Write([]byte(`{"entries":[{"id":3,"time":"2019-05-01T10:00:00Z","duration_us":15000,"command":"keys","client":"admin"}],"len":3}`))
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	entries, n, err := c.Slowlog(ctx, 1)
+	if err != nil {
+		t.Error(err)
+	}
+	if n != 3 || len(entries) != 1 {
+		t.Fatalf("wrong slow log %v, len %d", entries, n)
+	}
+	if e := entries[0]; e.ID != 3 || e.Duration != 15000 || e.Command != "keys" || e.Client != "admin" {
+		t.Errorf("wrong entry %+v", e)
+	}
+}
+
+func TestClient_SlowlogReset(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/slowlog" {
+			t.Error("wrong url:", r.URL.String())
+		}
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	if err := c.SlowlogReset(ctx); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestClient_Context(t *testing.T) {
+	tracer := tracing.NewTracer("test", 1, nil)
+	ctx, span := tracer.Start(context.Background(), "app", tracing.KindInternal, tracing.SpanContext{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sc := tracing.Extract(r.Header)
+		if sc.TraceID != span.Context().TraceID || sc.SpanID == span.Context().SpanID || !sc.Sampled {
+			t.Errorf("wrong trace context %q", r.Header.Get(tracing.TraceparentHeader))
+		}
+		w.Write([]byte(`{"value":"v"}`))
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	if _, err := c.Get(ctx, "k"); err != nil {
+		t.Error(err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := c.Get(ctx, "k"); err == nil {
+		t.Error("expected cancellation error")
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	for _, u := range []string{"", "localhost:8000", "ftp://localhost/api", "http://localhost/api?db=1", "http://%zz"} {
+		if _, err := client.NewClient(client.WithBaseURL(u)); err == nil {
+			t.Errorf("no error for base URL %q", u)
+		}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p := r.URL.EscapedPath(); p != "/api/db/my%20db/get/a%2Fb%3Fc" {
+			t.Error("wrong path:", p)
+		}
+		if ua := r.Header.Get("User-Agent"); ua != client.DefaultUserAgent {
+			t.Error("wrong user agent:", ua)
+		}
+		w.Write([]byte(`{"value":"v"}`))
+	}))
+	defer server.Close()
+
+	// test trailing slash of base URL and escaped path segments
+	c, err := client.NewClient(client.WithBaseURL(server.URL+"/api/"), client.WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := c.DB("my db").Get(ctx, "a/b?c"); err != nil || v != "v" {
+		t.Errorf("expected value = v, got %s (%v)", v, err)
+	}
 }
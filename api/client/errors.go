@@ -0,0 +1,77 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Error is an API error returned for unsuccessful response status
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+	Key        string
+}
+
+func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+
+	if e.Key != "" {
+		return fmt.Sprintf("keyval: %d %s: %s", e.StatusCode, e.Key, msg)
+	}
+	return fmt.Sprintf("keyval: %d: %s", e.StatusCode, msg)
+}
+
+// IsBadRequest returns true if err is an API error caused by incorrect request
+func IsBadRequest(err error) bool {
+	return hasStatus(err, http.StatusBadRequest)
+}
+
+// IsNotFound returns true if err is an API error caused by missing key or field
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsWrongType returns true if err is an API error caused by operation against a key holding the wrong kind of value
+func IsWrongType(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+// IsTimeout returns true if err is an API error caused by passed blocking operation timeout
+func IsTimeout(err error) bool {
+	return hasStatus(err, http.StatusRequestTimeout)
+}
+
+// IsRequestTimeout returns true if err is an API error caused by passed request processing timeout
+func IsRequestTimeout(err error) bool {
+	return hasStatus(err, http.StatusGatewayTimeout)
+}
+
+// IsUnauthorized returns true if err is an API error caused by missing or invalid token
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+// IsForbidden returns true if err is an API error caused by denied access to command or key
+func IsForbidden(err error) bool {
+	return hasStatus(err, http.StatusForbidden)
+}
+
+// IsTooLarge returns true if err is an API error caused by exceeded request, key or value size limit
+func IsTooLarge(err error) bool {
+	return hasStatus(err, http.StatusRequestEntityTooLarge)
+}
+
+// IsRateLimited returns true if err is an API error caused by exceeded request rate
+func IsRateLimited(err error) bool {
+	return hasStatus(err, http.StatusTooManyRequests)
+}
+
+// hasStatus returns true if err is an API error with the given status
+func hasStatus(err error, status int) bool {
+	e, ok := err.(*Error)
+	return ok && e.StatusCode == status
+}
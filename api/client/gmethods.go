@@ -0,0 +1,56 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/alexxeis/keyval/api"
+)
+
+func (c *Client) Geoadd(ctx context.Context, key string, locations ...api.GeoLocation) (int, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, "/geoadd/"+url.PathEscape(key), api.GeoaddParams{Locations: locations})
+	if err != nil {
+		return 0, err
+	}
+
+	cnt := &api.Count{}
+	err = c.process(req, cnt)
+	return cnt.Count, err
+}
+
+// Geopos returns coordinates of members, missing members are nil
+func (c *Client) Geopos(ctx context.Context, key string, members ...string) ([]*api.GeoLocation, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/geopos/"+url.PathEscape(key)+"?"+url.Values{"member": members}.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var locations []*api.GeoLocation
+	err = c.process(req, &locations)
+	return locations, err
+}
+
+// Geodist returns distance in meters between members
+func (c *Client) Geodist(ctx context.Context, key, member1, member2 string) (float64, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/geodist/"+url.PathEscape(key)+"/"+url.PathEscape(member1)+"/"+url.PathEscape(member2), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	d := &api.Distance{}
+	err = c.process(req, d)
+	return d.Distance, err
+}
+
+// Geosearch returns members within radius or box ordered by distance
+func (c *Client) Geosearch(ctx context.Context, key string, q *api.GeoQuery) ([]api.GeoResult, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, "/geosearch/"+url.PathEscape(key), q)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []api.GeoResult
+	err = c.process(req, &results)
+	return results, err
+}
@@ -0,0 +1,65 @@
+package client_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alexxeis/keyval/api"
+)
+
+func TestClient_Geopos(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/geopos/k?member=a&member=b" {
+			t.Error("wrong url:", r.URL.String())
+		}
+		w.Write([]byte(`[{"member":"a","longitude":13.5,"latitude":38.5},null]`))
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	locations, err := c.Geopos(ctx, "k", "a", "b")
+	if err != nil {
+		t.Error(err)
+	}
+	if len(locations) != 2 || locations[0] == nil || locations[1] != nil {
+		t.Fatal("wrong locations ", locations)
+	}
+	if *locations[0] != (api.GeoLocation{Member: "a", Longitude: 13.5, Latitude: 38.5}) {
+		t.Error("wrong location ", *locations[0])
+	}
+}
+
+func TestClient_Geosearch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/geosearch/k" {
+			t.Error("wrong url:", r.URL.String())
+		}
+
+		var q api.GeoQuery
+		if err := json.NewDecoder(r.Body).Decode(&q); err != nil {
+			t.Error(err)
+		}
+		if q.Member != "a" || q.Radius != 1000 {
+			t.Error("wrong query ", q)
+		}
+		w.Write([]byte(`[{"member":"b","distance":10.5,"longitude":13.5,"latitude":38.5}]`))
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	results, err := c.Geosearch(ctx, "k", &api.GeoQuery{Member: "a", Radius: 1000})
+	if err != nil {
+		t.Error(err)
+	}
+	if len(results) != 1 || results[0].Member != "b" || results[0].Distance != 10.5 {
+		t.Error("wrong results ", results)
+	}
+}
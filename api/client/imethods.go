@@ -0,0 +1,45 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/alexxeis/keyval/api"
+)
+
+// IndexCreate declares secondary index of hash field for keys with prefix
+func (c *Client) IndexCreate(ctx context.Context, name, prefix, field string) error {
+	req, err := c.newRequest(ctx, http.MethodPost, "/index/"+url.PathEscape(name), api.IndexParams{Prefix: prefix, Field: field})
+	if err != nil {
+		return err
+	}
+	return c.process(req, nil)
+}
+
+func (c *Client) IndexDrop(ctx context.Context, name string) error {
+	req, err := c.newRequest(ctx, http.MethodDelete, "/index/"+url.PathEscape(name), nil)
+	if err != nil {
+		return err
+	}
+	return c.process(req, nil)
+}
+
+// IndexQuery returns page of up to count keys after the cursor with indexed field equal to value,
+// empty cursor starts from the first key
+func (c *Client) IndexQuery(ctx context.Context, name, value, after string, count int) (*api.IndexPage, error) {
+	q := url.Values{"value": {value}, "count": {strconv.Itoa(count)}}
+	if after != "" {
+		q.Set("after", after)
+	}
+
+	req, err := c.newRequest(ctx, http.MethodGet, "/index/"+url.PathEscape(name)+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &api.IndexPage{}
+	err = c.process(req, page)
+	return page, err
+}
@@ -0,0 +1,29 @@
+package client_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_IndexQuery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/index/country?after=user%3A1&count=2&value=DE" {
+			t.Error("wrong url:", r.URL.String())
+		}
+		w.Write([]byte(`{"keys":["user:2","user:3"],"next":"user:3"}`))
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	page, err := c.IndexQuery(ctx, "country", "DE", "user:1", 2)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(page.Keys) != 2 || page.Keys[0] != "user:2" || page.Next != "user:3" {
+		t.Error("wrong page ", page)
+	}
+}
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/alexxeis/keyval/api"
+)
+
+// jsonPath returns JSON document path with path query
+func jsonPath(key, path string) string {
+	return "/json/" + url.PathEscape(key) + "?" + url.Values{"path": {path}}.Encode()
+}
+
+// JSONGet decodes JSON value at path of the document into v
+func (c *Client) JSONGet(ctx context.Context, key, path string, v interface{}) error {
+	req, err := c.newRequest(ctx, http.MethodGet, jsonPath(key, path), nil)
+	if err != nil {
+		return err
+	}
+	return c.process(req, v)
+}
+
+// JSONSet sets v encoded to JSON at path of the document, "$" path creates the document
+func (c *Client) JSONSet(ctx context.Context, key, path string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	req, err := c.newRawRequest(ctx, http.MethodPost, jsonPath(key, path), bytes.NewReader(data), "application/json")
+	if err != nil {
+		return err
+	}
+	return c.process(req, nil)
+}
+
+// JSONDel deletes value at path of the document, returns count of deleted values
+func (c *Client) JSONDel(ctx context.Context, key, path string) (int, error) {
+	req, err := c.newRequest(ctx, http.MethodDelete, jsonPath(key, path), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	cnt := &api.Count{}
+	err = c.process(req, cnt)
+	return cnt.Count, err
+}
+
+// JSONArrAppend appends values encoded to JSON to the array at path, returns array length
+func (c *Client) JSONArrAppend(ctx context.Context, key, path string, values ...interface{}) (int, error) {
+	params := api.JSONArrAppendParams{Values: make([]json.RawMessage, len(values))}
+	for i, v := range values {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return 0, err
+		}
+		params.Values[i] = data
+	}
+
+	p := "/json/" + url.PathEscape(key) + "/arrappend?" + url.Values{"path": {path}}.Encode()
+	req, err := c.newRequest(ctx, http.MethodPost, p, params)
+	if err != nil {
+		return 0, err
+	}
+
+	cnt := &api.Count{}
+	err = c.process(req, cnt)
+	return cnt.Count, err
+}
+
+// JSONNumIncrBy increments number at path, returns new number
+func (c *Client) JSONNumIncrBy(ctx context.Context, key, path string, incr float64) (float64, error) {
+	p := "/json/" + url.PathEscape(key) + "/numincrby?" + url.Values{"path": {path}}.Encode()
+	req, err := c.newRequest(ctx, http.MethodPost, p, api.Incr{Incr: incr})
+	if err != nil {
+		return 0, err
+	}
+
+	var n float64
+	err = c.process(req, &n)
+	return n, err
+}
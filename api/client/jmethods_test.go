@@ -0,0 +1,109 @@
+package client_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alexxeis/keyval/api"
+)
+
+func TestClient_JSONSet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/json/k?path=%24.a" {
+			t.Error("wrong url:", r.URL.String())
+		}
+
+		payload, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		if string(payload) != `{"b":[1,2]}` {
+			t.Error("wrong payload ", string(payload))
+		}
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	if err := c.JSONSet(ctx, "k", "$.a", map[string][]int{"b": {1, 2}}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestClient_JSONGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/json/k?path=%24.a" {
+			t.Error("wrong url:", r.URL.String())
+		}
+		w.Write([]byte(`{"b":[1,2]}`))
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	var v struct {
+		B []int `json:"b"`
+	}
+	if err := c.JSONGet(ctx, "k", "$.a", &v); err != nil {
+		t.Error(err)
+	}
+	if len(v.B) != 2 || v.B[0] != 1 || v.B[1] != 2 {
+		t.Error("wrong value ", v)
+	}
+}
+
+func TestClient_JSONArrAppend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/json/k/arrappend?path=%24.a" {
+			t.Error("wrong url:", r.URL.String())
+		}
+
+		var params api.JSONArrAppendParams
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			t.Error(err)
+		}
+		if len(params.Values) != 2 || string(params.Values[0]) != `1` || string(params.Values[1]) != `"x"` {
+			t.Error("wrong values ", params.Values)
+		}
+		w.Write([]byte(`{"count":3}`))
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	n, err := c.JSONArrAppend(ctx, "k", "$.a", 1, "x")
+	if err != nil {
+		t.Error(err)
+	}
+	if n != 3 {
+		t.Error("count expected 3, got ", n)
+	}
+}
+
+func TestClient_JSONNumIncrBy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.String() != "/json/k/numincrby?path=%24.n" {
+			t.Error("wrong url:", r.URL.String())
+		}
+		w.Write([]byte(`3.5`))
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	n, err := c.JSONNumIncrBy(ctx, "k", "$.n", 1.5)
+	if err != nil {
+		t.Error(err)
+	}
+	if n != 3.5 {
+		t.Error("number expected 3.5, got ", n)
+	}
+}
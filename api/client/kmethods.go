@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/alexxeis/keyval/api"
+)
+
+func (c *Client) Exists(ctx context.Context, keys ...string) (int, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/exists?"+url.Values{"key": keys}.Encode(), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	cnt := &api.Count{}
+	err = c.process(req, cnt)
+	return cnt.Count, err
+}
+
+func (c *Client) Type(ctx context.Context, key string) (string, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/type/"+url.PathEscape(key), nil)
+	if err != nil {
+		return "", err
+	}
+
+	res := &api.Type{}
+	err = c.process(req, res)
+	return res.Type, err
+}
+
+func (c *Client) Dbsize(ctx context.Context) (int, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/dbsize", nil)
+	if err != nil {
+		return 0, err
+	}
+
+	cnt := &api.Count{}
+	err = c.process(req, cnt)
+	return cnt.Count, err
+}
+
+func (c *Client) Rename(ctx context.Context, key, newKey string) error {
+	req, err := c.newRequest(ctx, http.MethodPost, "/rename/"+url.PathEscape(key)+"/"+url.PathEscape(newKey), nil)
+	if err != nil {
+		return err
+	}
+	return c.process(req, nil)
+}
+
+func (c *Client) Copy(ctx context.Context, key, newKey string, replace bool) (bool, error) {
+	path := "/copy/" + url.PathEscape(key) + "/" + url.PathEscape(newKey)
+	if replace {
+		path += "?replace=true"
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, path, nil)
+	if err != nil {
+		return false, err
+	}
+
+	res := &api.Copied{}
+	err = c.process(req, res)
+	return res.Copied, err
+}
+
+// Flushall deletes all keys of all databases, c must not be a client of the selected database
+func (c *Client) Flushall(ctx context.Context) error {
+	req, err := c.newRequest(ctx, http.MethodPost, "/flushall", nil)
+	if err != nil {
+		return err
+	}
+	return c.process(req, nil)
+}
@@ -0,0 +1,90 @@
+package client_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_Exists(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/exists?key=k1&key=k2" {
+			t.Error("wrong url:", r.URL.String())
+		}
+		w.Write([]byte(`{"count":1}`))
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	n, err := c.Exists(ctx, "k1", "k2")
+	if err != nil {
+		t.Error(err)
+	}
+	if n != 1 {
+		t.Error("count expected 1, got ", n)
+	}
+}
+
+func TestClient_Type(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/type/k" {
+			t.Error("wrong url:", r.URL.String())
+		}
+		w.Write([]byte(`{"type":"hash"}`))
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	typ, err := c.Type(ctx, "k")
+	if err != nil {
+		t.Error(err)
+	}
+	if typ != "hash" {
+		t.Error("type expected hash, got ", typ)
+	}
+}
+
+func TestClient_Rename(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/rename/a/b" {
+			t.Error("wrong url:", r.URL.String())
+		}
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	if err := c.Rename(ctx, "a", "b"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestClient_Copy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/copy/a/b?replace=true" {
+			t.Error("wrong url:", r.URL.String())
+		}
+		w.Write([]byte(`{"copied":true}`))
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	copied, err := c.Copy(ctx, "a", "b", true)
+	if err != nil {
+		t.Error(err)
+	}
+	if !copied {
+		t.Error("expected copied")
+	}
+}
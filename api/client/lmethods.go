@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/alexxeis/keyval/api"
+)
+
+func (c *Client) Lpush(ctx context.Context, key string, vals ...string) (int, error) {
+	return c.push(ctx, "/lpush/"+url.PathEscape(key), vals)
+}
+
+func (c *Client) Rpush(ctx context.Context, key string, vals ...string) (int, error) {
+	return c.push(ctx, "/rpush/"+url.PathEscape(key), vals)
+}
+
+// push adds values to the list
+func (c *Client) push(ctx context.Context, path string, vals []string) (int, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, path, api.Values{Values: vals})
+	if err != nil {
+		return 0, err
+	}
+
+	cnt := &api.Count{}
+	err = c.process(req, cnt)
+	return cnt.Count, err
+}
+
+func (c *Client) Lpop(ctx context.Context, key string) (string, error) {
+	return c.pop(ctx, "/lpop/"+url.PathEscape(key))
+}
+
+func (c *Client) Rpop(ctx context.Context, key string) (string, error) {
+	return c.pop(ctx, "/rpop/"+url.PathEscape(key))
+}
+
+// Blpop waits for the first list element until timeout is passed or ctx is done, zero timeout waits until ctx is done
+func (c *Client) Blpop(ctx context.Context, key string, timeout time.Duration) (string, error) {
+	return c.pop(blocking(ctx), "/blpop/"+url.PathEscape(key)+timeoutQuery(timeout))
+}
+
+// Brpop waits for the last list element until timeout is passed or ctx is done, zero timeout waits until ctx is done
+func (c *Client) Brpop(ctx context.Context, key string, timeout time.Duration) (string, error) {
+	return c.pop(blocking(ctx), "/brpop/"+url.PathEscape(key)+timeoutQuery(timeout))
+}
+
+// timeoutQuery returns query string with timeout in milliseconds
+func timeoutQuery(timeout time.Duration) string {
+	if timeout <= 0 {
+		return ""
+	}
+	return "?timeout=" + strconv.FormatInt(int64(timeout/time.Millisecond), 10)
+}
+
+// pop deletes and returns list element
+func (c *Client) pop(ctx context.Context, path string) (string, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, path, nil)
+	if err != nil {
+		return "", err
+	}
+
+	val := &api.Value{}
+	err = c.process(req, val)
+	return val.Value, err
+}
+
+func (c *Client) Llen(ctx context.Context, key string) (int, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/llen/"+url.PathEscape(key), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	cnt := &api.Count{}
+	err = c.process(req, cnt)
+	return cnt.Count, err
+}
+
+func (c *Client) Lrange(ctx context.Context, key string, start, stop int) ([]string, error) {
+	q := url.Values{}
+	q.Set("start", strconv.Itoa(start))
+	q.Set("stop", strconv.Itoa(stop))
+
+	req, err := c.newRequest(ctx, http.MethodGet, "/lrange/"+url.PathEscape(key)+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var vals []string
+	err = c.process(req, &vals)
+	return vals, err
+}
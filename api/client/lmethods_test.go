@@ -0,0 +1,93 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alexxeis/keyval/api"
+	"github.com/alexxeis/keyval/api/client"
+)
+
+func TestClient_Rpush(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/rpush/k" {
+			t.Error("wrong url:", r.URL.String())
+		}
+
+		payload, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		r.Body.Close()
+
+		var params api.Values
+		if err = json.Unmarshal(payload, &params); err != nil {
+			t.Error(err)
+		}
+
+		if len(params.Values) != 2 || params.Values[0] != "a" || params.Values[1] != "b" {
+			t.Error("wrong payload ", string(payload))
+		}
+		w.Write([]byte(`{"count":2}`))
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	n, err := c.Rpush(ctx, "k", "a", "b")
+	if err != nil {
+		t.Error(err)
+	}
+	if n != 2 {
+		t.Error("count expected 2, got ", n)
+	}
+}
+
+func TestClient_Blpop(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Error("wrong method ", r.Method)
+		}
+
+		switch r.URL.String() {
+		case "/blpop/k?timeout=1000":
+			w.Write([]byte(`{"value":"v"}`))
+		case "/blpop/empty?timeout=10":
+			w.WriteHeader(http.StatusRequestTimeout)
+			w.Write([]byte(`{"code":"timeout","message":"timeout","key":"empty"}`))
+		case "/blpop/wait":
+			<-r.Context().Done()
+		default:
+			t.Error("wrong url:", r.URL.String())
+		}
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	val, err := c.Blpop(context.Background(), "k", time.Second)
+	if err != nil {
+		t.Error(err)
+	}
+	if val != "v" {
+		t.Error("value expected v, got ", val)
+	}
+
+	// test timeout
+	if _, err = c.Blpop(context.Background(), "empty", 10*time.Millisecond); !client.IsTimeout(err) {
+		t.Error("expected timeout error, got ", err)
+	}
+
+	// test cancel
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err = c.Blpop(ctx, "wait", 0); err == nil {
+		t.Error("expected context error")
+	}
+}
@@ -1,13 +1,18 @@
 package client
 
 import (
+	"context"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/alexxeis/keyval/api"
 )
 
-func (c *Client) Keys() ([]string, error) {
-	req, err := c.newRequest(http.MethodGet, "/keys", nil)
+func (c *Client) Keys(ctx context.Context) ([]string, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/keys", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -17,8 +22,19 @@ func (c *Client) Keys() ([]string, error) {
 	return keys, err
 }
 
-func (c *Client) Get(key string) (string, error) {
-	req, err := c.newRequest(http.MethodGet, "/get/"+key, nil)
+func (c *Client) Stats(ctx context.Context) (*api.Stats, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/stats", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	st := &api.Stats{}
+	err = c.process(req, st)
+	return st, err
+}
+
+func (c *Client) Get(ctx context.Context, key string) (string, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/get/"+url.PathEscape(key), nil)
 	if err != nil {
 		return "", err
 	}
@@ -28,32 +44,65 @@ func (c *Client) Get(key string) (string, error) {
 	return val.Value, err
 }
 
-func (c *Client) Set(key string, params *api.SetParams) error {
-	req, err := c.newRequest(http.MethodPost, "/set/"+key, params)
+func (c *Client) Set(ctx context.Context, key string, params *api.SetParams) error {
+	req, err := c.newRequest(ctx, http.MethodPost, "/set/"+url.PathEscape(key), params)
 	if err != nil {
 		return err
 	}
 	return c.process(req, nil)
 }
 
-func (c *Client) Expire(key string, ttl *api.Ttl) error {
-	req, err := c.newRequest(http.MethodPost, "/expire/"+key, ttl)
+func (c *Client) Expire(ctx context.Context, key string, ttl *api.Ttl) error {
+	req, err := c.newRequest(ctx, http.MethodPost, "/expire/"+url.PathEscape(key), ttl)
 	if err != nil {
 		return err
 	}
 	return c.process(req, nil)
 }
 
-func (c *Client) Remove(key string) error {
-	req, err := c.newRequest(http.MethodPost, "/remove/"+key, nil)
+// SetRaw stores binary value read from r with its content type, ttl is rounded to milliseconds
+func (c *Client) SetRaw(ctx context.Context, key string, r io.Reader, contentType string, ttl time.Duration) error {
+	path := "/raw/" + url.PathEscape(key)
+	if ttl > 0 {
+		path += "?ttl=" + strconv.FormatInt(int64(ttl/time.Millisecond), 10)
+	}
+
+	req, err := c.newRawRequest(ctx, http.MethodPost, path, r, contentType)
+	if err != nil {
+		return err
+	}
+	return c.process(req, nil)
+}
+
+// GetRaw writes binary value to w and returns its content type
+func (c *Client) GetRaw(ctx context.Context, key string, w io.Writer) (string, error) {
+	req, err := c.newRawRequest(ctx, http.MethodGet, "/raw/"+url.PathEscape(key), nil, "")
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if _, err = io.Copy(w, resp.Body); err != nil {
+		return "", err
+	}
+	return resp.Header.Get("Content-Type"), nil
+}
+
+func (c *Client) Remove(ctx context.Context, key string) error {
+	req, err := c.newRequest(ctx, http.MethodPost, "/remove/"+url.PathEscape(key), nil)
 	if err != nil {
 		return err
 	}
 	return c.process(req, nil)
 }
 
-func (c *Client) Hget(key, field string) (string, error) {
-	req, err := c.newRequest(http.MethodGet, "/hget/"+key+"/"+field, nil)
+func (c *Client) Hget(ctx context.Context, key, field string) (string, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/hget/"+url.PathEscape(key)+"/"+url.PathEscape(field), nil)
 	if err != nil {
 		return "", err
 	}
@@ -63,16 +112,120 @@ func (c *Client) Hget(key, field string) (string, error) {
 	return val.Value, err
 }
 
-func (c *Client) Hset(key, field, value string) error {
-	req, err := c.newRequest(http.MethodPost, "/hset/"+key+"/"+field, api.Value{Value: value})
+func (c *Client) Hset(ctx context.Context, key, field, value string) error {
+	req, err := c.newRequest(ctx, http.MethodPost, "/hset/"+url.PathEscape(key)+"/"+url.PathEscape(field), api.Value{Value: value})
+	if err != nil {
+		return err
+	}
+	return c.process(req, nil)
+}
+
+func (c *Client) Hdel(ctx context.Context, key, field string) error {
+	req, err := c.newRequest(ctx, http.MethodPost, "/hdel/"+url.PathEscape(key)+"/"+url.PathEscape(field), nil)
 	if err != nil {
 		return err
 	}
 	return c.process(req, nil)
 }
 
-func (c *Client) Hdel(key, field string) error {
-	req, err := c.newRequest(http.MethodPost, "/hdel/"+key+"/"+field, nil)
+func (c *Client) Hexpire(ctx context.Context, key, field string, ttl *api.Ttl) error {
+	req, err := c.newRequest(ctx, http.MethodPost, "/hexpire/"+url.PathEscape(key)+"/"+url.PathEscape(field), ttl)
+	if err != nil {
+		return err
+	}
+	return c.process(req, nil)
+}
+
+func (c *Client) Httl(ctx context.Context, key, field string) (*api.Ttl, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/httl/"+url.PathEscape(key)+"/"+url.PathEscape(field), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := &api.Ttl{}
+	err = c.process(req, ttl)
+	return ttl, err
+}
+
+func (c *Client) Hpersist(ctx context.Context, key, field string) error {
+	req, err := c.newRequest(ctx, http.MethodPost, "/hpersist/"+url.PathEscape(key)+"/"+url.PathEscape(field), nil)
+	if err != nil {
+		return err
+	}
+	return c.process(req, nil)
+}
+
+// Metrics returns request limits metrics, c must not be a client of the selected database
+func (c *Client) Metrics(ctx context.Context) (*api.Metrics, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/metrics", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &api.Metrics{}
+	err = c.process(req, m)
+	return m, err
+}
+
+// ConfigGet returns values of server settings by keys or all settings if keys are empty,
+// c must not be a client of the selected database
+func (c *Client) ConfigGet(ctx context.Context, keys ...string) (map[string]string, error) {
+	path := "/config"
+	if len(keys) > 0 {
+		path += "?" + url.Values{"key": keys}.Encode()
+	}
+
+	req, err := c.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	err = c.process(req, &values)
+	return values, err
+}
+
+// ConfigSet changes runtime settings of server all together, c must not be a client of the selected database
+func (c *Client) ConfigSet(ctx context.Context, values map[string]string) error {
+	req, err := c.newRequest(ctx, http.MethodPost, "/config", values)
+	if err != nil {
+		return err
+	}
+	return c.process(req, nil)
+}
+
+// Slowlog returns up to count the newest slow commands and count of all logged commands,
+// c must not be a client of the selected database
+func (c *Client) Slowlog(ctx context.Context, count int) ([]api.SlowLogEntry, int, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/slowlog?count="+strconv.Itoa(count), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	res := &api.SlowLogEntries{}
+	err = c.process(req, res)
+	return res.Entries, res.Len, err
+}
+
+// SlowlogReset deletes logged slow commands, c must not be a client of the selected database
+func (c *Client) SlowlogReset(ctx context.Context) error {
+	req, err := c.newRequest(ctx, http.MethodDelete, "/slowlog", nil)
+	if err != nil {
+		return err
+	}
+	return c.process(req, nil)
+}
+
+// DB returns client of the logical database by name sharing HTTP client with c
+func (c *Client) DB(name string) *Client {
+	db := *c
+	db.baseURL = c.baseURL + "/db/" + url.PathEscape(name)
+	return &db
+}
+
+// Flushdb deletes all keys of the database
+func (c *Client) Flushdb(ctx context.Context) error {
+	req, err := c.newRequest(ctx, http.MethodPost, "/flushdb", nil)
 	if err != nil {
 		return err
 	}
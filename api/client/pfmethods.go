@@ -0,0 +1,39 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/alexxeis/keyval/api"
+)
+
+func (c *Client) Pfadd(ctx context.Context, key string, elements ...string) (bool, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, "/pfadd/"+url.PathEscape(key), api.PfaddParams{Elements: elements})
+	if err != nil {
+		return false, err
+	}
+
+	res := &api.Changed{}
+	err = c.process(req, res)
+	return res.Changed, err
+}
+
+func (c *Client) Pfcount(ctx context.Context, keys ...string) (int, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/pfcount?"+url.Values{"key": keys}.Encode(), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	cnt := &api.Count{}
+	err = c.process(req, cnt)
+	return cnt.Count, err
+}
+
+func (c *Client) Pfmerge(ctx context.Context, dest string, sources ...string) error {
+	req, err := c.newRequest(ctx, http.MethodPost, "/pfmerge/"+url.PathEscape(dest), api.PfmergeParams{Keys: sources})
+	if err != nil {
+		return err
+	}
+	return c.process(req, nil)
+}
@@ -0,0 +1,95 @@
+package client_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alexxeis/keyval/api"
+)
+
+func TestClient_Pfadd(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/pfadd/k" {
+			t.Error("wrong url:", r.URL.String())
+		}
+
+		payload, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		r.Body.Close()
+
+		var params api.PfaddParams
+		if err = json.Unmarshal(payload, &params); err != nil {
+			t.Error(err)
+		}
+
+		if len(params.Elements) != 2 || params.Elements[0] != "a" || params.Elements[1] != "b" {
+			t.Error("wrong payload ", string(payload))
+		}
+		w.Write([]byte(`{"changed":true}`))
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	changed, err := c.Pfadd(ctx, "k", "a", "b")
+	if err != nil {
+		t.Error(err)
+	}
+	if !changed {
+		t.Error("expected changed")
+	}
+}
+
+func TestClient_Pfcount(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/pfcount?key=k1&key=k2" {
+			t.Error("wrong url:", r.URL.String())
+		}
+		w.Write([]byte(`{"count":42}`))
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	n, err := c.Pfcount(ctx, "k1", "k2")
+	if err != nil {
+		t.Error(err)
+	}
+	if n != 42 {
+		t.Error("count expected 42, got ", n)
+	}
+}
+
+func TestClient_Pfmerge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/pfmerge/dest" {
+			t.Error("wrong url:", r.URL.String())
+		}
+
+		var params api.PfmergeParams
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			t.Error(err)
+		}
+		if len(params.Keys) != 2 || params.Keys[0] != "k1" || params.Keys[1] != "k2" {
+			t.Error("wrong keys ", params.Keys)
+		}
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	if err := c.Pfmerge(ctx, "dest", "k1", "k2"); err != nil {
+		t.Error(err)
+	}
+}
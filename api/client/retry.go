@@ -0,0 +1,82 @@
+package client
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy defines retries of idempotent requests failed by network errors, attempt timeout
+// or 429, 502, 503 and 504 responses. Delay before retry grows exponentially from MinBackoff up to MaxBackoff
+type RetryPolicy struct {
+	// MaxAttempts is a max count of attempts including the first one, requests aren't retried if it's less than 2
+	MaxAttempts int
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+}
+
+// DefaultRetryPolicy makes up to 3 attempts with delays of 25-50ms and 50-100ms
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  50 * time.Millisecond,
+	MaxBackoff:  time.Second,
+}
+
+// idempotent are methods of requests which can be repeated safely, POST commands can be applied twice
+var idempotent = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+}
+
+// backoff returns delay after failed attempt starting from 1. Half of exponential delay is random,
+// so clients failed at once don't retry at once
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.MaxBackoff
+	if attempt < 32 {
+		if e := p.MinBackoff << uint(attempt-1); e > 0 && e < d {
+			d = e
+		}
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// delay returns delay before the next attempt of request failed by response or error,
+// it returns false if request mustn't be retried. Retry-After of 429 response longer than MaxBackoff isn't waited
+func (p RetryPolicy) delay(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	if attempt >= p.MaxAttempts || !idempotent[req.Method] || req.Context().Err() != nil {
+		return 0, false
+	}
+	// body can't be read again
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return 0, false
+	}
+
+	if err != nil {
+		return p.backoff(attempt), true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return p.backoff(attempt), true
+	case http.StatusTooManyRequests:
+		d := p.backoff(attempt)
+		if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			after := time.Duration(s) * time.Second
+			if after > p.MaxBackoff {
+				return 0, false
+			}
+			if after > d {
+				d = after
+			}
+		}
+		return d, true
+	}
+	return 0, false
+}
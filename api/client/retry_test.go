@@ -0,0 +1,136 @@
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alexxeis/keyval/api/client"
+)
+
+var testRetry = client.RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+
+func TestClient_Retry(t *testing.T) {
+	var attempts int32
+	var status int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the first two attempts fail
+		if atomic.AddInt32(&attempts, 1) <= 2 {
+			st := int(atomic.LoadInt32(&status))
+			if st == http.StatusTooManyRequests {
+				w.Header().Set("Retry-After", "0")
+			}
+			w.WriteHeader(st)
+			return
+		}
+		w.Write([]byte(`{"value":"v"}`))
+	}))
+	defer server.Close()
+
+	c := newClient(t, server, client.WithRetry(testRetry))
+	for _, st := range []int32{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusGatewayTimeout, http.StatusTooManyRequests} {
+		atomic.StoreInt32(&attempts, 0)
+		atomic.StoreInt32(&status, st)
+		if v, err := c.Get(ctx, "k"); err != nil || v != "v" {
+			t.Errorf("expected value = v after %d, got %s (%v)", st, v, err)
+		}
+		if n := atomic.LoadInt32(&attempts); n != 3 {
+			t.Errorf("expected 3 attempts after %d, got %d", st, n)
+		}
+	}
+
+	// test attempts are limited
+	atomic.StoreInt32(&attempts, 0)
+	atomic.StoreInt32(&status, http.StatusServiceUnavailable)
+	c2 := newClient(t, server, client.WithRetry(client.RetryPolicy{MaxAttempts: 2, MinBackoff: time.Millisecond}))
+	if _, err := c2.Get(ctx, "k"); err == nil || err.(*client.Error).StatusCode != http.StatusServiceUnavailable {
+		t.Error("expected unavailable error, got ", err)
+	}
+
+	// test not idempotent and not temporary failures aren't retried
+	for _, st := range []int32{http.StatusServiceUnavailable, http.StatusInternalServerError} {
+		atomic.StoreInt32(&attempts, 0)
+		atomic.StoreInt32(&status, st)
+		if st == http.StatusServiceUnavailable {
+			_ = c.Set(ctx, "k", nil)
+		} else {
+			_, _ = c.Get(ctx, "k")
+		}
+		if n := atomic.LoadInt32(&attempts); n != 1 {
+			t.Errorf("expected 1 attempt after %d, got %d", st, n)
+		}
+	}
+}
+
+func TestClient_RetryAfter(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	// test Retry-After longer than max backoff isn't waited
+	c := newClient(t, server, client.WithRetry(testRetry))
+	if _, err := c.Get(ctx, "k"); !client.IsRateLimited(err) {
+		t.Error("expected rate limited error, got ", err)
+	}
+	if n := atomic.LoadInt32(&attempts); n != 1 {
+		t.Errorf("expected 1 attempt, got %d", n)
+	}
+
+	// test waiting is cancelled with context
+	c = newClient(t, server, client.WithRetry(client.RetryPolicy{MaxAttempts: 3, MaxBackoff: time.Minute}))
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := c.Get(ctx, "k"); err != context.DeadlineExceeded {
+		t.Error("expected deadline error, got ", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Error("cancelled retry waited ", d)
+	}
+}
+
+func TestClient_Timeout(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the first attempt hangs
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+		w.Write([]byte(`{"value":"v"}`))
+	}))
+	defer server.Close()
+
+	c := newClient(t, server, client.WithTimeout(20*time.Millisecond))
+	if _, err := c.Get(ctx, "k"); err == nil {
+		t.Error("expected timeout error")
+	}
+
+	atomic.StoreInt32(&attempts, 0)
+	c = newClient(t, server, client.WithTimeout(20*time.Millisecond), client.WithRetry(testRetry))
+	if v, err := c.Get(ctx, "k"); err != nil || v != "v" {
+		t.Errorf("expected value = v, got %s (%v)", v, err)
+	}
+
+	// test blocking command isn't limited by timeout
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte(`{"value":"v"}`))
+	}))
+	defer server.Close()
+
+	c = newClient(t, server, client.WithTimeout(10*time.Millisecond))
+	if v, err := c.Blpop(ctx, "k", time.Second); err != nil || v != "v" {
+		t.Errorf("expected value = v, got %s (%v)", v, err)
+	}
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/alexxeis/keyval/api"
+)
+
+func (c *Client) Sadd(ctx context.Context, key string, members ...string) (int, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, "/sadd/"+url.PathEscape(key), api.Members{Members: members})
+	if err != nil {
+		return 0, err
+	}
+
+	cnt := &api.Count{}
+	err = c.process(req, cnt)
+	return cnt.Count, err
+}
+
+func (c *Client) Srem(ctx context.Context, key string, members ...string) (int, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, "/srem/"+url.PathEscape(key), api.Members{Members: members})
+	if err != nil {
+		return 0, err
+	}
+
+	cnt := &api.Count{}
+	err = c.process(req, cnt)
+	return cnt.Count, err
+}
+
+func (c *Client) Sismember(ctx context.Context, key, member string) (bool, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/sismember/"+url.PathEscape(key)+"/"+url.PathEscape(member), nil)
+	if err != nil {
+		return false, err
+	}
+
+	res := &api.IsMember{}
+	err = c.process(req, res)
+	return res.IsMember, err
+}
+
+func (c *Client) Smembers(ctx context.Context, key string) ([]string, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/smembers/"+url.PathEscape(key), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []string
+	err = c.process(req, &members)
+	return members, err
+}
+
+func (c *Client) Sinter(ctx context.Context, keys ...string) ([]string, error) {
+	return c.setAlgebra(ctx, "/sinter", keys)
+}
+
+func (c *Client) Sunion(ctx context.Context, keys ...string) ([]string, error) {
+	return c.setAlgebra(ctx, "/sunion", keys)
+}
+
+func (c *Client) Sdiff(ctx context.Context, keys ...string) ([]string, error) {
+	return c.setAlgebra(ctx, "/sdiff", keys)
+}
+
+// setAlgebra requests set operation against keys
+func (c *Client) setAlgebra(ctx context.Context, path string, keys []string) ([]string, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, path+"?"+url.Values{"key": keys}.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []string
+	err = c.process(req, &members)
+	return members, err
+}
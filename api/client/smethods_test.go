@@ -0,0 +1,92 @@
+package client_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alexxeis/keyval/api"
+)
+
+func TestClient_Sadd(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/sadd/k" {
+			t.Error("wrong url:", r.URL.String())
+		}
+
+		payload, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		r.Body.Close()
+
+		var params api.Members
+		if err = json.Unmarshal(payload, &params); err != nil {
+			t.Error(err)
+		}
+
+		if len(params.Members) != 2 || params.Members[0] != "a" || params.Members[1] != "b" {
+			t.Error("wrong payload ", string(payload))
+		}
+		w.Write([]byte(`{"count":2}`))
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	n, err := c.Sadd(ctx, "k", "a", "b")
+	if err != nil {
+		t.Error(err)
+	}
+	if n != 2 {
+		t.Error("count expected 2, got ", n)
+	}
+}
+
+func TestClient_Sismember(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/sismember/k/m" {
+			t.Error("wrong url:", r.URL.String())
+		}
+		w.Write([]byte(`{"ismember":true}`))
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	ok, err := c.Sismember(ctx, "k", "m")
+	if err != nil {
+		t.Error(err)
+	}
+	if !ok {
+		t.Error("expected member")
+	}
+}
+
+func TestClient_Sinter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/sinter?key=k1&key=k2" {
+			t.Error("wrong url:", r.URL.String())
+		}
+		w.Write([]byte(`["a"]`))
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	members, err := c.Sinter(ctx, "k1", "k2")
+	if err != nil {
+		t.Error(err)
+	}
+	if len(members) != 1 || members[0] != "a" {
+		t.Error("wrong members ", members)
+	}
+}
@@ -0,0 +1,121 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/alexxeis/keyval/api"
+)
+
+func (c *Client) Xadd(ctx context.Context, key, id string, fields map[string]string) (string, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, "/xadd/"+url.PathEscape(key), api.XaddParams{ID: id, Fields: fields})
+	if err != nil {
+		return "", err
+	}
+
+	res := &api.ID{}
+	err = c.process(req, res)
+	return res.ID, err
+}
+
+func (c *Client) Xlen(ctx context.Context, key string) (int, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/xlen/"+url.PathEscape(key), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	cnt := &api.Count{}
+	err = c.process(req, cnt)
+	return cnt.Count, err
+}
+
+func (c *Client) Xrange(ctx context.Context, key, start, end string, count int) ([]api.StreamEntry, error) {
+	q := url.Values{}
+	q.Set("start", start)
+	q.Set("end", end)
+	q.Set("count", strconv.Itoa(count))
+
+	req, err := c.newRequest(ctx, http.MethodGet, "/xrange/"+url.PathEscape(key)+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []api.StreamEntry
+	err = c.process(req, &entries)
+	return entries, err
+}
+
+// Xread returns entries with ID greater than after, negative timeout doesn't block, zero timeout blocks until ctx is done
+func (c *Client) Xread(ctx context.Context, key, after string, count int, timeout time.Duration) ([]api.StreamEntry, error) {
+	q := url.Values{}
+	q.Set("after", after)
+	q.Set("count", strconv.Itoa(count))
+	setTimeout(q, timeout)
+
+	req, err := c.newRequest(blocking(ctx), http.MethodGet, "/xread/"+url.PathEscape(key)+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []api.StreamEntry
+	err = c.process(req, &entries)
+	return entries, err
+}
+
+func (c *Client) XgroupCreate(ctx context.Context, key, group, start string) error {
+	req, err := c.newRequest(ctx, http.MethodPost, "/xgroup/"+url.PathEscape(key)+"/"+url.PathEscape(group), api.Start{Start: start})
+	if err != nil {
+		return err
+	}
+	return c.process(req, nil)
+}
+
+// Xreadgroup returns new group entries if id is ">" or consumer's pending entries with ID greater than id,
+// timeout is the same as in Xread
+func (c *Client) Xreadgroup(ctx context.Context, key, group, consumer, id string, count int, timeout time.Duration) ([]api.StreamEntry, error) {
+	q := url.Values{}
+	q.Set("id", id)
+	q.Set("count", strconv.Itoa(count))
+	setTimeout(q, timeout)
+
+	req, err := c.newRequest(blocking(ctx), http.MethodPost, "/xreadgroup/"+url.PathEscape(key)+"/"+url.PathEscape(group)+"/"+url.PathEscape(consumer)+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []api.StreamEntry
+	err = c.process(req, &entries)
+	return entries, err
+}
+
+func (c *Client) Xack(ctx context.Context, key, group string, ids ...string) (int, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, "/xack/"+url.PathEscape(key)+"/"+url.PathEscape(group), api.IDs{IDs: ids})
+	if err != nil {
+		return 0, err
+	}
+
+	cnt := &api.Count{}
+	err = c.process(req, cnt)
+	return cnt.Count, err
+}
+
+func (c *Client) Xpending(ctx context.Context, key, group string) ([]api.PendingEntry, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/xpending/"+url.PathEscape(key)+"/"+url.PathEscape(group), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []api.PendingEntry
+	err = c.process(req, &pending)
+	return pending, err
+}
+
+// setTimeout sets timeout query parameter in milliseconds, negative timeout is omitted
+func setTimeout(q url.Values, timeout time.Duration) {
+	if timeout >= 0 {
+		q.Set("timeout", strconv.FormatInt(int64(timeout/time.Millisecond), 10))
+	}
+}
@@ -0,0 +1,116 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alexxeis/keyval/api"
+)
+
+func TestClient_Xadd(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/xadd/k" {
+			t.Error("wrong url:", r.URL.String())
+		}
+
+		payload, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		r.Body.Close()
+
+		var params api.XaddParams
+		if err = json.Unmarshal(payload, &params); err != nil {
+			t.Error(err)
+		}
+
+		if params.ID != "*" || params.Fields["f"] != "v" {
+			t.Error("wrong payload ", string(payload))
+		}
+		w.Write([]byte(`{"id":"1-0"}`))
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	id, err := c.Xadd(ctx, "k", "*", map[string]string{"f": "v"})
+	if err != nil {
+		t.Error(err)
+	}
+	if id != "1-0" {
+		t.Error("id expected 1-0, got ", id)
+	}
+}
+
+func TestClient_Xreadgroup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/xreadgroup/k/g/c?count=10&id=%3E&timeout=1000" {
+			t.Error("wrong url:", r.URL.String())
+		}
+		w.Write([]byte(`[{"id":"1-0","fields":{"f":"v"}}]`))
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	entries, err := c.Xreadgroup(context.Background(), "k", "g", "c", ">", 10, time.Second)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(entries) != 1 || entries[0].ID != "1-0" || entries[0].Fields["f"] != "v" {
+		t.Error("wrong entries ", entries)
+	}
+}
+
+func TestClient_Xread(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/xread/k?after=%24&count=0" {
+			t.Error("wrong url:", r.URL.String())
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	entries, err := c.Xread(context.Background(), "k", "$", 0, -1)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(entries) != 0 {
+		t.Error("wrong entries ", entries)
+	}
+}
+
+func TestClient_Xpending(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/xpending/k/g" {
+			t.Error("wrong url:", r.URL.String())
+		}
+		w.Write([]byte(`[{"id":"1-0","consumer":"c","deliveries":2,"idle":100}]`))
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	pending, err := c.Xpending(ctx, "k", "g")
+	if err != nil {
+		t.Error(err)
+	}
+	if len(pending) != 1 || pending[0].Consumer != "c" || pending[0].Deliveries != 2 || pending[0].Idle != 100 {
+		t.Errorf("wrong pending %+v", pending)
+	}
+}
@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/alexxeis/keyval/api"
+)
+
+func (c *Client) Zadd(ctx context.Context, key string, members ...api.ZMember) (int, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, "/zadd/"+url.PathEscape(key), api.ZaddParams{Members: members})
+	if err != nil {
+		return 0, err
+	}
+
+	cnt := &api.Count{}
+	err = c.process(req, cnt)
+	return cnt.Count, err
+}
+
+func (c *Client) Zrem(ctx context.Context, key string, members ...string) (int, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, "/zrem/"+url.PathEscape(key), api.Members{Members: members})
+	if err != nil {
+		return 0, err
+	}
+
+	cnt := &api.Count{}
+	err = c.process(req, cnt)
+	return cnt.Count, err
+}
+
+func (c *Client) Zscore(ctx context.Context, key, member string) (float64, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/zscore/"+url.PathEscape(key)+"/"+url.PathEscape(member), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	score := &api.Score{}
+	err = c.process(req, score)
+	return score.Score, err
+}
+
+func (c *Client) Zrank(ctx context.Context, key, member string) (int, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/zrank/"+url.PathEscape(key)+"/"+url.PathEscape(member), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	rank := &api.Rank{}
+	err = c.process(req, rank)
+	return rank.Rank, err
+}
+
+func (c *Client) Zrange(ctx context.Context, key string, start, stop int) ([]api.ZMember, error) {
+	q := url.Values{}
+	q.Set("start", strconv.Itoa(start))
+	q.Set("stop", strconv.Itoa(stop))
+
+	req, err := c.newRequest(ctx, http.MethodGet, "/zrange/"+url.PathEscape(key)+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []api.ZMember
+	err = c.process(req, &members)
+	return members, err
+}
+
+func (c *Client) ZrangeByScore(ctx context.Context, key string, min, max float64) ([]api.ZMember, error) {
+	q := url.Values{}
+	q.Set("min", strconv.FormatFloat(min, 'g', -1, 64))
+	q.Set("max", strconv.FormatFloat(max, 'g', -1, 64))
+
+	req, err := c.newRequest(ctx, http.MethodGet, "/zrangebyscore/"+url.PathEscape(key)+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []api.ZMember
+	err = c.process(req, &members)
+	return members, err
+}
+
+func (c *Client) Zincrby(ctx context.Context, key, member string, incr float64) (float64, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, "/zincrby/"+url.PathEscape(key)+"/"+url.PathEscape(member), api.Incr{Incr: incr})
+	if err != nil {
+		return 0, err
+	}
+
+	score := &api.Score{}
+	err = c.process(req, score)
+	return score.Score, err
+}
+
+func (c *Client) Zpopmin(ctx context.Context, key string, count int) ([]api.ZMember, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, "/zpopmin/"+url.PathEscape(key), api.Count{Count: count})
+	if err != nil {
+		return nil, err
+	}
+
+	var members []api.ZMember
+	err = c.process(req, &members)
+	return members, err
+}
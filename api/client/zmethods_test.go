@@ -0,0 +1,116 @@
+package client_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alexxeis/keyval/api"
+)
+
+func TestClient_Zadd(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/zadd/k" {
+			t.Error("wrong url:", r.URL.String())
+		}
+
+		payload, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		r.Body.Close()
+
+		var params api.ZaddParams
+		if err = json.Unmarshal(payload, &params); err != nil {
+			t.Error(err)
+		}
+
+		if len(params.Members) != 1 || params.Members[0].Member != "m" || params.Members[0].Score != 1.5 {
+			t.Error("wrong payload ", string(payload))
+		}
+		w.Write([]byte(`{"count":1}`))
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	n, err := c.Zadd(ctx, "k", api.ZMember{Member: "m", Score: 1.5})
+	if err != nil {
+		t.Error(err)
+	}
+	if n != 1 {
+		t.Error("count expected 1, got ", n)
+	}
+}
+
+func TestClient_ZrangeByScore(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/zrangebyscore/k?max=%2BInf&min=1" {
+			t.Error("wrong url:", r.URL.String())
+		}
+		w.Write([]byte(`[{"member":"a","score":1},{"member":"b","score":2}]`))
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	members, err := c.ZrangeByScore(ctx, "k", 1, math.Inf(1))
+	if err != nil {
+		t.Error(err)
+	}
+
+	if len(members) != 2 || members[0].Member != "a" || members[1].Score != 2 {
+		t.Errorf("wrong members %v", members)
+	}
+}
+
+func TestClient_Zrank(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/zrank/k/m" {
+			t.Error("wrong url:", r.URL.String())
+		}
+		w.Write([]byte(`{"rank":3}`))
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	rank, err := c.Zrank(ctx, "k", "m")
+	if err != nil {
+		t.Error(err)
+	}
+	if rank != 3 {
+		t.Error("rank expected 3, got ", rank)
+	}
+}
+
+func TestClient_Zpopmin(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Error("wrong method ", r.Method)
+		}
+		if r.URL.String() != "/zpopmin/k" {
+			t.Error("wrong url:", r.URL.String())
+		}
+		w.Write([]byte(`[{"member":"a","score":1}]`))
+	}))
+	defer server.Close()
+
+	c := newClient(t, server)
+	members, err := c.Zpopmin(ctx, "k", 1)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(members) != 1 || members[0].Member != "a" {
+		t.Errorf("wrong members %v", members)
+	}
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/alexxeis/keyval/storage"
+	"github.com/gorilla/mux"
+)
+
+// DatabasePrefix is a path prefix of API routes of the selected database
+const DatabasePrefix = "/api/db/{db:[A-Za-z0-9_-]+}"
+
+// defaultDatabase is a name of the database used without selection, it's the same as cluster.DefaultDatabase
+const defaultDatabase = "0"
+
+// Databases is a set of logical databases
+type Databases interface {
+	// Database returns storage of the database by name
+	Database(name string) (storage.Storage, error)
+
+	// Flushall deletes all keys of all databases
+	Flushall(ctx context.Context)
+}
+
+// Flushall returns handler deleting all keys of all databases
+func Flushall(dbs Databases) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dbs.Flushall(r.Context())
+	}
+}
+
+// databasesHandler routes requests to API of the database selected by path prefix
+type databasesHandler struct {
+	dbs         Databases
+	middlewares []mux.MiddlewareFunc
+	mu          sync.Mutex
+	routers     map[string]*mux.Router
+}
+
+// NewDatabasesHandler returns handler of API routes prefixed with DatabasePrefix,
+// middlewares are applied to the routes of every database
+func NewDatabasesHandler(dbs Databases, middlewares ...mux.MiddlewareFunc) http.Handler {
+	return &databasesHandler{
+		dbs:         dbs,
+		middlewares: middlewares,
+		routers:     make(map[string]*mux.Router),
+	}
+}
+
+// router returns router of the database API by name
+func (d *databasesHandler) router(name string) (*mux.Router, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if router, ok := d.routers[name]; ok {
+		return router, nil
+	}
+
+	s, err := d.dbs.Database(name)
+	if err != nil {
+		return nil, err
+	}
+
+	router := mux.NewRouter()
+	router.Use(d.middlewares...)
+	NewHandler(s).Register(router.PathPrefix(DatabasePrefix).Subrouter())
+	d.routers[name] = router
+	return router, nil
+}
+
+func (d *databasesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	router, err := d.router(mux.Vars(r)["db"])
+	if err != nil {
+		writeError(w, "", badRequestError(err.Error()))
+		return
+	}
+
+	router.ServeHTTP(w, r)
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/alexxeis/keyval/storage"
+	"github.com/gorilla/mux"
+)
+
+// testDatabases creates storages of databases on first use and records their names
+type testDatabases struct {
+	mu  sync.Mutex
+	dbs map[string]storage.Storage
+}
+
+func (d *testDatabases) Database(name string) (storage.Storage, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if s, ok := d.dbs[name]; ok {
+		return s, nil
+	}
+	s := storage.NewStorage(0)
+	d.dbs[name] = s
+	return s, nil
+}
+
+func (d *testDatabases) Flushall(ctx context.Context) {}
+
+func TestDatabasesHandler_ACL(t *testing.T) {
+	acl := newTestACL(t,
+		Token{Name: "users", Token: "users", Rules: []Rule{{DB: "users", Prefix: "k", Permission: PermissionRead}}},
+		Token{Name: "all", Token: "all", Rules: []Rule{{DB: "*", Permission: PermissionWrite}}},
+	)
+	dbs := &testDatabases{dbs: make(map[string]storage.Storage)}
+	defer func() {
+		for _, s := range dbs.dbs {
+			s.Shutdown()
+		}
+	}()
+
+	router := mux.NewRouter()
+	router.PathPrefix(DatabasePrefix + "/").Handler(acl.AuthorizeDatabase(NewDatabasesHandler(dbs, acl.Middleware)))
+
+	tests := []struct {
+		token  string
+		target string
+		status int
+	}{
+		{"", "/api/db/users/get/k", http.StatusUnauthorized},
+		{"wrong", "/api/db/users/get/k", http.StatusUnauthorized},
+		{"users", "/api/db/other/get/k", http.StatusForbidden},
+		{"users", "/api/db/users/get/a", http.StatusForbidden},
+		{"users", "/api/db/users/get/k", http.StatusNotFound},
+		{"all", "/api/db/sessions/get/k", http.StatusNotFound},
+	}
+	for _, test := range tests {
+		if status := serveAs(router, test.token, http.MethodGet, test.target, ""); status != test.status {
+			t.Errorf("%s %s: expected %d, got %d", test.token, test.target, test.status, status)
+		}
+	}
+
+	// test databases are created for authorized clients only
+	if _, ok := dbs.dbs["other"]; ok || len(dbs.dbs) != 2 {
+		t.Errorf("wrong created databases %v", dbs.dbs)
+	}
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/alexxeis/keyval/storage"
+)
+
+// Error codes of JSON error object
+const (
+	CodeBadRequest   = "bad_request"
+	CodeNotFound     = "not_found"
+	CodeWrongType    = "wrong_type"
+	CodeTimeout      = "timeout"
+	CodeExists       = "exists"
+	CodeUnauthorized = "unauthorized"
+	CodeForbidden    = "forbidden"
+	CodeTooLarge     = "too_large"
+	CodeRateLimited  = "rate_limited"
+	CodeCanceled     = "canceled"
+	CodeInternal     = "internal"
+)
+
+var (
+	errorMissingKey      = badRequestError("missing key")
+	errorMissingField    = badRequestError("missing field")
+	errorMissingMember   = badRequestError("missing member")
+	errorMissingGroup    = badRequestError("missing group")
+	errorMissingConsumer = badRequestError("missing consumer")
+	errorMissingIndex    = badRequestError("missing index")
+	errorWrongTtl        = badRequestError("ttl cant be less than 0")
+	errorWrongTimeout    = badRequestError("timeout cant be less than 0")
+	errorWrongPayload    = badRequestError("wrong payload")
+	errorWrongBit        = badRequestError("bit must be 0 or 1")
+)
+
+// StatusClientClosedRequest is a status of request canceled by client, it's never received by the client
+const StatusClientClosedRequest = 499
+
+// badRequestError is an error caused by incorrect request
+type badRequestError string
+
+func (e badRequestError) Error() string {
+	return string(e)
+}
+
+// Error is a struct for JSON error object
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Key     string `json:"key,omitempty"`
+}
+
+// errorStatus returns HTTP status and error code by error
+func errorStatus(err error) (int, string) {
+	if _, ok := err.(badRequestError); ok {
+		return http.StatusBadRequest, CodeBadRequest
+	}
+	if _, ok := err.(tooLargeError); ok {
+		return http.StatusRequestEntityTooLarge, CodeTooLarge
+	}
+
+	switch err {
+	case errorUnauthorized:
+		return http.StatusUnauthorized, CodeUnauthorized
+	case errorForbidden:
+		return http.StatusForbidden, CodeForbidden
+	case errorRateLimited:
+		return http.StatusTooManyRequests, CodeRateLimited
+	case storage.ErrorNotFound:
+		return http.StatusNotFound, CodeNotFound
+	case storage.ErrorWrongType:
+		return http.StatusConflict, CodeWrongType
+	case storage.ErrorNotANumber, storage.ErrorScoreOverflow:
+		return http.StatusBadRequest, CodeBadRequest
+	case storage.ErrorTimeout:
+		return http.StatusRequestTimeout, CodeTimeout
+	case storage.ErrorStreamID:
+		return http.StatusBadRequest, CodeBadRequest
+	case storage.ErrorNoGroup:
+		return http.StatusNotFound, CodeNotFound
+	case storage.ErrorGroupExists:
+		return http.StatusConflict, CodeExists
+	case storage.ErrorBitOffset, storage.ErrorBitOp:
+		return http.StatusBadRequest, CodeBadRequest
+	case storage.ErrorCoordinates, storage.ErrorGeoQuery:
+		return http.StatusBadRequest, CodeBadRequest
+	case storage.ErrorJSON, storage.ErrorJSONPath:
+		return http.StatusBadRequest, CodeBadRequest
+	case storage.ErrorNoPath:
+		return http.StatusNotFound, CodeNotFound
+	case storage.ErrorIndexExists:
+		return http.StatusConflict, CodeExists
+	case storage.ErrorNoIndex:
+		return http.StatusNotFound, CodeNotFound
+	case storage.ErrorSameKey:
+		return http.StatusBadRequest, CodeBadRequest
+	case context.Canceled:
+		return StatusClientClosedRequest, CodeCanceled
+	case context.DeadlineExceeded:
+		return http.StatusGatewayTimeout, CodeTimeout
+	}
+
+	return http.StatusInternalServerError, CodeInternal
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/alexxeis/keyval/storage"
+	"github.com/gorilla/mux"
+)
+
+// GeoLocation is a struct for JSON geo location object
+type GeoLocation struct {
+	Member    string  `json:"member"`
+	Longitude float64 `json:"longitude"`
+	Latitude  float64 `json:"latitude"`
+}
+
+// GeoaddParams is a struct for JSON geoaddParams object
+type GeoaddParams struct {
+	Locations []GeoLocation `json:"locations"`
+}
+
+// GeoResult is a struct for JSON geo search result object, distance is in meters
+type GeoResult struct {
+	Member    string  `json:"member"`
+	Distance  float64 `json:"distance"`
+	Longitude float64 `json:"longitude"`
+	Latitude  float64 `json:"latitude"`
+}
+
+// GeoQuery is a struct for JSON geo search query object, radius, width and height are in meters
+type GeoQuery struct {
+	Member    string  `json:"member,omitempty"`
+	Longitude float64 `json:"longitude,omitempty"`
+	Latitude  float64 `json:"latitude,omitempty"`
+	Radius    float64 `json:"radius,omitempty"`
+	Width     float64 `json:"width,omitempty"`
+	Height    float64 `json:"height,omitempty"`
+	Count     int     `json:"count,omitempty"`
+}
+
+// Distance is a struct for JSON distance object, distance is in meters
+type Distance struct {
+	Distance float64 `json:"distance"`
+}
+
+func (h *handler) Geoadd(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	var params GeoaddParams
+	if err := readJSON(r.Body, &params); err != nil || len(params.Locations) == 0 {
+		writeError(w, key, errorWrongPayload)
+		return
+	}
+
+	locations := make([]storage.GeoLocation, len(params.Locations))
+	for i, l := range params.Locations {
+		locations[i] = storage.GeoLocation{Member: l.Member, Longitude: l.Longitude, Latitude: l.Latitude}
+	}
+
+	n, err := h.storage.Geoadd(r.Context(), key, locations...)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeContent(w, Count{n})
+}
+
+func (h *handler) Geopos(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	members := r.URL.Query()["member"]
+	if len(members) == 0 {
+		writeError(w, key, errorMissingMember)
+		return
+	}
+
+	locations, err := h.storage.Geopos(r.Context(), key, members...)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	res := make([]*GeoLocation, len(locations))
+	for i, l := range locations {
+		if l != nil {
+			res[i] = &GeoLocation{Member: l.Member, Longitude: l.Longitude, Latitude: l.Latitude}
+		}
+	}
+	writeContent(w, res)
+}
+
+func (h *handler) Geodist(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	member1, ok1 := vars["member1"]
+	member2, ok2 := vars["member2"]
+	if !ok1 || !ok2 {
+		writeError(w, key, errorMissingMember)
+		return
+	}
+
+	d, err := h.storage.Geodist(r.Context(), key, member1, member2)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeContent(w, Distance{d})
+}
+
+func (h *handler) Geosearch(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	var q GeoQuery
+	if err := readJSON(r.Body, &q); err != nil || q.Count < 0 {
+		writeError(w, key, errorWrongPayload)
+		return
+	}
+
+	results, err := h.storage.Geosearch(r.Context(), key, storage.GeoQuery{
+		Member:    q.Member,
+		Longitude: q.Longitude,
+		Latitude:  q.Latitude,
+		Radius:    q.Radius,
+		Width:     q.Width,
+		Height:    q.Height,
+		Count:     q.Count,
+	})
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	res := make([]GeoResult, len(results))
+	for i, g := range results {
+		res[i] = GeoResult{
+			Member:    g.Member,
+			Distance:  g.Distance,
+			Longitude: g.Longitude,
+			Latitude:  g.Latitude,
+		}
+	}
+	writeContent(w, res)
+}
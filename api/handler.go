@@ -2,8 +2,10 @@ package api
 
 import (
 	"encoding/json"
+	"io"
 	"log"
 	"net/http"
+	"strconv"
 
 	"github.com/alexxeis/keyval/storage"
 )
@@ -18,6 +20,19 @@ func NewHandler(s storage.Storage) *handler {
 	return &handler{s}
 }
 
+// readJSON decodes JSON payload into v, payload mustn't have data after the value.
+// Handlers and middlewares inspecting payload must use it, so they agree on accepted payloads
+func readJSON(r io.Reader, v interface{}) error {
+	dec := json.NewDecoder(r)
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return errorWrongPayload
+	}
+	return nil
+}
+
 // writeContent writes payload to writer
 func writeContent(w http.ResponseWriter, content interface{}) {
 	w.Header().Add("Content-Type", "application/json")
@@ -25,3 +40,27 @@ func writeContent(w http.ResponseWriter, content interface{}) {
 		log.Print(err)
 	}
 }
+
+// writeRaw writes binary payload with its content type to writer
+func writeRaw(w http.ResponseWriter, data []byte, contentType string) {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	w.Header().Add("Content-Type", contentType)
+	w.Header().Add("Content-Length", strconv.Itoa(len(data)))
+	if _, err := w.Write(data); err != nil {
+		log.Print(err)
+	}
+}
+
+// writeError writes JSON error object with HTTP status matched to err
+func writeError(w http.ResponseWriter, key string, err error) {
+	status, code := errorStatus(err)
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(Error{Code: code, Message: err.Error(), Key: key}); err != nil {
+		log.Print(err)
+	}
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alexxeis/keyval/storage"
+	"github.com/gorilla/mux"
+)
+
+// newTestRouter returns router of API handler of new storage with middlewares
+func newTestRouter(t *testing.T, mwf ...mux.MiddlewareFunc) *mux.Router {
+	s := storage.NewStorage(0)
+	t.Cleanup(s.Shutdown)
+
+	router := mux.NewRouter()
+	router.Use(mwf...)
+	NewHandler(s).Register(router)
+	return router
+}
+
+// newRequest returns test request with body
+func newRequest(method, target, body string) *http.Request {
+	return httptest.NewRequest(method, target, strings.NewReader(body))
+}
+
+// serveRequest makes request to router and returns response recorder
+func serveRequest(router http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	return w
+}
+
+// serve makes request to router and returns response recorder
+func serve(router http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	return serveRequest(router, newRequest(method, target, body))
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// IndexParams is a struct for JSON indexParams object
+type IndexParams struct {
+	Prefix string `json:"prefix"`
+	Field  string `json:"field"`
+}
+
+// IndexPage is a struct for JSON index query result page, next is a cursor of the next page or empty
+type IndexPage struct {
+	Keys []string `json:"keys"`
+	Next string   `json:"next,omitempty"`
+}
+
+// defaultPageSize is a count of keys in index query result page by default
+const defaultPageSize = 100
+
+func (h *handler) IndexCreate(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	name, ok := vars["name"]
+	if !ok {
+		writeError(w, "", errorMissingIndex)
+		return
+	}
+
+	var params IndexParams
+	if err := readJSON(r.Body, &params); err != nil || params.Field == "" {
+		writeError(w, "", errorWrongPayload)
+		return
+	}
+
+	if err := h.storage.IndexCreate(r.Context(), name, params.Prefix, params.Field); err != nil {
+		writeError(w, "", err)
+		return
+	}
+}
+
+func (h *handler) IndexDrop(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	name, ok := vars["name"]
+	if !ok {
+		writeError(w, "", errorMissingIndex)
+		return
+	}
+
+	if err := h.storage.IndexDrop(r.Context(), name); err != nil {
+		writeError(w, "", err)
+		return
+	}
+}
+
+func (h *handler) IndexQuery(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	name, ok := vars["name"]
+	if !ok {
+		writeError(w, "", errorMissingIndex)
+		return
+	}
+
+	count, err := queryInt(r, "count", defaultPageSize)
+	if err != nil || count < 1 {
+		writeError(w, "", badRequestError("wrong count"))
+		return
+	}
+
+	// one more key shows that there is the next page
+	keys, err := h.storage.IndexQuery(r.Context(), name, r.URL.Query().Get("value"), r.URL.Query().Get("after"), count+1)
+	if err != nil {
+		writeError(w, "", err)
+		return
+	}
+
+	page := IndexPage{Keys: keys}
+	if len(keys) > count {
+		page.Keys = keys[:count]
+		page.Next = keys[count-1]
+	}
+	writeContent(w, page)
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// JSONArrAppendParams is a struct for JSON arrAppendParams object
+type JSONArrAppendParams struct {
+	Values []json.RawMessage `json:"values"`
+}
+
+func (h *handler) JSONGet(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	val, err := h.storage.JSONGet(r.Context(), key, queryString(r, "path", "$"))
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeRaw(w, val, "application/json")
+}
+
+func (h *handler) JSONSet(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, key, errorWrongPayload)
+		return
+	}
+
+	if err = h.storage.JSONSet(r.Context(), key, queryString(r, "path", "$"), data); err != nil {
+		writeError(w, key, err)
+		return
+	}
+}
+
+func (h *handler) JSONDel(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	n, err := h.storage.JSONDel(r.Context(), key, queryString(r, "path", "$"))
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeContent(w, Count{n})
+}
+
+func (h *handler) JSONArrAppend(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	var params JSONArrAppendParams
+	if err := readJSON(r.Body, &params); err != nil || len(params.Values) == 0 {
+		writeError(w, key, errorWrongPayload)
+		return
+	}
+
+	values := make([][]byte, len(params.Values))
+	for i, v := range params.Values {
+		values[i] = v
+	}
+
+	n, err := h.storage.JSONArrAppend(r.Context(), key, queryString(r, "path", "$"), values...)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeContent(w, Count{n})
+}
+
+func (h *handler) JSONNumIncrBy(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	var params Incr
+	if err := readJSON(r.Body, &params); err != nil {
+		writeError(w, key, errorWrongPayload)
+		return
+	}
+
+	val, err := h.storage.JSONNumIncrBy(r.Context(), key, queryString(r, "path", "$"), params.Incr)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeRaw(w, val, "application/json")
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Type is a struct for JSON type object
+type Type struct {
+	Type string `json:"type"`
+}
+
+// Copied is a struct for JSON copied object
+type Copied struct {
+	Copied bool `json:"copied"`
+}
+
+func (h *handler) Exists(w http.ResponseWriter, r *http.Request) {
+	keys := r.URL.Query()["key"]
+	if len(keys) == 0 {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	writeContent(w, Count{h.storage.Exists(r.Context(), keys...)})
+}
+
+func (h *handler) Type(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	writeContent(w, Type{h.storage.Type(r.Context(), key)})
+}
+
+func (h *handler) Dbsize(w http.ResponseWriter, r *http.Request) {
+	writeContent(w, Count{h.storage.Dbsize(r.Context())})
+}
+
+func (h *handler) Rename(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+	newKey, ok := vars["newkey"]
+	if !ok {
+		writeError(w, key, errorMissingKey)
+		return
+	}
+
+	if err := h.storage.Rename(r.Context(), key, newKey); err != nil {
+		writeError(w, key, err)
+		return
+	}
+}
+
+func (h *handler) Copy(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+	newKey, ok := vars["newkey"]
+	if !ok {
+		writeError(w, key, errorMissingKey)
+		return
+	}
+
+	copied, err := h.storage.Copy(r.Context(), key, newKey, r.URL.Query().Get("replace") == "true")
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeContent(w, Copied{copied})
+}
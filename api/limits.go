@@ -0,0 +1,313 @@
+package api
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/gorilla/mux"
+)
+
+// tooLargeError is an error caused by exceeded size limit
+type tooLargeError string
+
+func (e tooLargeError) Error() string {
+	return string(e)
+}
+
+var (
+	errorBodyTooLarge  = tooLargeError("request body is too large")
+	errorKeyTooLarge   = tooLargeError("key is too large")
+	errorFieldTooLarge = tooLargeError("field is too large")
+	errorValueTooLarge = tooLargeError("value is too large")
+)
+
+// valueSizes return max size of stored values by request and its payload of route
+var valueSizes = map[string]func(r *http.Request, payload []byte) (int, error){
+	"set":           valueSize,
+	"hset":          valueSize,
+	"setraw":        payloadSize,
+	"jsonset":       payloadSize,
+	"jsonarrappend": jsonValuesSize,
+	"lpush":         valuesSize,
+	"rpush":         valuesSize,
+	"sadd":          membersSize,
+	"zadd":          zmembersSize,
+	"zincrby":       memberSize,
+	"geoadd":        locationsSize,
+	"xadd":          fieldsSize,
+	"setbit":        bitmapSize,
+}
+
+// payloadKeys return keys passed by request payload of route
+var payloadKeys = map[string]func(payload []byte) ([]string, error){
+	"bitop":   keysOf,
+	"pfmerge": keysOf,
+}
+
+// maxLen returns max length of strings
+func maxLen(ss ...string) int {
+	max := 0
+	for _, s := range ss {
+		if len(s) > max {
+			max = len(s)
+		}
+	}
+	return max
+}
+
+// valueSize returns size of value in JSON value object
+func valueSize(_ *http.Request, payload []byte) (int, error) {
+	var v Value
+	if err := readJSON(bytes.NewReader(payload), &v); err != nil {
+		return 0, err
+	}
+	return len(v.Value), nil
+}
+
+// payloadSize returns size of payload stored as is
+func payloadSize(_ *http.Request, payload []byte) (int, error) {
+	return len(payload), nil
+}
+
+// valuesSize returns max size of values in JSON values object
+func valuesSize(_ *http.Request, payload []byte) (int, error) {
+	var v Values
+	if err := readJSON(bytes.NewReader(payload), &v); err != nil {
+		return 0, err
+	}
+	return maxLen(v.Values...), nil
+}
+
+// jsonValuesSize returns max size of encoded values in JSON array append object
+func jsonValuesSize(_ *http.Request, payload []byte) (int, error) {
+	var v JSONArrAppendParams
+	if err := readJSON(bytes.NewReader(payload), &v); err != nil {
+		return 0, err
+	}
+
+	max := 0
+	for _, val := range v.Values {
+		if len(val) > max {
+			max = len(val)
+		}
+	}
+	return max, nil
+}
+
+// membersSize returns max size of members in JSON members object
+func membersSize(_ *http.Request, payload []byte) (int, error) {
+	var v Members
+	if err := readJSON(bytes.NewReader(payload), &v); err != nil {
+		return 0, err
+	}
+	return maxLen(v.Members...), nil
+}
+
+// zmembersSize returns max size of members in JSON zadd object
+func zmembersSize(_ *http.Request, payload []byte) (int, error) {
+	var v ZaddParams
+	if err := readJSON(bytes.NewReader(payload), &v); err != nil {
+		return 0, err
+	}
+
+	max := 0
+	for _, m := range v.Members {
+		if len(m.Member) > max {
+			max = len(m.Member)
+		}
+	}
+	return max, nil
+}
+
+// memberSize returns size of member passed by path
+func memberSize(r *http.Request, _ []byte) (int, error) {
+	return len(mux.Vars(r)["member"]), nil
+}
+
+// locationsSize returns max size of members in JSON geoadd object
+func locationsSize(_ *http.Request, payload []byte) (int, error) {
+	var v GeoaddParams
+	if err := readJSON(bytes.NewReader(payload), &v); err != nil {
+		return 0, err
+	}
+
+	max := 0
+	for _, l := range v.Locations {
+		if len(l.Member) > max {
+			max = len(l.Member)
+		}
+	}
+	return max, nil
+}
+
+// fieldsSize returns max size of names and values of fields in JSON xadd object
+func fieldsSize(_ *http.Request, payload []byte) (int, error) {
+	var v XaddParams
+	if err := readJSON(bytes.NewReader(payload), &v); err != nil {
+		return 0, err
+	}
+
+	max := 0
+	for name, val := range v.Fields {
+		if n := maxLen(name, val); n > max {
+			max = n
+		}
+	}
+	return max, nil
+}
+
+// bitmapSize returns size of bitmap grown to bit offset passed by path
+func bitmapSize(r *http.Request, _ []byte) (int, error) {
+	// wrong offset is reported by the handler
+	offset, err := strconv.Atoi(mux.Vars(r)["offset"])
+	if err != nil || offset < 0 {
+		return 0, nil
+	}
+	return offset/8 + 1, nil
+}
+
+// keysOf returns keys of JSON keys object
+func keysOf(payload []byte) ([]string, error) {
+	var v struct {
+		Keys []string `json:"keys"`
+	}
+	if err := readJSON(bytes.NewReader(payload), &v); err != nil {
+		return nil, err
+	}
+	return v.Keys, nil
+}
+
+// Limits are request size limits, zero limit is disabled. Values are limited by max body size
+// if max value size is disabled, as values can be grown by commands like setbit without large body
+type Limits struct {
+	MaxBodySize  int64
+	MaxKeySize   int
+	MaxFieldSize int
+	MaxValueSize int
+}
+
+// SizeLimiter rejects requests exceeding limits, limits can be changed at runtime
+type SizeLimiter struct {
+	// rejected is a count of rejected requests, it's first to be aligned for atomic operations
+	rejected uint64
+	limits   atomic.Value
+}
+
+// NewSizeLimiter returns limiter of requests by limits
+func NewSizeLimiter(l Limits) *SizeLimiter {
+	sl := &SizeLimiter{}
+	sl.limits.Store(l)
+	return sl
+}
+
+// Limits returns current limits
+func (sl *SizeLimiter) Limits() Limits {
+	return sl.limits.Load().(Limits)
+}
+
+// SetLimits changes limits of next requests
+func (sl *SizeLimiter) SetLimits(l Limits) {
+	sl.limits.Store(l)
+}
+
+// Rejected returns count of requests rejected due to limits
+func (sl *SizeLimiter) Rejected() uint64 {
+	return atomic.LoadUint64(&sl.rejected)
+}
+
+// check returns key exceeding limit and tooLargeError or reads payload and restores it for the handler
+func (l *Limits) check(r *http.Request) (string, error) {
+	vars := mux.Vars(r)
+	if l.MaxKeySize > 0 {
+		keys := r.URL.Query()["key"]
+		for _, v := range []string{"key", "newkey"} {
+			if key, ok := vars[v]; ok {
+				keys = append(keys, key)
+			}
+		}
+		for _, key := range keys {
+			if len(key) > l.MaxKeySize {
+				return "", errorKeyTooLarge
+			}
+		}
+	}
+
+	key := vars["key"]
+	if l.MaxFieldSize > 0 && len(vars["field"]) > l.MaxFieldSize {
+		return key, errorFieldTooLarge
+	}
+
+	name := routeName(r)
+	_, hasKeys := payloadKeys[name]
+	if l.MaxBodySize <= 0 && l.MaxValueSize <= 0 && (l.MaxKeySize <= 0 || !hasKeys) {
+		return "", nil
+	}
+
+	// payload is read entirely here, so handlers get full body or no body at all
+	if l.MaxBodySize > 0 && r.ContentLength > l.MaxBodySize {
+		return key, errorBodyTooLarge
+	}
+	body := r.Body
+	if l.MaxBodySize > 0 {
+		body = http.MaxBytesReader(nil, r.Body, l.MaxBodySize)
+	}
+	payload, err := ioutil.ReadAll(body)
+	if err != nil {
+		if l.MaxBodySize > 0 && int64(len(payload)) >= l.MaxBodySize {
+			return key, errorBodyTooLarge
+		}
+		return key, errorWrongPayload
+	}
+	r.Body.Close()
+	r.Body = ioutil.NopCloser(bytes.NewReader(payload))
+
+	if l.MaxKeySize > 0 && hasKeys {
+		keys, err := payloadKeys[name](payload)
+		if err != nil {
+			return key, errorWrongPayload
+		}
+		for _, k := range keys {
+			if len(k) > l.MaxKeySize {
+				return key, errorKeyTooLarge
+			}
+		}
+	}
+
+	maxValue := int64(l.MaxValueSize)
+	if maxValue <= 0 {
+		maxValue = l.MaxBodySize
+	}
+	if size, ok := valueSizes[name]; ok && maxValue > 0 {
+		n, err := size(r, payload)
+		if err != nil {
+			return key, errorWrongPayload
+		}
+		if int64(n) > maxValue {
+			return key, errorValueTooLarge
+		}
+	}
+	return "", nil
+}
+
+// Middleware rejects requests exceeding limits, nil limiter allows all requests
+func (sl *SizeLimiter) Middleware(next http.Handler) http.Handler {
+	if sl == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l := sl.Limits()
+		if key, err := l.check(r); err != nil {
+			if _, ok := err.(tooLargeError); ok {
+				atomic.AddUint64(&sl.rejected, 1)
+			}
+			writeError(w, key, err)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
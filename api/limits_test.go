@@ -0,0 +1,105 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSizeLimiter_Values(t *testing.T) {
+	sl := NewSizeLimiter(Limits{MaxBodySize: 1 << 20, MaxKeySize: 8, MaxValueSize: 4})
+	router := newTestRouter(t, sl.Middleware)
+
+	tests := []struct {
+		target string
+		body   string
+		status int
+	}{
+		{"/setbit/b/31", `{"bit":1}`, http.StatusOK},
+		{"/setbit/b/32", `{"bit":1}`, http.StatusRequestEntityTooLarge},
+		{"/setbit/b/4294967295", `{"bit":1}`, http.StatusRequestEntityTooLarge},
+		{"/sadd/s", `{"members":["1234"]}`, http.StatusOK},
+		{"/sadd/s", `{"members":["1","12345"]}`, http.StatusRequestEntityTooLarge},
+		{"/zadd/z", `{"members":[{"member":"1234","score":1}]}`, http.StatusOK},
+		{"/zadd/z", `{"members":[{"member":"12345","score":1}]}`, http.StatusRequestEntityTooLarge},
+		{"/zincrby/z/1234", `{"incr":1}`, http.StatusOK},
+		{"/zincrby/z/12345", `{"incr":1}`, http.StatusRequestEntityTooLarge},
+		{"/geoadd/g", `{"locations":[{"member":"1234","longitude":1,"latitude":1}]}`, http.StatusOK},
+		{"/geoadd/g", `{"locations":[{"member":"12345","longitude":1,"latitude":1}]}`, http.StatusRequestEntityTooLarge},
+		{"/xadd/x", `{"fields":{"f":"1234"}}`, http.StatusOK},
+		{"/xadd/x", `{"fields":{"f":"12345"}}`, http.StatusRequestEntityTooLarge},
+		{"/xadd/x", `{"fields":{"12345":"v"}}`, http.StatusRequestEntityTooLarge},
+		{"/json/j?path=$", `[]`, http.StatusOK},
+		{"/json/j/arrappend?path=$", `{"values":[1234]}`, http.StatusOK},
+		{"/json/j/arrappend?path=$", `{"values":["123"]}`, http.StatusRequestEntityTooLarge},
+		{"/bitop/or/d", `{"keys":["b","12345678"]}`, http.StatusOK},
+		{"/bitop/or/d", `{"keys":["b","123456789"]}`, http.StatusRequestEntityTooLarge},
+		{"/pfmerge/p", `{"keys":["123456789"]}`, http.StatusRequestEntityTooLarge},
+	}
+	for _, test := range tests {
+		if w := serve(router, http.MethodPost, test.target, test.body); w.Code != test.status {
+			t.Errorf("%s %s: expected %d, got %d %s", test.target, test.body, test.status, w.Code, w.Body)
+		}
+	}
+
+	// test bitmap is limited by body size if value size is disabled
+	sl.SetLimits(Limits{MaxBodySize: 16})
+	if w := serve(router, http.MethodPost, "/setbit/b/128", `{"bit":1}`); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413, got %d %s", w.Code, w.Body)
+	}
+}
+
+func TestSizeLimiter_Middleware(t *testing.T) {
+	sl := NewSizeLimiter(Limits{MaxBodySize: 64, MaxKeySize: 4, MaxFieldSize: 2, MaxValueSize: 8})
+	router := newTestRouter(t, sl.Middleware)
+
+	tests := []struct {
+		method string
+		target string
+		body   string
+		status int
+	}{
+		{http.MethodPost, "/set/k", `{"value":"12345678"}`, http.StatusOK},
+		{http.MethodPost, "/set/k", `{"value":"123456789"}`, http.StatusRequestEntityTooLarge},
+		{http.MethodPost, "/set/k", `{"value":"` + strings.Repeat("1", 64) + `"}`, http.StatusRequestEntityTooLarge},
+		{http.MethodPost, "/raw/k", "123456789", http.StatusRequestEntityTooLarge},
+		{http.MethodPost, "/set/12345", `{"value":"v"}`, http.StatusRequestEntityTooLarge},
+		{http.MethodPost, "/copy/k/12345", "", http.StatusRequestEntityTooLarge},
+		{http.MethodGet, "/exists?key=k&key=12345", "", http.StatusRequestEntityTooLarge},
+		{http.MethodPost, "/hset/h/12", `{"value":"v"}`, http.StatusOK},
+		{http.MethodPost, "/hset/h/123", `{"value":"v"}`, http.StatusRequestEntityTooLarge},
+		{http.MethodPost, "/lpush/l", `{"values":["1","123456789"]}`, http.StatusRequestEntityTooLarge},
+		// payloads rejected by handlers are rejected before size checks
+		{http.MethodPost, "/set/k", `{"value":`, http.StatusBadRequest},
+		{http.MethodPost, "/set/k", `{"value":"123456789"} x`, http.StatusBadRequest},
+		{http.MethodPost, "/lpush/l", `{"values":["1"]}{"values":["123456789"]}`, http.StatusBadRequest},
+		{http.MethodPost, "/bitop/or/d", `{"keys":["12345"]} x`, http.StatusBadRequest},
+		// trailing whitespace is allowed
+		{http.MethodPost, "/set/k", "{\"value\":\"1\"}\n", http.StatusOK},
+	}
+	for _, test := range tests {
+		if w := serve(router, test.method, test.target, test.body); w.Code != test.status {
+			t.Errorf("%s %s: expected %d, got %d %s", test.method, test.target, test.status, w.Code, w.Body)
+		}
+	}
+	if n := sl.Rejected(); n != 8 {
+		t.Errorf("expected 8 rejected requests, got %d", n)
+	}
+
+	// test body of unknown length is limited while reading
+	r := newRequest(http.MethodPost, "/raw/k", strings.Repeat("1", 65))
+	r.ContentLength = -1
+	if w := serveRequest(router, r); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413, got %d %s", w.Code, w.Body)
+	}
+
+	// test limits are changed at runtime
+	sl.SetLimits(Limits{})
+	if w := serve(router, http.MethodPost, "/set/12345", `{"value":"123456789"}`); w.Code != http.StatusOK {
+		t.Errorf("expected 200 without limits, got %d %s", w.Code, w.Body)
+	}
+	// handler rejects trailing data by the same decoder
+	if w := serve(router, http.MethodPost, "/set/k", `{"value":"1"} x`); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 without limits, got %d %s", w.Code, w.Body)
+	}
+}
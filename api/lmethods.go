@@ -0,0 +1,154 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Values is a struct for JSON values object
+type Values struct {
+	Values []string `json:"values"`
+}
+
+func (h *handler) Lpush(w http.ResponseWriter, r *http.Request) {
+	h.push(w, r, h.storage.Lpush)
+}
+
+func (h *handler) Rpush(w http.ResponseWriter, r *http.Request) {
+	h.push(w, r, h.storage.Rpush)
+}
+
+// push adds values from request to the list
+func (h *handler) push(w http.ResponseWriter, r *http.Request, op func(ctx context.Context, key string, vals ...string) (int, error)) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	var params Values
+	if err := readJSON(r.Body, &params); err != nil || len(params.Values) == 0 {
+		writeError(w, key, errorWrongPayload)
+		return
+	}
+
+	n, err := op(r.Context(), key, params.Values...)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeContent(w, Count{n})
+}
+
+func (h *handler) Lpop(w http.ResponseWriter, r *http.Request) {
+	h.pop(w, r, h.storage.Lpop)
+}
+
+func (h *handler) Rpop(w http.ResponseWriter, r *http.Request) {
+	h.pop(w, r, h.storage.Rpop)
+}
+
+// pop deletes and writes list element
+func (h *handler) pop(w http.ResponseWriter, r *http.Request, op func(ctx context.Context, key string) (string, error)) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	val, err := op(r.Context(), key)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeContent(w, Value{val})
+}
+
+func (h *handler) Blpop(w http.ResponseWriter, r *http.Request) {
+	h.bpop(w, r, h.storage.Blpop)
+}
+
+func (h *handler) Brpop(w http.ResponseWriter, r *http.Request) {
+	h.bpop(w, r, h.storage.Brpop)
+}
+
+// bpop waits for list element until timeout is passed or client goes away
+func (h *handler) bpop(w http.ResponseWriter, r *http.Request, op func(ctx context.Context, key string, timeout time.Duration) (string, error)) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	timeout, err := queryInt(r, "timeout", 0)
+	if err != nil || timeout < 0 {
+		writeError(w, key, errorWrongTimeout)
+		return
+	}
+
+	val, err := op(r.Context(), key, time.Duration(timeout)*time.Millisecond)
+	if err == context.Canceled {
+		// client is gone, nobody to respond
+		return
+	}
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeContent(w, Value{val})
+}
+
+func (h *handler) Llen(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	n, err := h.storage.Llen(r.Context(), key)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeContent(w, Count{n})
+}
+
+func (h *handler) Lrange(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	start, err := queryInt(r, "start", 0)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	stop, err := queryInt(r, "stop", -1)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	vals, err := h.storage.Lrange(r.Context(), key, start, stop)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeContent(w, vals)
+}
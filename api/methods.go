@@ -1,10 +1,13 @@
 package api
 
 import (
-	"encoding/json"
+	"io/ioutil"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/alexxeis/keyval/storage"
 	"github.com/gorilla/mux"
 )
 
@@ -24,86 +27,152 @@ type SetParams struct {
 	Ttl   time.Duration `json:"ttl"`
 }
 
+// Stats is a struct for JSON stats object
+type Stats struct {
+	Keys           int   `json:"keys"`
+	Compressed     int   `json:"compressed"`
+	RawSize        int64 `json:"raw_size"`
+	CompressedSize int64 `json:"compressed_size"`
+}
+
 func (h *handler) Keys(w http.ResponseWriter, r *http.Request) {
-	keys := h.storage.Keys()
+	keys, err := h.storage.Keys(r.Context())
+	if err != nil {
+		writeError(w, "", err)
+		return
+	}
+
 	writeContent(w, keys)
 }
 
+func (h *handler) Stats(w http.ResponseWriter, r *http.Request) {
+	st := h.storage.Stats(r.Context())
+	writeContent(w, Stats{
+		Keys:           st.Keys,
+		Compressed:     st.Compressed,
+		RawSize:        st.RawSize,
+		CompressedSize: st.CompressedSize,
+	})
+}
+
 func (h *handler) Set(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	key, ok := vars["key"]
 	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, "", errorMissingKey)
 		return
 	}
 
 	var params SetParams
-	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+	if err := readJSON(r.Body, &params); err != nil {
+		writeError(w, key, errorWrongPayload)
 		return
 	}
 
-	if params.Value == "" || params.Ttl < 0 {
-		w.WriteHeader(http.StatusBadRequest)
+	if params.Ttl < 0 || int64(params.Ttl) > maxTtl {
+		writeError(w, key, errorWrongTtl)
 		return
 	}
 
-	h.storage.Set(key, params.Value, params.Ttl*time.Millisecond)
+	h.storage.Set(r.Context(), key, params.Value, params.Ttl*time.Millisecond)
 }
 
 func (h *handler) Get(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	key, ok := vars["key"]
 	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, "", errorMissingKey)
 		return
 	}
 
-	val, err := h.storage.Get(key)
+	val, err := h.storage.Get(r.Context(), key)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, key, err)
+		return
+	}
+
+	writeContent(w, Value{val})
+}
+
+// maxTtl is a max TTL in milliseconds which doesn't overflow duration
+const maxTtl = math.MaxInt64 / int64(time.Millisecond)
+
+func (h *handler) SetRaw(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	var ttl time.Duration
+	if v := r.URL.Query().Get("ttl"); v != "" {
+		ms, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || ms < 0 || ms > maxTtl {
+			writeError(w, key, errorWrongTtl)
+			return
+		}
+		ttl = time.Duration(ms) * time.Millisecond
+	}
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, key, errorWrongPayload)
+		return
+	}
+
+	h.storage.SetRaw(r.Context(), key, data, r.Header.Get("Content-Type"), ttl)
+}
+
+func (h *handler) GetRaw(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
 		return
 	}
 
-	if val == "" {
-		w.WriteHeader(http.StatusNotFound)
-	} else {
-		writeContent(w, Value{val})
+	val, contentType, err := h.storage.GetRaw(r.Context(), key)
+	if err != nil {
+		writeError(w, key, err)
+		return
 	}
+
+	writeRaw(w, val, contentType)
 }
 
 func (h *handler) Remove(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	key, ok := vars["key"]
 	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, "", errorMissingKey)
 		return
 	}
 
-	h.storage.Remove(key)
+	h.storage.Remove(r.Context(), key)
 }
 
 func (h *handler) Expire(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	key, ok := vars["key"]
 	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, "", errorMissingKey)
 		return
 	}
 
 	var params Ttl
-	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+	if err := readJSON(r.Body, &params); err != nil {
+		writeError(w, key, errorWrongPayload)
 		return
 	}
 
-	if params.Ttl < 0 {
-		w.WriteHeader(http.StatusBadRequest)
+	if params.Ttl < 0 || int64(params.Ttl) > maxTtl {
+		writeError(w, key, errorWrongTtl)
 		return
 	}
 
-	if ok = h.storage.Expire(key, params.Ttl*time.Millisecond); !ok {
-		w.WriteHeader(http.StatusNotFound)
+	if ok = h.storage.Expire(r.Context(), key, params.Ttl*time.Millisecond); !ok {
+		writeError(w, key, storage.ErrorNotFound)
 	}
 }
 
@@ -111,74 +180,156 @@ func (h *handler) Hget(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	key, ok := vars["key"]
 	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, "", errorMissingKey)
 		return
 	}
 
 	field, ok := vars["field"]
 	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, key, errorMissingField)
 		return
 	}
 
-	val, err := h.storage.Hget(key, field)
+	val, err := h.storage.Hget(r.Context(), key, field)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, key, err)
 		return
 	}
 
-	if val == "" {
-		w.WriteHeader(http.StatusNotFound)
-	} else {
-		writeContent(w, Value{val})
-	}
+	writeContent(w, Value{val})
 }
 
 func (h *handler) Hset(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	key, ok := vars["key"]
 	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, "", errorMissingKey)
 		return
 	}
 
 	field, ok := vars["field"]
 	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, key, errorMissingField)
 		return
 	}
 
 	var params Value
-	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+	if err := readJSON(r.Body, &params); err != nil {
+		writeError(w, key, errorWrongPayload)
 		return
 	}
 
-	if params.Value == "" {
-		w.WriteHeader(http.StatusBadRequest)
+	if err := h.storage.Hset(r.Context(), key, field, params.Value); err != nil {
+		writeError(w, key, err)
+	}
+}
+
+func (h *handler) Hdel(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	field, ok := vars["field"]
+	if !ok {
+		writeError(w, key, errorMissingField)
 		return
 	}
 
-	if err := h.storage.Hset(key, field, params.Value); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+	if err := h.storage.Hdel(r.Context(), key, field); err != nil {
+		writeError(w, key, err)
 	}
 }
 
-func (h *handler) Hdel(w http.ResponseWriter, r *http.Request) {
+func (h *handler) Hexpire(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	key, ok := vars["key"]
 	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, "", errorMissingKey)
 		return
 	}
 
 	field, ok := vars["field"]
 	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, key, errorMissingField)
 		return
 	}
 
-	if err := h.storage.Hdel(key, field); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+	var params Ttl
+	if err := readJSON(r.Body, &params); err != nil {
+		writeError(w, key, errorWrongPayload)
+		return
 	}
+
+	if params.Ttl < 0 || int64(params.Ttl) > maxTtl {
+		writeError(w, key, errorWrongTtl)
+		return
+	}
+
+	ok, err := h.storage.Hexpire(r.Context(), key, field, params.Ttl*time.Millisecond)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	if !ok {
+		writeError(w, key, storage.ErrorNotFound)
+	}
+}
+
+func (h *handler) Httl(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	field, ok := vars["field"]
+	if !ok {
+		writeError(w, key, errorMissingField)
+		return
+	}
+
+	ttl, err := h.storage.Httl(r.Context(), key, field)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	if ttl != storage.NoTtl {
+		ttl /= time.Millisecond
+	}
+	writeContent(w, Ttl{ttl})
+}
+
+func (h *handler) Hpersist(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	field, ok := vars["field"]
+	if !ok {
+		writeError(w, key, errorMissingField)
+		return
+	}
+
+	ok, err := h.storage.Hpersist(r.Context(), key, field)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	if !ok {
+		writeError(w, key, storage.ErrorNotFound)
+	}
+}
+
+func (h *handler) Flushdb(w http.ResponseWriter, r *http.Request) {
+	h.storage.Flush(r.Context())
 }
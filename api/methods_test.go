@@ -0,0 +1,41 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestHandler_Ttl(t *testing.T) {
+	router := newTestRouter(t)
+	max := strconv.FormatInt(maxTtl, 10)
+	over := strconv.FormatInt(maxTtl+1, 10)
+
+	tests := []struct {
+		method string
+		target string
+		body   string
+		status int
+	}{
+		{http.MethodPost, "/raw/r?ttl=" + max, "v", http.StatusOK},
+		{http.MethodPost, "/raw/r?ttl=" + over, "v", http.StatusBadRequest},
+		{http.MethodPost, "/raw/r?ttl=9223372036854775807", "v", http.StatusBadRequest},
+		{http.MethodPost, "/raw/r?ttl=-1", "v", http.StatusBadRequest},
+		{http.MethodPost, "/set/k", `{"value":"v","ttl":` + max + `}`, http.StatusOK},
+		{http.MethodPost, "/set/k", `{"value":"v","ttl":` + over + `}`, http.StatusBadRequest},
+		{http.MethodPost, "/expire/k", `{"ttl":` + over + `}`, http.StatusBadRequest},
+		{http.MethodPost, "/expire/k", `{"ttl":` + max + `}`, http.StatusOK},
+	}
+	for _, test := range tests {
+		if w := serve(router, test.method, test.target, test.body); w.Code != test.status {
+			t.Errorf("%s %s: expected %d, got %d %s", test.method, test.target, test.status, w.Code, w.Body)
+		}
+	}
+
+	// values with max TTL don't expire
+	for _, target := range []string{"/raw/r", "/get/k"} {
+		if w := serve(router, http.MethodGet, target, ""); w.Code != http.StatusOK {
+			t.Errorf("GET %s: expected 200, got %d %s", target, w.Code, w.Body)
+		}
+	}
+}
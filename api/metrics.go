@@ -0,0 +1,28 @@
+package api
+
+import "net/http"
+
+// Metrics is a struct for JSON metrics object
+type Metrics struct {
+	Clients     int    `json:"clients"`
+	Allowed     uint64 `json:"allowed"`
+	RateLimited uint64 `json:"rate_limited"`
+	TooLarge    uint64 `json:"too_large"`
+}
+
+// MetricsHandler returns handler of request limits metrics, nil limiters are skipped
+func MetricsHandler(sl *SizeLimiter, rl *RateLimiter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var m Metrics
+		if sl != nil {
+			m.TooLarge = sl.Rejected()
+		}
+		if rl != nil {
+			st := rl.Stats()
+			m.Clients = st.Clients
+			m.Allowed = st.Allowed
+			m.RateLimited = st.Limited
+		}
+		writeContent(w, m)
+	}
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// PfaddParams is a struct for JSON pfaddParams object
+type PfaddParams struct {
+	Elements []string `json:"elements"`
+}
+
+// PfmergeParams is a struct for JSON pfmergeParams object
+type PfmergeParams struct {
+	Keys []string `json:"keys"`
+}
+
+// Changed is a struct for JSON changed object
+type Changed struct {
+	Changed bool `json:"changed"`
+}
+
+func (h *handler) Pfadd(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	var params PfaddParams
+	if err := readJSON(r.Body, &params); err != nil {
+		writeError(w, key, errorWrongPayload)
+		return
+	}
+
+	changed, err := h.storage.Pfadd(r.Context(), key, params.Elements...)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeContent(w, Changed{changed})
+}
+
+func (h *handler) Pfcount(w http.ResponseWriter, r *http.Request) {
+	keys := r.URL.Query()["key"]
+	if len(keys) == 0 {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	n, err := h.storage.Pfcount(r.Context(), keys...)
+	if err != nil {
+		writeError(w, "", err)
+		return
+	}
+
+	writeContent(w, Count{n})
+}
+
+func (h *handler) Pfmerge(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	var params PfmergeParams
+	if err := readJSON(r.Body, &params); err != nil {
+		writeError(w, key, errorWrongPayload)
+		return
+	}
+
+	if err := h.storage.Pfmerge(r.Context(), key, params.Keys...); err != nil {
+		writeError(w, key, err)
+		return
+	}
+}
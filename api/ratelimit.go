@@ -0,0 +1,163 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var errorRateLimited = errors.New("rate limit exceeded")
+
+// bucket is a token bucket of client
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimiter limits request rate of every client with token bucket.
+// Client is identified by token name if it's authenticated or by IP address
+type RateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	// swept is the time of the last deletion of full buckets
+	swept   time.Time
+	allowed uint64
+	limited uint64
+}
+
+// NewRateLimiter returns limiter allowing rate requests per second with bursts up to burst requests,
+// zero rate allows all requests
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+		swept:   time.Now(),
+	}
+}
+
+// SetRate changes rate and burst, current buckets are truncated to the new burst
+func (l *RateLimiter) SetRate(rate float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.rate = rate
+	l.burst = float64(burst)
+	for _, b := range l.buckets {
+		b.tokens = math.Min(l.burst, b.tokens)
+	}
+}
+
+// sweep deletes buckets refilled to burst, they are the same as new ones, must be called under lock
+func (l *RateLimiter) sweep(now time.Time) {
+	refill := time.Duration(l.burst / l.rate * float64(time.Second))
+	if now.Sub(l.swept) < refill {
+		return
+	}
+
+	for client, b := range l.buckets {
+		if now.Sub(b.last) >= refill {
+			delete(l.buckets, client)
+		}
+	}
+	l.swept = now
+}
+
+// Allow takes token from client bucket, returns false and time to wait for the next token if it's empty
+func (l *RateLimiter) Allow(client string) (bool, time.Duration) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rate <= 0 {
+		l.allowed++
+		return true, 0
+	}
+
+	l.sweep(now)
+
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[client] = b
+	}
+
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		l.limited++
+		return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+
+	b.tokens--
+	l.allowed++
+	return true, 0
+}
+
+// RateLimiterStats is a rate limiter statistics
+type RateLimiterStats struct {
+	Clients int
+	Allowed uint64
+	Limited uint64
+}
+
+// Stats returns statistics of limiter
+func (l *RateLimiter) Stats() RateLimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return RateLimiterStats{
+		Clients: len(l.buckets),
+		Allowed: l.allowed,
+		Limited: l.limited,
+	}
+}
+
+// clientID returns token name of authenticated client or IP address
+func clientID(r *http.Request) string {
+	if name := ClientName(r); name != "" {
+		return name
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// limitedContextKey marks requests counted by rate limiter, so requests of nested routers are counted once
+type limitedContextKey struct{}
+
+// Middleware rejects requests of clients exceeding rate, it must follow ACL Identify middleware to identify
+// clients and precede ACL Middleware to limit unauthenticated requests too. Nil limiter allows all requests
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Context().Value(limitedContextKey{}) != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if ok, wait := l.Allow(clientID(r)); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			writeError(w, "", errorRateLimited)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), limitedContextKey{}, true)))
+	})
+}
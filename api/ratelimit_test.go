@@ -0,0 +1,102 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_Allow(t *testing.T) {
+	l := NewRateLimiter(10, 2)
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.Allow("a"); !ok {
+			t.Fatal("request of burst is limited")
+		}
+	}
+	ok, wait := l.Allow("a")
+	if ok || wait <= 0 || wait > 100*time.Millisecond {
+		t.Errorf("expected limited request with wait up to 100ms, got %v %s", ok, wait)
+	}
+	if ok, _ := l.Allow("b"); !ok {
+		t.Error("request of other client is limited")
+	}
+	if st := l.Stats(); st.Clients != 2 || st.Allowed != 3 || st.Limited != 1 {
+		t.Errorf("wrong stats %+v", st)
+	}
+
+	// test bucket is refilled and full buckets are swept
+	time.Sleep(250 * time.Millisecond)
+	if ok, _ := l.Allow("a"); !ok {
+		t.Error("request is limited after refill")
+	}
+	if st := l.Stats(); st.Clients != 1 {
+		t.Errorf("expected full buckets are swept, got %d clients", st.Clients)
+	}
+
+	// test buckets are truncated to the new burst and zero rate allows all requests
+	l.SetRate(0.001, 1)
+	l.Allow("a")
+	if ok, _ := l.Allow("a"); ok {
+		t.Error("request over new burst is allowed")
+	}
+	l.SetRate(0, 1)
+	if ok, _ := l.Allow("a"); !ok {
+		t.Error("request is limited with zero rate")
+	}
+}
+
+func TestRateLimiter_Middleware(t *testing.T) {
+	router := newTestRouter(t, NewRateLimiter(0.5, 1).Middleware)
+
+	if w := serve(router, http.MethodGet, "/keys", ""); w.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", w.Code)
+	}
+	w := serve(router, http.MethodGet, "/keys", "")
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected 429, got %d", w.Code)
+	}
+	if ra := w.Header().Get("Retry-After"); ra != "2" {
+		t.Errorf("expected Retry-After 2, got %q", ra)
+	}
+
+	// test clients are identified by IP address without token
+	r := newRequest(http.MethodGet, "/keys", "")
+	r.RemoteAddr = "192.0.2.2:1234"
+	if w := serveRequest(router, r); w.Code != http.StatusOK {
+		t.Errorf("expected 200 of other client, got %d", w.Code)
+	}
+}
+
+func TestRateLimiter_ACL(t *testing.T) {
+	acl := newTestACL(t, Token{Name: "app", Token: "app", Rules: []Rule{{Permission: PermissionAdmin}}})
+	l := NewRateLimiter(0.5, 1)
+	router := newTestRouter(t, acl.Identify, l.Middleware, acl.Middleware)
+
+	// test unauthenticated requests are limited before ACL rejects them
+	if status := serveAs(router, "", http.MethodGet, "/keys", ""); status != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", status)
+	}
+	if status := serveAs(router, "wrong", http.MethodGet, "/keys", ""); status != http.StatusTooManyRequests {
+		t.Errorf("expected 429, got %d", status)
+	}
+
+	// test authenticated client is identified by token name
+	if status := serveAs(router, "app", http.MethodGet, "/keys", ""); status != http.StatusOK {
+		t.Errorf("expected 200, got %d", status)
+	}
+	if status := serveAs(router, "app", http.MethodGet, "/keys", ""); status != http.StatusTooManyRequests {
+		t.Errorf("expected 429, got %d", status)
+	}
+
+	// test request passing nested limiters is counted once
+	nested := l.Middleware(newTestRouter(t, l.Middleware))
+	r := newRequest(http.MethodGet, "/keys", "")
+	r.RemoteAddr = "192.0.2.2:1234"
+	if w := serveRequest(nested, r); w.Code != http.StatusOK {
+		t.Errorf("expected 200 of nested limiters, got %d", w.Code)
+	}
+	if st := l.Stats(); st.Allowed != 3 {
+		t.Errorf("expected 3 allowed requests, got %d", st.Allowed)
+	}
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// blockingHandler is a handler of command waiting for data up to its own timeout. Its requests aren't limited
+// by request timeout and aren't logged as slow, as their duration depends on clients
+type blockingHandler http.HandlerFunc
+
+func (h blockingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h(w, r)
+}
+
+// isBlocking returns true if request is routed to blocking command
+func isBlocking(r *http.Request) bool {
+	if route := mux.CurrentRoute(r); route != nil {
+		_, ok := route.GetHandler().(blockingHandler)
+		return ok
+	}
+	return false
+}
+
+// Register registers API routes of the handler in router
+func (h *handler) Register(router *mux.Router) {
+	router.HandleFunc("/keys", h.Keys).Methods(http.MethodGet).Name("keys")
+	router.HandleFunc("/stats", h.Stats).Methods(http.MethodGet).Name("stats")
+	router.HandleFunc("/get/{key}", h.Get).Methods(http.MethodGet).Name("get")
+	router.HandleFunc("/set/{key}", h.Set).Methods(http.MethodPost).Name("set")
+	router.HandleFunc("/raw/{key}", h.GetRaw).Methods(http.MethodGet).Name("getraw")
+	router.HandleFunc("/raw/{key}", h.SetRaw).Methods(http.MethodPost).Name("setraw")
+	router.HandleFunc("/remove/{key}", h.Remove).Methods(http.MethodPost).Name("remove")
+	router.HandleFunc("/expire/{key}", h.Expire).Methods(http.MethodPost).Name("expire")
+	router.HandleFunc("/hget/{key}/{field}", h.Hget).Methods(http.MethodGet).Name("hget")
+	router.HandleFunc("/hset/{key}/{field}", h.Hset).Methods(http.MethodPost).Name("hset")
+	router.HandleFunc("/hdel/{key}/{field}", h.Hdel).Methods(http.MethodPost).Name("hdel")
+	router.HandleFunc("/hexpire/{key}/{field}", h.Hexpire).Methods(http.MethodPost).Name("hexpire")
+	router.HandleFunc("/httl/{key}/{field}", h.Httl).Methods(http.MethodGet).Name("httl")
+	router.HandleFunc("/hpersist/{key}/{field}", h.Hpersist).Methods(http.MethodPost).Name("hpersist")
+	router.HandleFunc("/zadd/{key}", h.Zadd).Methods(http.MethodPost).Name("zadd")
+	router.HandleFunc("/zrem/{key}", h.Zrem).Methods(http.MethodPost).Name("zrem")
+	router.HandleFunc("/zscore/{key}/{member}", h.Zscore).Methods(http.MethodGet).Name("zscore")
+	router.HandleFunc("/zrank/{key}/{member}", h.Zrank).Methods(http.MethodGet).Name("zrank")
+	router.HandleFunc("/zrange/{key}", h.Zrange).Methods(http.MethodGet).Name("zrange")
+	router.HandleFunc("/zrangebyscore/{key}", h.ZrangeByScore).Methods(http.MethodGet).Name("zrangebyscore")
+	router.HandleFunc("/zincrby/{key}/{member}", h.Zincrby).Methods(http.MethodPost).Name("zincrby")
+	router.HandleFunc("/zpopmin/{key}", h.Zpopmin).Methods(http.MethodPost).Name("zpopmin")
+	router.HandleFunc("/sadd/{key}", h.Sadd).Methods(http.MethodPost).Name("sadd")
+	router.HandleFunc("/srem/{key}", h.Srem).Methods(http.MethodPost).Name("srem")
+	router.HandleFunc("/sismember/{key}/{member}", h.Sismember).Methods(http.MethodGet).Name("sismember")
+	router.HandleFunc("/smembers/{key}", h.Smembers).Methods(http.MethodGet).Name("smembers")
+	router.HandleFunc("/sinter", h.Sinter).Methods(http.MethodGet).Name("sinter")
+	router.HandleFunc("/sunion", h.Sunion).Methods(http.MethodGet).Name("sunion")
+	router.HandleFunc("/sdiff", h.Sdiff).Methods(http.MethodGet).Name("sdiff")
+	router.HandleFunc("/lpush/{key}", h.Lpush).Methods(http.MethodPost).Name("lpush")
+	router.HandleFunc("/rpush/{key}", h.Rpush).Methods(http.MethodPost).Name("rpush")
+	router.HandleFunc("/lpop/{key}", h.Lpop).Methods(http.MethodPost).Name("lpop")
+	router.HandleFunc("/rpop/{key}", h.Rpop).Methods(http.MethodPost).Name("rpop")
+	router.Handle("/blpop/{key}", blockingHandler(h.Blpop)).Methods(http.MethodPost).Name("blpop")
+	router.Handle("/brpop/{key}", blockingHandler(h.Brpop)).Methods(http.MethodPost).Name("brpop")
+	router.HandleFunc("/llen/{key}", h.Llen).Methods(http.MethodGet).Name("llen")
+	router.HandleFunc("/lrange/{key}", h.Lrange).Methods(http.MethodGet).Name("lrange")
+	router.HandleFunc("/xadd/{key}", h.Xadd).Methods(http.MethodPost).Name("xadd")
+	router.HandleFunc("/xlen/{key}", h.Xlen).Methods(http.MethodGet).Name("xlen")
+	router.HandleFunc("/xrange/{key}", h.Xrange).Methods(http.MethodGet).Name("xrange")
+	router.Handle("/xread/{key}", blockingHandler(h.Xread)).Methods(http.MethodGet).Name("xread")
+	router.HandleFunc("/xgroup/{key}/{group}", h.XgroupCreate).Methods(http.MethodPost).Name("xgroupcreate")
+	router.Handle("/xreadgroup/{key}/{group}/{consumer}", blockingHandler(h.Xreadgroup)).Methods(http.MethodPost).Name("xreadgroup")
+	router.HandleFunc("/xack/{key}/{group}", h.Xack).Methods(http.MethodPost).Name("xack")
+	router.HandleFunc("/xpending/{key}/{group}", h.Xpending).Methods(http.MethodGet).Name("xpending")
+	router.HandleFunc("/pfadd/{key}", h.Pfadd).Methods(http.MethodPost).Name("pfadd")
+	router.HandleFunc("/pfcount", h.Pfcount).Methods(http.MethodGet).Name("pfcount")
+	router.HandleFunc("/pfmerge/{key}", h.Pfmerge).Methods(http.MethodPost).Name("pfmerge")
+	router.HandleFunc("/setbit/{key}/{offset}", h.Setbit).Methods(http.MethodPost).Name("setbit")
+	router.HandleFunc("/getbit/{key}/{offset}", h.Getbit).Methods(http.MethodGet).Name("getbit")
+	router.HandleFunc("/bitcount/{key}", h.Bitcount).Methods(http.MethodGet).Name("bitcount")
+	router.HandleFunc("/bitpos/{key}/{bit}", h.Bitpos).Methods(http.MethodGet).Name("bitpos")
+	router.HandleFunc("/bitop/{op}/{key}", h.Bitop).Methods(http.MethodPost).Name("bitop")
+	router.HandleFunc("/geoadd/{key}", h.Geoadd).Methods(http.MethodPost).Name("geoadd")
+	router.HandleFunc("/geopos/{key}", h.Geopos).Methods(http.MethodGet).Name("geopos")
+	router.HandleFunc("/geodist/{key}/{member1}/{member2}", h.Geodist).Methods(http.MethodGet).Name("geodist")
+	router.HandleFunc("/geosearch/{key}", h.Geosearch).Methods(http.MethodPost).Name("geosearch")
+	router.HandleFunc("/json/{key}", h.JSONGet).Methods(http.MethodGet).Name("jsonget")
+	router.HandleFunc("/json/{key}", h.JSONSet).Methods(http.MethodPost).Name("jsonset")
+	router.HandleFunc("/json/{key}", h.JSONDel).Methods(http.MethodDelete).Name("jsondel")
+	router.HandleFunc("/json/{key}/arrappend", h.JSONArrAppend).Methods(http.MethodPost).Name("jsonarrappend")
+	router.HandleFunc("/json/{key}/numincrby", h.JSONNumIncrBy).Methods(http.MethodPost).Name("jsonnumincrby")
+	router.HandleFunc("/index/{name}", h.IndexCreate).Methods(http.MethodPost).Name("indexcreate")
+	router.HandleFunc("/index/{name}", h.IndexDrop).Methods(http.MethodDelete).Name("indexdrop")
+	router.HandleFunc("/index/{name}", h.IndexQuery).Methods(http.MethodGet).Name("indexquery")
+	router.HandleFunc("/exists", h.Exists).Methods(http.MethodGet).Name("exists")
+	router.HandleFunc("/type/{key}", h.Type).Methods(http.MethodGet).Name("type")
+	router.HandleFunc("/dbsize", h.Dbsize).Methods(http.MethodGet).Name("dbsize")
+	router.HandleFunc("/rename/{key}/{newkey}", h.Rename).Methods(http.MethodPost).Name("rename")
+	router.HandleFunc("/copy/{key}/{newkey}", h.Copy).Methods(http.MethodPost).Name("copy")
+	router.HandleFunc("/flushdb", h.Flushdb).Methods(http.MethodPost).Name("flushdb")
+}
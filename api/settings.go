@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+)
+
+// Settings is a configuration of running server
+type Settings interface {
+	// Get returns values of settings by keys or all settings if keys are empty
+	Get(keys ...string) (map[string]string, error)
+
+	// Set changes settings by keys all together
+	Set(values map[string]string) error
+}
+
+// ConfigGet returns handler of settings values
+func ConfigGet(s Settings) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		values, err := s.Get(r.URL.Query()["key"]...)
+		if err != nil {
+			writeError(w, "", badRequestError(err.Error()))
+			return
+		}
+
+		writeContent(w, values)
+	}
+}
+
+// ConfigSet returns handler changing settings
+func ConfigSet(s Settings) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var values map[string]string
+		if err := readJSON(r.Body, &values); err != nil || len(values) == 0 {
+			writeError(w, "", errorWrongPayload)
+			return
+		}
+
+		if err := s.Set(values); err != nil {
+			writeError(w, "", badRequestError(err.Error()))
+			return
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SlowLogEntry is a struct for JSON slow command object
+type SlowLogEntry struct {
+	ID       uint64    `json:"id"`
+	Time     time.Time `json:"time"`
+	Duration int64     `json:"duration_us"`
+	Command  string    `json:"command"`
+	Key      string    `json:"key,omitempty"`
+	Client   string    `json:"client"`
+}
+
+// SlowLogEntries is a struct for JSON slow log object, entries are ordered from the newest
+type SlowLogEntries struct {
+	Entries []SlowLogEntry `json:"entries"`
+	Len     int            `json:"len"`
+}
+
+// SlowLog keeps the last commands executed longer than threshold in a ring buffer
+type SlowLog struct {
+	// threshold is first to be aligned for atomic operations
+	threshold int64
+
+	mu      sync.Mutex
+	entries []SlowLogEntry
+	// head is an index of the next entry, len is a count of entries
+	head   int
+	len    int
+	lastID uint64
+}
+
+// NewSlowLog returns slow log of size last commands executed longer than threshold, zero threshold disables log
+func NewSlowLog(threshold time.Duration, size int) *SlowLog {
+	return &SlowLog{
+		threshold: int64(threshold),
+		entries:   make([]SlowLogEntry, size),
+	}
+}
+
+// SetThreshold changes threshold and size of log, the newest entries are kept
+func (l *SlowLog) SetThreshold(threshold time.Duration, size int) {
+	atomic.StoreInt64(&l.threshold, int64(threshold))
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if size == len(l.entries) {
+		return
+	}
+	kept := l.last(size)
+	l.entries = make([]SlowLogEntry, size)
+	for i := range kept {
+		l.entries[i] = kept[len(kept)-1-i]
+	}
+	l.len = len(kept)
+	l.head = l.len % size
+}
+
+// Add adds command entry to log with the next id
+func (l *SlowLog) Add(e SlowLogEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.lastID++
+	e.ID = l.lastID
+	l.entries[l.head] = e
+	l.head = (l.head + 1) % len(l.entries)
+	if l.len < len(l.entries) {
+		l.len++
+	}
+}
+
+// last returns up to count the newest entries from the newest, must be called under lock
+func (l *SlowLog) last(count int) []SlowLogEntry {
+	if count > l.len {
+		count = l.len
+	}
+
+	res := make([]SlowLogEntry, count)
+	for i := range res {
+		res[i] = l.entries[(l.head-1-i+len(l.entries))%len(l.entries)]
+	}
+	return res
+}
+
+// Entries returns up to count the newest entries from the newest and count of all entries,
+// all entries are returned if count is negative
+func (l *SlowLog) Entries(count int) ([]SlowLogEntry, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if count < 0 {
+		count = l.len
+	}
+	return l.last(count), l.len
+}
+
+// Reset deletes all entries, ids aren't reset
+func (l *SlowLog) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.head, l.len = 0, 0
+}
+
+// Middleware adds commands executed longer than threshold, it must be the last middleware to measure handler only.
+// Nil log adds nothing
+func (l *SlowLog) Middleware(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		next.ServeHTTP(w, r)
+		d := time.Since(start)
+
+		threshold := time.Duration(atomic.LoadInt64(&l.threshold))
+		name := routeName(r)
+		if threshold <= 0 || d < threshold || isBlocking(r) {
+			return
+		}
+
+		l.Add(SlowLogEntry{
+			Time:     start,
+			Duration: d.Nanoseconds() / int64(time.Microsecond),
+			Command:  name,
+			Key:      requestKey(r),
+			Client:   clientID(r),
+		})
+	})
+}
+
+// SlowLogHandler returns handler of the newest slow commands, count is passed by query and defaults to 10
+func SlowLogHandler(l *SlowLog) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		count, err := queryInt(r, "count", 10)
+		if err != nil {
+			writeError(w, "", err)
+			return
+		}
+
+		entries, n := l.Entries(count)
+		writeContent(w, SlowLogEntries{Entries: entries, Len: n})
+	}
+}
+
+// SlowLogReset returns handler deleting slow log entries
+func SlowLogReset(l *SlowLog) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l.Reset()
+	}
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlowLog_Blocking(t *testing.T) {
+	l := NewSlowLog(time.Nanosecond, 16)
+	router := newTestRouter(t, l.Middleware)
+
+	serve(router, http.MethodPost, "/xgroup/s/g", `{"start":"0"}`)
+	l.Reset()
+
+	for _, target := range []string{
+		"/blpop/l?timeout=1",
+		"/brpop/l?timeout=1",
+		"/xread/s?after=$&timeout=1",
+		"/xreadgroup/s/g/c?id=>&timeout=1",
+	} {
+		method := http.MethodPost
+		if strings.HasPrefix(target, "/xread/") {
+			method = http.MethodGet
+		}
+		if w := serve(router, method, target, ""); w.Code != http.StatusRequestTimeout && w.Code != http.StatusOK {
+			t.Errorf("%s responded %d %s", target, w.Code, w.Body)
+		}
+	}
+	if entries, n := l.Entries(-1); n != 0 {
+		t.Errorf("blocking commands are logged %+v", entries)
+	}
+
+	serve(router, http.MethodGet, "/keys", "")
+	if entries, n := l.Entries(-1); n != 1 || entries[0].Command != "keys" {
+		t.Errorf("expected keys command logged, got %+v", entries)
+	}
+}
+
+// slowLogIDs returns ids of all entries from the newest
+func slowLogIDs(l *SlowLog) []uint64 {
+	entries, n := l.Entries(-1)
+	if n != len(entries) {
+		return nil
+	}
+
+	ids := make([]uint64, len(entries))
+	for i, e := range entries {
+		ids[i] = e.ID
+	}
+	return ids
+}
+
+func TestSlowLog_Entries(t *testing.T) {
+	l := NewSlowLog(time.Millisecond, 3)
+	for i := 0; i < 5; i++ {
+		l.Add(SlowLogEntry{Command: "keys"})
+	}
+
+	// test ring buffer keeps the newest entries
+	if ids := slowLogIDs(l); fmt.Sprint(ids) != "[5 4 3]" {
+		t.Errorf("wrong entries %v", ids)
+	}
+	if entries, n := l.Entries(2); len(entries) != 2 || n != 3 || entries[0].ID != 5 || entries[1].ID != 4 {
+		t.Errorf("wrong 2 entries %+v of %d", entries, n)
+	}
+
+	// test entries are kept on resize
+	l.SetThreshold(time.Millisecond, 5)
+	l.Add(SlowLogEntry{})
+	if ids := slowLogIDs(l); fmt.Sprint(ids) != "[6 5 4 3]" {
+		t.Errorf("wrong entries after grow %v", ids)
+	}
+	l.Add(SlowLogEntry{})
+	l.Add(SlowLogEntry{})
+	l.SetThreshold(time.Millisecond, 2)
+	if ids := slowLogIDs(l); fmt.Sprint(ids) != "[8 7]" {
+		t.Errorf("wrong entries after shrink %v", ids)
+	}
+	l.Add(SlowLogEntry{})
+	if ids := slowLogIDs(l); fmt.Sprint(ids) != "[9 8]" {
+		t.Errorf("wrong entries after wraparound %v", ids)
+	}
+
+	// test reset keeps ids
+	l.Reset()
+	if ids := slowLogIDs(l); len(ids) != 0 {
+		t.Errorf("entries after reset %v", ids)
+	}
+	l.Add(SlowLogEntry{})
+	if ids := slowLogIDs(l); fmt.Sprint(ids) != "[10]" {
+		t.Errorf("wrong entries after reset %v", ids)
+	}
+}
+
+func TestSlowLogHandler(t *testing.T) {
+	l := NewSlowLog(time.Millisecond, 16)
+	for i := 0; i < 12; i++ {
+		l.Add(SlowLogEntry{Command: "keys"})
+	}
+
+	for target, expected := range map[string]int{"/": 10, "/?count=3": 3, "/?count=-1": 12} {
+		w := serve(SlowLogHandler(l), http.MethodGet, target, "")
+		var res SlowLogEntries
+		if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Entries) != expected || res.Len != 12 {
+			t.Errorf("%s: expected %d entries, got %d of %d", target, expected, len(res.Entries), res.Len)
+		}
+	}
+	if w := serve(SlowLogHandler(l), http.MethodGet, "/?count=x", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+
+	serve(SlowLogReset(l), http.MethodDelete, "/", "")
+	if _, n := l.Entries(-1); n != 0 {
+		t.Errorf("expected reset log, got %d entries", n)
+	}
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// IsMember is a struct for JSON isMember object
+type IsMember struct {
+	IsMember bool `json:"ismember"`
+}
+
+func (h *handler) Sadd(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	var params Members
+	if err := readJSON(r.Body, &params); err != nil || len(params.Members) == 0 {
+		writeError(w, key, errorWrongPayload)
+		return
+	}
+
+	n, err := h.storage.Sadd(r.Context(), key, params.Members...)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeContent(w, Count{n})
+}
+
+func (h *handler) Srem(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	var params Members
+	if err := readJSON(r.Body, &params); err != nil {
+		writeError(w, key, errorWrongPayload)
+		return
+	}
+
+	n, err := h.storage.Srem(r.Context(), key, params.Members...)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeContent(w, Count{n})
+}
+
+func (h *handler) Sismember(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	member, ok := vars["member"]
+	if !ok {
+		writeError(w, key, errorMissingMember)
+		return
+	}
+
+	ok, err := h.storage.Sismember(r.Context(), key, member)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeContent(w, IsMember{ok})
+}
+
+func (h *handler) Smembers(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	members, err := h.storage.Smembers(r.Context(), key)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeContent(w, members)
+}
+
+func (h *handler) Sinter(w http.ResponseWriter, r *http.Request) {
+	h.setAlgebra(w, r, h.storage.Sinter)
+}
+
+func (h *handler) Sunion(w http.ResponseWriter, r *http.Request) {
+	h.setAlgebra(w, r, h.storage.Sunion)
+}
+
+func (h *handler) Sdiff(w http.ResponseWriter, r *http.Request) {
+	h.setAlgebra(w, r, h.storage.Sdiff)
+}
+
+// setAlgebra runs set operation against keys from "key" query parameters
+func (h *handler) setAlgebra(w http.ResponseWriter, r *http.Request, op func(ctx context.Context, keys ...string) ([]string, error)) {
+	keys := r.URL.Query()["key"]
+	if len(keys) == 0 {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	members, err := op(r.Context(), keys...)
+	if err != nil {
+		writeError(w, "", err)
+		return
+	}
+
+	writeContent(w, members)
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// RequestTimeout limits time of request processing by deadline of request context,
+// blocking commands are limited by their own timeout
+type RequestTimeout struct {
+	// timeout is first to be aligned for atomic operations
+	timeout int64
+}
+
+// NewRequestTimeout returns request timeout, zero timeout is disabled
+func NewRequestTimeout(timeout time.Duration) *RequestTimeout {
+	return &RequestTimeout{timeout: int64(timeout)}
+}
+
+// SetTimeout changes timeout of next requests
+func (t *RequestTimeout) SetTimeout(timeout time.Duration) {
+	atomic.StoreInt64(&t.timeout, int64(timeout))
+}
+
+// Middleware sets deadline of request context, long operations return timeout error after it.
+// Nil timeout sets nothing
+func (t *RequestTimeout) Middleware(next http.Handler) http.Handler {
+	if t == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timeout := time.Duration(atomic.LoadInt64(&t.timeout))
+		if timeout <= 0 || isBlocking(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func TestRequestTimeout_Blocking(t *testing.T) {
+	router := newTestRouter(t, NewRequestTimeout(20*time.Millisecond).Middleware)
+
+	var blocking []string
+	err := router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		if _, ok := route.GetHandler().(blockingHandler); ok {
+			blocking = append(blocking, route.GetName())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(blocking)
+	if strings.Join(blocking, ",") != "blpop,brpop,xread,xreadgroup" {
+		t.Errorf("wrong blocking routes %v", blocking)
+	}
+
+	// test blocking commands wait for their own timeout
+	serve(router, http.MethodPost, "/xgroup/s/g", `{"start":"0"}`)
+	for _, target := range []string{"/blpop/l", "/brpop/l", "/xread/s?after=$", "/xreadgroup/s/g/c?id=>"} {
+		method := http.MethodPost
+		if strings.HasPrefix(target, "/xread/") {
+			method = http.MethodGet
+		}
+		if strings.Contains(target, "?") {
+			target += "&timeout=50"
+		} else {
+			target += "?timeout=50"
+		}
+
+		start := time.Now()
+		w := serve(router, method, target, "")
+		if w.Code == http.StatusGatewayTimeout || time.Since(start) < 50*time.Millisecond {
+			t.Errorf("%s is limited by request timeout: %d %s", target, w.Code, w.Body)
+		}
+	}
+}
+
+func TestRequestTimeout_Middleware(t *testing.T) {
+	timeout := NewRequestTimeout(time.Minute)
+	router := mux.NewRouter()
+	router.Use(timeout.Middleware)
+	router.HandleFunc("/deadline", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Deadline(); !ok {
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+
+	if w := serve(router, http.MethodGet, "/deadline", ""); w.Code != http.StatusOK {
+		t.Error("request has no deadline")
+	}
+
+	timeout.SetTimeout(0)
+	if w := serve(router, http.MethodGet, "/deadline", ""); w.Code != http.StatusNoContent {
+		t.Error("request has deadline with disabled timeout")
+	}
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/alexxeis/keyval/tracing"
+	"github.com/gorilla/mux"
+)
+
+// Tracing returns middleware recording server span of request, child of remote span passed by traceparent header.
+// It must be the first middleware to include other ones in span. Nil tracer records nothing
+func Tracing(t *tracing.Tracer) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		if t == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := r.URL.Path
+			if cur := mux.CurrentRoute(r); cur != nil {
+				if tpl, err := cur.GetPathTemplate(); err == nil {
+					route = tpl
+				}
+			}
+
+			ctx, span := t.Start(r.Context(), r.Method+" "+route, tracing.KindServer, tracing.Extract(r.Header))
+			if span == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			defer span.End()
+
+			span.SetAttribute("http.method", r.Method)
+			span.SetAttribute("http.route", route)
+			span.SetAttribute("keyval.command", routeName(r))
+			if key := requestKey(r); key != "" {
+				span.SetAttribute("keyval.key", key)
+			}
+
+			sw := &statusWriter{ResponseWriter: w}
+			next.ServeHTTP(sw, r.WithContext(ctx))
+
+			if sw.status == 0 {
+				sw.status = http.StatusOK
+			}
+			span.SetAttribute("http.status_code", sw.status)
+			if sw.status >= http.StatusInternalServerError {
+				span.SetError(statusError(sw.status))
+			}
+		})
+	}
+}
+
+// statusError is an error of span with HTTP status
+type statusError int
+
+func (e statusError) Error() string {
+	return strconv.Itoa(int(e)) + " " + http.StatusText(int(e))
+}
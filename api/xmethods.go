@@ -0,0 +1,300 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/alexxeis/keyval/storage"
+	"github.com/gorilla/mux"
+)
+
+// XaddParams is a struct for JSON xaddParams object
+type XaddParams struct {
+	ID     string            `json:"id"`
+	Fields map[string]string `json:"fields"`
+}
+
+// ID is a struct for JSON id object
+type ID struct {
+	ID string `json:"id"`
+}
+
+// IDs is a struct for JSON ids object
+type IDs struct {
+	IDs []string `json:"ids"`
+}
+
+// Start is a struct for JSON start object
+type Start struct {
+	Start string `json:"start"`
+}
+
+// StreamEntry is a struct for JSON stream entry object
+type StreamEntry struct {
+	ID     string            `json:"id"`
+	Fields map[string]string `json:"fields"`
+}
+
+// PendingEntry is a struct for JSON pending entry object, idle is in ms
+type PendingEntry struct {
+	ID         string        `json:"id"`
+	Consumer   string        `json:"consumer"`
+	Deliveries int           `json:"deliveries"`
+	Idle       time.Duration `json:"idle"`
+}
+
+// queryString returns query parameter or def if it's missing
+func queryString(r *http.Request, name, def string) string {
+	if v := r.URL.Query().Get(name); v != "" {
+		return v
+	}
+	return def
+}
+
+// queryTimeout returns timeout query parameter, missing timeout is negative
+func queryTimeout(r *http.Request) (time.Duration, error) {
+	timeout, err := queryInt(r, "timeout", -1)
+	if err != nil || timeout < -1 {
+		return 0, errorWrongTimeout
+	}
+	if timeout < 0 {
+		return -1, nil
+	}
+	return time.Duration(timeout) * time.Millisecond, nil
+}
+
+// writeStreamEntries writes stream entries to writer
+func writeStreamEntries(w http.ResponseWriter, entries []storage.StreamEntry) {
+	res := make([]StreamEntry, len(entries))
+	for i, e := range entries {
+		res[i] = StreamEntry{ID: e.ID, Fields: e.Fields}
+	}
+	writeContent(w, res)
+}
+
+func (h *handler) Xadd(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	var params XaddParams
+	if err := readJSON(r.Body, &params); err != nil || len(params.Fields) == 0 {
+		writeError(w, key, errorWrongPayload)
+		return
+	}
+
+	if params.ID == "" {
+		params.ID = "*"
+	}
+
+	id, err := h.storage.Xadd(r.Context(), key, params.ID, params.Fields)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeContent(w, ID{id})
+}
+
+func (h *handler) Xlen(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	n, err := h.storage.Xlen(r.Context(), key)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeContent(w, Count{n})
+}
+
+func (h *handler) Xrange(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	count, err := queryInt(r, "count", 0)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	entries, err := h.storage.Xrange(r.Context(), key, queryString(r, "start", "-"), queryString(r, "end", "+"), count)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeStreamEntries(w, entries)
+}
+
+func (h *handler) Xread(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	count, err := queryInt(r, "count", 0)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	timeout, err := queryTimeout(r)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	entries, err := h.storage.Xread(r.Context(), key, queryString(r, "after", "$"), count, timeout)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeStreamEntries(w, entries)
+}
+
+func (h *handler) XgroupCreate(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	group, ok := vars["group"]
+	if !ok {
+		writeError(w, key, errorMissingGroup)
+		return
+	}
+
+	var params Start
+	if err := readJSON(r.Body, &params); err != nil {
+		writeError(w, key, errorWrongPayload)
+		return
+	}
+
+	if params.Start == "" {
+		params.Start = "$"
+	}
+
+	if err := h.storage.XgroupCreate(r.Context(), key, group, params.Start); err != nil {
+		writeError(w, key, err)
+	}
+}
+
+func (h *handler) Xreadgroup(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	group, ok := vars["group"]
+	if !ok {
+		writeError(w, key, errorMissingGroup)
+		return
+	}
+
+	consumer, ok := vars["consumer"]
+	if !ok {
+		writeError(w, key, errorMissingConsumer)
+		return
+	}
+
+	count, err := queryInt(r, "count", 0)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	timeout, err := queryTimeout(r)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	entries, err := h.storage.Xreadgroup(r.Context(), key, group, consumer, queryString(r, "id", ">"), count, timeout)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeStreamEntries(w, entries)
+}
+
+func (h *handler) Xack(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	group, ok := vars["group"]
+	if !ok {
+		writeError(w, key, errorMissingGroup)
+		return
+	}
+
+	var params IDs
+	if err := readJSON(r.Body, &params); err != nil {
+		writeError(w, key, errorWrongPayload)
+		return
+	}
+
+	n, err := h.storage.Xack(r.Context(), key, group, params.IDs...)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeContent(w, Count{n})
+}
+
+func (h *handler) Xpending(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	group, ok := vars["group"]
+	if !ok {
+		writeError(w, key, errorMissingGroup)
+		return
+	}
+
+	pending, err := h.storage.Xpending(r.Context(), key, group)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	res := make([]PendingEntry, len(pending))
+	for i, p := range pending {
+		res[i] = PendingEntry{
+			ID:         p.ID,
+			Consumer:   p.Consumer,
+			Deliveries: p.Deliveries,
+			Idle:       p.Idle / time.Millisecond,
+		}
+	}
+	writeContent(w, res)
+}
@@ -0,0 +1,290 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/alexxeis/keyval/storage"
+	"github.com/gorilla/mux"
+)
+
+// ZMember is a struct for JSON sorted set member object
+type ZMember struct {
+	Member string  `json:"member"`
+	Score  float64 `json:"score"`
+}
+
+// ZaddParams is a struct for JSON zaddParams object
+type ZaddParams struct {
+	Members []ZMember `json:"members"`
+}
+
+// Members is a struct for JSON members object
+type Members struct {
+	Members []string `json:"members"`
+}
+
+// Count is a struct for JSON count object
+type Count struct {
+	Count int `json:"count"`
+}
+
+// Score is a struct for JSON score object
+type Score struct {
+	Score float64 `json:"score"`
+}
+
+// Rank is a struct for JSON rank object
+type Rank struct {
+	Rank int `json:"rank"`
+}
+
+// Incr is a struct for JSON incr object
+type Incr struct {
+	Incr float64 `json:"incr"`
+}
+
+// queryInt returns integer query parameter or def if it's missing
+func queryInt(r *http.Request, name string, def int) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, badRequestError("wrong " + name)
+	}
+	return i, nil
+}
+
+// queryFloat returns float query parameter or def if it's missing, "-inf" and "+inf" are accepted
+func queryFloat(r *http.Request, name string, def float64) (float64, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || math.IsNaN(f) {
+		return 0, badRequestError("wrong " + name)
+	}
+	return f, nil
+}
+
+// writeZMembers writes sorted set members to writer
+func writeZMembers(w http.ResponseWriter, members []storage.ZMember) {
+	res := make([]ZMember, len(members))
+	for i, m := range members {
+		res[i] = ZMember{Member: m.Member, Score: m.Score}
+	}
+	writeContent(w, res)
+}
+
+func (h *handler) Zadd(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	var params ZaddParams
+	if err := readJSON(r.Body, &params); err != nil || len(params.Members) == 0 {
+		writeError(w, key, errorWrongPayload)
+		return
+	}
+
+	members := make([]storage.ZMember, len(params.Members))
+	for i, m := range params.Members {
+		members[i] = storage.ZMember{Member: m.Member, Score: m.Score}
+	}
+
+	n, err := h.storage.Zadd(r.Context(), key, members...)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeContent(w, Count{n})
+}
+
+func (h *handler) Zrem(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	var params Members
+	if err := readJSON(r.Body, &params); err != nil {
+		writeError(w, key, errorWrongPayload)
+		return
+	}
+
+	n, err := h.storage.Zrem(r.Context(), key, params.Members...)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeContent(w, Count{n})
+}
+
+func (h *handler) Zscore(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	member, ok := vars["member"]
+	if !ok {
+		writeError(w, key, errorMissingMember)
+		return
+	}
+
+	score, err := h.storage.Zscore(r.Context(), key, member)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeContent(w, Score{score})
+}
+
+func (h *handler) Zrank(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	member, ok := vars["member"]
+	if !ok {
+		writeError(w, key, errorMissingMember)
+		return
+	}
+
+	rank, err := h.storage.Zrank(r.Context(), key, member)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeContent(w, Rank{rank})
+}
+
+func (h *handler) Zrange(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	start, err := queryInt(r, "start", 0)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	stop, err := queryInt(r, "stop", -1)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	members, err := h.storage.Zrange(r.Context(), key, start, stop)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeZMembers(w, members)
+}
+
+func (h *handler) ZrangeByScore(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	min, err := queryFloat(r, "min", math.Inf(-1))
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	max, err := queryFloat(r, "max", math.Inf(1))
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	members, err := h.storage.ZrangeByScore(r.Context(), key, min, max)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeZMembers(w, members)
+}
+
+func (h *handler) Zincrby(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	member, ok := vars["member"]
+	if !ok {
+		writeError(w, key, errorMissingMember)
+		return
+	}
+
+	var params Incr
+	if err := readJSON(r.Body, &params); err != nil {
+		writeError(w, key, errorWrongPayload)
+		return
+	}
+
+	score, err := h.storage.Zincrby(r.Context(), key, member, params.Incr)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeContent(w, Score{score})
+}
+
+func (h *handler) Zpopmin(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	key, ok := vars["key"]
+	if !ok {
+		writeError(w, "", errorMissingKey)
+		return
+	}
+
+	var params Count
+	if err := readJSON(r.Body, &params); err != nil || params.Count < 0 {
+		writeError(w, key, errorWrongPayload)
+		return
+	}
+
+	members, err := h.storage.Zpopmin(r.Context(), key, params.Count)
+	if err != nil {
+		writeError(w, key, err)
+		return
+	}
+
+	writeZMembers(w, members)
+}
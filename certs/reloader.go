@@ -0,0 +1,92 @@
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sync"
+)
+
+// ErrorNoCA is returned if client CA file has no certificates
+var ErrorNoCA = errors.New("no certificates in client CA file")
+
+// Reloader keeps TLS configuration loaded from certificate, key and optional client CA files.
+// Clients must present certificate signed by client CA if it's set (mutual TLS)
+type Reloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+
+	mu     sync.RWMutex
+	config *tls.Config
+}
+
+// NewReloader returns reloader with loaded files, caFile can be empty
+func NewReloader(certFile, keyFile, caFile string) (*Reloader, error) {
+	r := &Reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		caFile:   caFile,
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload loads files again, current configuration is kept on error.
+// Established connections keep configuration they are accepted with
+func (r *Reloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+		NextProtos:   []string{"h2", "http/1.1"},
+	}
+
+	if r.caFile != "" {
+		data, err := ioutil.ReadFile(r.caFile)
+		if err != nil {
+			return err
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return fmt.Errorf("%s: %v", r.caFile, ErrorNoCA)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	r.mu.Lock()
+	r.config = config
+	r.mu.Unlock()
+	return nil
+}
+
+// current returns current configuration
+func (r *Reloader) current() *tls.Config {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.config
+}
+
+// TLSConfig returns configuration of listener using current certificates for every connection
+func (r *Reloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return r.current(), nil
+		},
+		// http.Server requires certificate in the listener configuration
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return &r.current().Certificates[0], nil
+		},
+		NextProtos: []string{"h2", "http/1.1"},
+	}
+}
@@ -0,0 +1,180 @@
+package certs_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alexxeis/keyval/certs"
+)
+
+// certificate is a generated certificate with its key
+type certificate struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+// generate returns certificate signed by parent or self-signed certificate if parent is nil
+func generate(t *testing.T, name string, parent *certificate) *certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &certificate{
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+// keyPEM returns PEM encoded private key
+func (c *certificate) keyPEM(t *testing.T) []byte {
+	der, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+}
+
+// tlsCertificate returns certificate for TLS client
+func (c *certificate) tlsCertificate(t *testing.T) tls.Certificate {
+	cert, err := tls.X509KeyPair(c.pem, c.keyPEM(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+// writeFiles writes certificate and key files to dir
+func writeFiles(t *testing.T, dir string, c *certificate) {
+	if err := ioutil.WriteFile(filepath.Join(dir, "cert.pem"), c.pem, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "key.pem"), c.keyPEM(t), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// serverName returns common name of certificate presented by server
+func serverName(t *testing.T, c *http.Client, url string) string {
+	resp, err := c.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.TLS.PeerCertificates[0].Subject.CommonName
+}
+
+func TestReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "certs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca := generate(t, "ca", nil)
+	if err = ioutil.WriteFile(filepath.Join(dir, "ca.pem"), ca.pem, 0600); err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, dir, generate(t, "server1", ca))
+
+	r, err := certs.NewReloader(filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"), filepath.Join(dir, "ca.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var clientName string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientName = r.TLS.VerifiedChains[0][0].Subject.CommonName
+	}))
+	server.TLS = r.TLSConfig()
+	server.StartTLS()
+	defer server.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	newClient := func(certs ...tls.Certificate) *http.Client {
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:      pool,
+			Certificates: certs,
+		}}}
+	}
+
+	// test client certificate is required
+	if _, err = newClient().Get(server.URL); err == nil {
+		t.Error("expected error without client certificate")
+	}
+
+	c := newClient(generate(t, "client", ca).tlsCertificate(t))
+	if name := serverName(t, c, server.URL); name != "server1" {
+		t.Errorf("expected server name = server1, got %s", name)
+	}
+	if clientName != "client" {
+		t.Errorf("expected client name = client, got %s", clientName)
+	}
+
+	// test reload
+	writeFiles(t, dir, generate(t, "server2", ca))
+	if err = r.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	c = newClient(generate(t, "client", ca).tlsCertificate(t))
+	if name := serverName(t, c, server.URL); name != "server2" {
+		t.Errorf("expected server name = server2, got %s", name)
+	}
+
+	// test broken files keep current certificates
+	if err = ioutil.WriteFile(filepath.Join(dir, "key.pem"), []byte("broken"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = r.Reload(); err == nil {
+		t.Error("expected reload error")
+	}
+	c = newClient(generate(t, "client", ca).tlsCertificate(t))
+	if name := serverName(t, c, server.URL); name != "server2" {
+		t.Errorf("expected server name = server2, got %s", name)
+	}
+}
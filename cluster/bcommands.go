@@ -0,0 +1,54 @@
+package cluster
+
+import (
+	"context"
+
+	"github.com/alexxeis/keyval/storage"
+)
+
+func (c *cluster) Setbit(ctx context.Context, key string, offset int, bit bool) (bool, error) {
+	return c.instance(key).Setbit(ctx, key, offset, bit)
+}
+
+func (c *cluster) Getbit(ctx context.Context, key string, offset int) (bool, error) {
+	return c.instance(key).Getbit(ctx, key, offset)
+}
+
+func (c *cluster) Bitcount(ctx context.Context, key string, start, end int) (int, error) {
+	return c.instance(key).Bitcount(ctx, key, start, end)
+}
+
+func (c *cluster) Bitpos(ctx context.Context, key string, bit bool, start, end int) (int, error) {
+	return c.instance(key).Bitpos(ctx, key, bit, start, end)
+}
+
+// Bitop reads values from their instances and stores the result in dest instance.
+// It isn't atomic across instances
+func (c *cluster) Bitop(ctx context.Context, op storage.BitOp, dest string, keys ...string) (int, error) {
+	order, _ := c.group(append([]string{dest}, keys...))
+	if len(order) == 1 {
+		return c.instance(dest).Bitop(ctx, op, dest, keys...)
+	}
+
+	values := make([][]byte, len(keys))
+	for i, key := range keys {
+		data, _, err := c.instance(key).GetRaw(ctx, key)
+		if err != nil && err != storage.ErrorNotFound {
+			return 0, err
+		}
+		values[i] = data
+	}
+
+	res, err := op.Apply(values)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(res) == 0 {
+		c.instance(dest).Remove(ctx, dest)
+		return 0, nil
+	}
+
+	c.instance(dest).SetRaw(ctx, dest, res, "", 0)
+	return len(res), nil
+}
@@ -0,0 +1,46 @@
+package cluster_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/alexxeis/keyval/cluster"
+	"github.com/alexxeis/keyval/storage"
+)
+
+func TestCluster_Bitop(t *testing.T) {
+	c := cluster.NewCluster(10, 0)
+
+	// keys are spread across instances
+	keys := make([]string, 5)
+	for i := range keys {
+		keys[i] = "b" + strconv.Itoa(i)
+		if _, err := c.Setbit(ctx, keys[i], i, true); err != nil {
+			t.Error(err)
+		}
+	}
+
+	n, err := c.Bitop(ctx, storage.BitOr, "dest", keys...)
+	if err != nil {
+		t.Error(err)
+	}
+	if n != 1 {
+		t.Errorf("expected length = %d, got %d", 1, n)
+	}
+
+	cnt, err := c.Bitcount(ctx, "dest", 0, -1)
+	if err != nil {
+		t.Error(err)
+	}
+	if cnt != 5 {
+		t.Errorf("expected count = %d, got %d", 5, cnt)
+	}
+
+	pos, err := c.Bitpos(ctx, "dest", false, 0, -1)
+	if err != nil {
+		t.Error(err)
+	}
+	if pos != 5 {
+		t.Errorf("expected position = %d, got %d", 5, pos)
+	}
+}
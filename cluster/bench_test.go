@@ -1,6 +1,7 @@
 package cluster_test
 
 import (
+	"strconv"
 	"sync"
 	"testing"
 
@@ -9,12 +10,12 @@ import (
 )
 
 func setConcurrently(i int, s storage.Storage, wg *sync.WaitGroup) {
-	s.Set(string(i), "v", 0)
+	s.Set(ctx, strconv.Itoa(i), "v", 0)
 	wg.Done()
 }
 
 func getConcurrently(i int, s storage.Storage, wg *sync.WaitGroup) {
-	s.Get("k" + string(i))
+	s.Get(ctx, "k"+strconv.Itoa(i))
 	wg.Done()
 }
 
@@ -1,6 +1,7 @@
 package cluster
 
 import (
+	"sync"
 	"time"
 
 	"github.com/alexxeis/keyval/storage"
@@ -10,17 +11,19 @@ import (
 type cluster struct {
 	instances []storage.Storage
 	count     int
+	// indexMu serializes index declarations, so an index is declared in every instance or in none
+	indexMu sync.Mutex
 }
 
 // NewCluster returns new cluster instance
-func NewCluster(count int, cleanInterval time.Duration) *cluster {
+func NewCluster(count int, cleanInterval time.Duration, opts ...storage.Option) *cluster {
 	if count < 1 {
 		panic("wrong cluster instances count")
 	}
 
 	instances := make([]storage.Storage, count)
 	for i := 0; i < count; i++ {
-		instances[i] = storage.NewStorage(cleanInterval)
+		instances[i] = storage.NewStorage(cleanInterval, append(opts[:len(opts):len(opts)], storage.WithPartition(i))...)
 	}
 
 	return &cluster{
@@ -31,10 +34,29 @@ func NewCluster(count int, cleanInterval time.Duration) *cluster {
 
 // instance returns storage instance by key
 func (c *cluster) instance(key string) storage.Storage {
+	return c.instances[c.index(key)]
+}
+
+// index returns storage instance index by key
+func (c *cluster) index(key string) int {
 	// TODO: can be better :)
 	hasher := newDjb32a()
 	hasher.Write([]byte(key))
 	sum := int(hasher.Sum32())
 
-	return c.instances[sum%c.count]
+	return sum % c.count
+}
+
+// group returns keys grouped by storage instance index in order of first appearance
+func (c *cluster) group(keys []string) ([]int, map[int][]string) {
+	var order []int
+	groups := make(map[int][]string)
+	for _, key := range keys {
+		idx := c.index(key)
+		if _, ok := groups[idx]; !ok {
+			order = append(order, idx)
+		}
+		groups[idx] = append(groups[idx], key)
+	}
+	return order, groups
 }
@@ -1,61 +1,96 @@
 package cluster
 
 import (
-	"sync"
+	"context"
 	"time"
 
 	"github.com/alexxeis/keyval/storage"
 )
 
-func (c *cluster) Expire(key string, ttl time.Duration) bool {
-	return c.instance(key).Expire(key, ttl)
+func (c *cluster) Expire(ctx context.Context, key string, ttl time.Duration) bool {
+	return c.instance(key).Expire(ctx, key, ttl)
 }
 
-func (c *cluster) Set(key, val string, ttl time.Duration) {
-	c.instance(key).Set(key, val, ttl)
+func (c *cluster) Set(ctx context.Context, key, val string, ttl time.Duration) {
+	c.instance(key).Set(ctx, key, val, ttl)
 }
 
-func (c *cluster) Get(key string) (string, error) {
-	return c.instance(key).Get(key)
+func (c *cluster) Get(ctx context.Context, key string) (string, error) {
+	return c.instance(key).Get(ctx, key)
 }
 
-func (c *cluster) Remove(key string) {
-	c.instance(key).Remove(key)
+func (c *cluster) SetRaw(ctx context.Context, key string, val []byte, contentType string, ttl time.Duration) {
+	c.instance(key).SetRaw(ctx, key, val, contentType, ttl)
 }
 
-func (c *cluster) Keys() []string {
-	ch := make(chan []string, c.count)
-	wg := sync.WaitGroup{}
-	wg.Add(int(c.count))
+func (c *cluster) GetRaw(ctx context.Context, key string) ([]byte, string, error) {
+	return c.instance(key).GetRaw(ctx, key)
+}
 
-	for _, i := range c.instances {
-		go func(s storage.Storage, ch chan<- []string, wg *sync.WaitGroup) {
-			ch <- s.Keys()
-			wg.Done()
-		}(i, ch, &wg)
+func (c *cluster) Remove(ctx context.Context, key string) {
+	c.instance(key).Remove(ctx, key)
+}
+
+// Keys collects keys of all instances concurrently, it returns ctx error without waiting for instances if ctx is done
+func (c *cluster) Keys(ctx context.Context) ([]string, error) {
+	type result struct {
+		keys []string
+		err  error
 	}
 
-	wg.Wait()
-	close(ch)
+	// channel is buffered, so instances don't block after return on done ctx
+	ch := make(chan result, c.count)
+	for idx := range c.instances {
+		go func(s storage.Storage) {
+			keys, err := s.Keys(ctx)
+			ch <- result{keys, err}
+		}(c.instances[idx])
+	}
 
 	var keys []string
-	for k := range ch {
-		keys = append(keys, k...)
+	for range c.instances {
+		select {
+		case res := <-ch:
+			if res.err != nil {
+				return nil, res.err
+			}
+			keys = append(keys, res.keys...)
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
 	}
 
-	return keys
+	return keys, nil
+}
+
+func (c *cluster) Hget(ctx context.Context, key, field string) (string, error) {
+	return c.instance(key).Hget(ctx, key, field)
+}
+
+func (c *cluster) Hset(ctx context.Context, key, field, val string) error {
+	return c.instance(key).Hset(ctx, key, field, val)
+}
+
+func (c *cluster) Hdel(ctx context.Context, key, field string) error {
+	return c.instance(key).Hdel(ctx, key, field)
+}
+
+func (c *cluster) Hexpire(ctx context.Context, key, field string, ttl time.Duration) (bool, error) {
+	return c.instance(key).Hexpire(ctx, key, field, ttl)
 }
 
-func (c *cluster) Hget(key, field string) (string, error) {
-	return c.instance(key).Hget(key, field)
+func (c *cluster) Httl(ctx context.Context, key, field string) (time.Duration, error) {
+	return c.instance(key).Httl(ctx, key, field)
 }
 
-func (c *cluster) Hset(key, field, val string) error {
-	return c.instance(key).Hset(key, field, val)
+func (c *cluster) Hpersist(ctx context.Context, key, field string) (bool, error) {
+	return c.instance(key).Hpersist(ctx, key, field)
 }
 
-func (c *cluster) Hdel(key, field string) error {
-	return c.instance(key).Hdel(key, field)
+func (c *cluster) Flush(ctx context.Context) {
+	for _, i := range c.instances {
+		i.Flush(ctx)
+	}
 }
 
 func (c *cluster) Shutdown() {
@@ -63,3 +98,22 @@ func (c *cluster) Shutdown() {
 		i.Shutdown()
 	}
 }
+
+func (c *cluster) SetCleanInterval(interval time.Duration) {
+	for _, i := range c.instances {
+		i.SetCleanInterval(interval)
+	}
+}
+
+func (c *cluster) Stats(ctx context.Context) storage.Stats {
+	var st storage.Stats
+	for _, i := range c.instances {
+		is := i.Stats(ctx)
+		st.Keys += is.Keys
+		st.Compressed += is.Compressed
+		st.RawSize += is.RawSize
+		st.CompressedSize += is.CompressedSize
+	}
+
+	return st
+}
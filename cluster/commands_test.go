@@ -1,6 +1,8 @@
 package cluster_test
 
 import (
+	"bytes"
+	"context"
 	"testing"
 	"time"
 
@@ -8,23 +10,32 @@ import (
 	"github.com/alexxeis/keyval/storage"
 )
 
+// ctx is a context of commands in tests
+var ctx = context.Background()
+
+// allKeys returns keys of storage, test fails on error
+func allKeys(t *testing.T, s storage.Storage) []string {
+	keys, err := s.Keys(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
+
 func TestCluster_Get(t *testing.T) {
 	c := cluster.NewCluster(10, 0)
 
 	// test missing key
-	v, err := c.Get("missing")
-	if err != nil {
+	_, err := c.Get(ctx, "missing")
+	if err != storage.ErrorNotFound {
 		t.Error(err)
 	}
-	if v != "" {
-		t.Error("not empty value")
-	}
 
 	// test wrong type
-	if err = c.Hset("hset", "f", "v"); err != nil {
+	if err = c.Hset(ctx, "hset", "f", "v"); err != nil {
 		t.Error(err)
 	}
-	if _, err = c.Get("hset"); err != storage.ErrorWrongType {
+	if _, err = c.Get(ctx, "hset"); err != storage.ErrorWrongType {
 		t.Error(err)
 	}
 }
@@ -34,8 +45,8 @@ func TestCluster_Set(t *testing.T) {
 	key := "k"
 
 	// test write
-	c.Set(key, "v1", 0)
-	v, err := c.Get(key)
+	c.Set(ctx, key, "v1", 0)
+	v, err := c.Get(ctx, key)
 	if err != nil {
 		t.Error(err)
 	}
@@ -44,8 +55,8 @@ func TestCluster_Set(t *testing.T) {
 	}
 
 	// test rewrite
-	c.Set(key, "v2", 0)
-	v, err = c.Get(key)
+	c.Set(ctx, key, "v2", 0)
+	v, err = c.Get(ctx, key)
 	if err != nil {
 		t.Error(err)
 	}
@@ -53,60 +64,113 @@ func TestCluster_Set(t *testing.T) {
 		t.Error("expected value = v2, got ", v)
 	}
 
-	// test expire
-	c.Set(key, "expired", time.Nanosecond)
-	time.Sleep(time.Nanosecond)
-	v, err = c.Get(key)
+	// test empty value
+	c.Set(ctx, key, "", 0)
+	v, err = c.Get(ctx, key)
 	if err != nil {
 		t.Error(err)
 	}
 	if v != "" {
 		t.Error("not empty value")
 	}
+
+	// test expire
+	c.Set(ctx, key, "expired", time.Nanosecond)
+	time.Sleep(time.Nanosecond)
+	if _, err = c.Get(ctx, key); err != storage.ErrorNotFound {
+		t.Error(err)
+	}
 }
 
-func TestCluster_Expire(t *testing.T) {
+func TestCluster_SetRaw(t *testing.T) {
 	c := cluster.NewCluster(10, 0)
 	key := "k"
+	data := []byte{0, 255, 'v'}
 
-	c.Set(key, "v", 0)
-	c.Expire(key, time.Nanosecond)
-	time.Sleep(time.Nanosecond)
+	// test write
+	c.SetRaw(ctx, key, data, "application/octet-stream", 0)
+	val, ct, err := c.GetRaw(ctx, key)
+	if err != nil {
+		t.Error(err)
+	}
+	if !bytes.Equal(val, data) {
+		t.Errorf("expected value = %v, got %v", data, val)
+	}
+	if ct != "application/octet-stream" {
+		t.Error("expected content type = application/octet-stream, got ", ct)
+	}
 
-	val, err := c.Get(key)
+	// test read as string
+	v, err := c.Get(ctx, key)
 	if err != nil {
 		t.Error(err)
 	}
-	if val != "" {
-		t.Error("not empty value")
+	if v != string(data) {
+		t.Errorf("expected value = %q, got %q", data, v)
+	}
+
+	// test string read as raw
+	c.Set(ctx, key, "v", 0)
+	val, ct, err = c.GetRaw(ctx, key)
+	if err != nil {
+		t.Error(err)
+	}
+	if string(val) != "v" || ct != "" {
+		t.Errorf("expected value = v, got %q (%s)", val, ct)
+	}
+
+	// test expire
+	c.SetRaw(ctx, key, data, "", time.Nanosecond)
+	time.Sleep(time.Nanosecond)
+	if _, _, err = c.GetRaw(ctx, key); err != storage.ErrorNotFound {
+		t.Error(err)
+	}
+
+	// test wrong type
+	if err = c.Hset(ctx, "hset", "f", "v"); err != nil {
+		t.Error(err)
+	}
+	if _, _, err = c.GetRaw(ctx, "hset"); err != storage.ErrorWrongType {
+		t.Error(err)
 	}
 }
 
-func TestCluster_Remove(t *testing.T) {
+func TestCluster_Expire(t *testing.T) {
 	c := cluster.NewCluster(10, 0)
 	key := "k"
 
-	c.Set(key, "v", 0)
-	c.Remove(key)
+	c.Set(ctx, key, "v", 0)
+	c.Expire(ctx, key, time.Nanosecond)
+	time.Sleep(time.Nanosecond)
 
-	v, err := c.Get(key)
-	if err != nil {
+	_, err := c.Get(ctx, key)
+	if err != storage.ErrorNotFound {
 		t.Error(err)
 	}
-	if v != "" {
-		t.Error("not empty value")
+}
+
+func TestCluster_Remove(t *testing.T) {
+	c := cluster.NewCluster(10, 0)
+	key := "k"
+
+	c.Set(ctx, key, "v", 0)
+	c.Remove(ctx, key)
+
+	_, err := c.Get(ctx, key)
+	if err != storage.ErrorNotFound {
+		t.Error(err)
 	}
 }
 
 func TestCluster_Keys(t *testing.T) {
 	c := cluster.NewCluster(10, 0)
 
-	c.Set("k1", "v", 0)
-	c.Set("k2", "v", time.Nanosecond)
-	c.Set("k3", "v", 0)
+	c.Set(ctx, "k1", "v", 0)
+	c.Set(ctx, "k2", "v", time.Nanosecond)
+	c.Set(ctx, "k3", "v", 0)
 	time.Sleep(time.Nanosecond)
 
-	keys := c.Keys()
+	keys := allKeys(t, c)
 	l := len(keys)
 	if l != 2 {
 		t.Errorf("expected len is %d, got %d", 2, l)
@@ -120,33 +184,42 @@ func TestCluster_Keys(t *testing.T) {
 	}
 }
 
+func TestCluster_KeysCanceled(t *testing.T) {
+	c := cluster.NewCluster(10, 0)
+	c.Set(ctx, "k", "v", 0)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := c.Keys(canceled); err != context.Canceled {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+
+	// test instances aren't queried after cancellation
+	if _, err := c.Sunion(canceled, "k", "k1", "k2", "k3"); err != context.Canceled {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+}
+
 func TestCluster_Hget(t *testing.T) {
 	c := cluster.NewCluster(10, 0)
 
 	// test missing key
-	v, err := c.Hget("missing", "f")
-	if err != nil {
+	_, err := c.Hget(ctx, "missing", "f")
+	if err != storage.ErrorNotFound {
 		t.Error(err)
 	}
-	if v != "" {
-		t.Error("not empty value")
-	}
 
 	// test missing field
-	if err = c.Hset("hset", "f", "v"); err != nil {
+	if err = c.Hset(ctx, "hset", "f", "v"); err != nil {
 		t.Error(err)
 	}
-	v, err = c.Hget("hset", "missing")
-	if err != nil {
+	if _, err = c.Hget(ctx, "hset", "missing"); err != storage.ErrorNotFound {
 		t.Error(err)
 	}
-	if v != "" {
-		t.Error("not empty value")
-	}
 
 	// test wrong type
-	c.Set("string", "v", 0)
-	if _, err = c.Hget("string", "f"); err != storage.ErrorWrongType {
+	c.Set(ctx, "string", "v", 0)
+	if _, err = c.Hget(ctx, "string", "f"); err != storage.ErrorWrongType {
 		t.Error(err)
 	}
 }
@@ -163,14 +236,14 @@ func TestCluster_Hset(t *testing.T) {
 	var err error
 
 	// test write
-	if err = c.Hset(key, field1, val1); err != nil {
+	if err = c.Hset(ctx, key, field1, val1); err != nil {
 		t.Error(err)
 	}
-	if err = c.Hset(key, field2, val2); err != nil {
+	if err = c.Hset(ctx, key, field2, val2); err != nil {
 		t.Error(err)
 	}
 
-	v, err := c.Hget(key, field1)
+	v, err := c.Hget(ctx, key, field1)
 	if err != nil {
 		t.Error(err)
 	}
@@ -178,7 +251,7 @@ func TestCluster_Hset(t *testing.T) {
 		t.Errorf("expected value = %s, got %s", val1, v)
 	}
 
-	v, err = c.Hget(key, field2)
+	v, err = c.Hget(ctx, key, field2)
 	if err != nil {
 		t.Error(err)
 	}
@@ -187,11 +260,11 @@ func TestCluster_Hset(t *testing.T) {
 	}
 
 	// test rewrite
-	if err = c.Hset(key, field1, val3); err != nil {
+	if err = c.Hset(ctx, key, field1, val3); err != nil {
 		t.Error(err)
 	}
 
-	v, err = c.Hget(key, field1)
+	v, err = c.Hget(ctx, key, field1)
 	if err != nil {
 		t.Error(err)
 	}
@@ -205,29 +278,51 @@ func TestCluster_Hdel(t *testing.T) {
 	var err error
 
 	// test missing key
-	if err = c.Hdel("missing", "missing"); err != nil {
+	if err = c.Hdel(ctx, "missing", "missing"); err != nil {
 		t.Error(err)
 	}
 
 	// test remove
-	if err = c.Hset("removed", "removed", "v"); err != nil {
+	if err = c.Hset(ctx, "removed", "removed", "v"); err != nil {
+		t.Error(err)
+	}
+	if err = c.Hdel(ctx, "removed", "removed"); err != nil {
+		t.Error(err)
+	}
+
+	if _, err = c.Hget(ctx, "removed", "removed"); err != storage.ErrorNotFound {
+		t.Error(err)
+	}
+
+	// test wrong type
+	c.Set(ctx, "string", "v", 0)
+	if err = c.Hdel(ctx, "string", "missing"); err != storage.ErrorWrongType {
 		t.Error(err)
 	}
-	if err = c.Hdel("removed", "removed"); err != nil {
+}
+
+func TestCluster_Hexpire(t *testing.T) {
+	c := cluster.NewCluster(10, 0)
+
+	if err := c.Hset(ctx, "k", "f", "v"); err != nil {
+		t.Error(err)
+	}
+	if _, err := c.Hexpire(ctx, "k", "f", time.Minute); err != nil {
 		t.Error(err)
 	}
 
-	v, err := c.Hget("removed", "removed")
+	ttl, err := c.Httl(ctx, "k", "f")
 	if err != nil {
 		t.Error(err)
 	}
-	if v != "" {
-		t.Error("not empty value")
+	if ttl <= 0 || ttl > time.Minute {
+		t.Error("wrong ttl ", ttl)
 	}
 
-	// test wrong type
-	c.Set("string", "v", 0)
-	if err = c.Hdel("string", "missing"); err != storage.ErrorWrongType {
+	if _, err = c.Hpersist(ctx, "k", "f"); err != nil {
 		t.Error(err)
 	}
+	if ttl, err = c.Httl(ctx, "k", "f"); err != nil || ttl != storage.NoTtl {
+		t.Error("expected no ttl, got ", ttl, err)
+	}
 }
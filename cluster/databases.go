@@ -0,0 +1,101 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/alexxeis/keyval/storage"
+)
+
+// DefaultDatabase is a name of the database used without selection
+const DefaultDatabase = "0"
+
+var ErrorDatabasesLimit = errors.New("too many databases")
+
+// Databases is a set of logical databases with separate keyspaces, each database is a cluster
+type Databases struct {
+	mu    sync.Mutex
+	dbs   map[string]*cluster
+	limit int
+
+	count         int
+	cleanInterval time.Duration
+	opts          []storage.Option
+}
+
+// NewDatabases returns up to limit databases created on first use, each one has count instances
+func NewDatabases(count, limit int, cleanInterval time.Duration, opts ...storage.Option) *Databases {
+	if limit < 1 {
+		panic("wrong databases limit")
+	}
+
+	d := &Databases{
+		dbs:           make(map[string]*cluster),
+		limit:         limit,
+		count:         count,
+		cleanInterval: cleanInterval,
+		opts:          opts,
+	}
+	d.dbs[DefaultDatabase] = NewCluster(count, cleanInterval, opts...)
+	return d
+}
+
+// Default returns the default database
+func (d *Databases) Default() storage.Storage {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.dbs[DefaultDatabase]
+}
+
+// Database returns database by name, creates it if it's missing.
+// Returns ErrorDatabasesLimit if databases limit is reached
+func (d *Databases) Database(name string) (storage.Storage, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if c, ok := d.dbs[name]; ok {
+		return c, nil
+	}
+
+	if len(d.dbs) >= d.limit {
+		return nil, ErrorDatabasesLimit
+	}
+
+	c := NewCluster(d.count, d.cleanInterval, d.opts...)
+	d.dbs[name] = c
+	return c, nil
+}
+
+// Shutdown finishes work of all databases
+func (d *Databases) Shutdown() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, c := range d.dbs {
+		c.Shutdown()
+	}
+}
+
+// SetCleanInterval changes clean interval of all instances of all databases including new ones
+func (d *Databases) SetCleanInterval(interval time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.cleanInterval = interval
+	for _, c := range d.dbs {
+		c.SetCleanInterval(interval)
+	}
+}
+
+// Flushall deletes all keys of all databases
+func (d *Databases) Flushall(ctx context.Context) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, c := range d.dbs {
+		c.Flush(ctx)
+	}
+}
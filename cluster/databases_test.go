@@ -0,0 +1,66 @@
+package cluster_test
+
+import (
+	"testing"
+
+	"github.com/alexxeis/keyval/cluster"
+	"github.com/alexxeis/keyval/storage"
+)
+
+func TestDatabases_Database(t *testing.T) {
+	dbs := cluster.NewDatabases(10, 2, 0)
+
+	def := dbs.Default()
+	def.Set(ctx, "key", "default", 0)
+
+	db, err := dbs.Database("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = db.Get(ctx, "key"); err != storage.ErrorNotFound {
+		t.Error(err)
+	}
+	db.Set(ctx, "key", "users", 0)
+
+	// test the same database is returned
+	db, err = dbs.Database("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val, err := db.Get(ctx, "key"); err != nil || val != "users" {
+		t.Error("wrong value ", val, err)
+	}
+	if val, err := def.Get(ctx, "key"); err != nil || val != "default" {
+		t.Error("wrong value ", val, err)
+	}
+
+	// test limit
+	if _, err = dbs.Database("orders"); err != cluster.ErrorDatabasesLimit {
+		t.Error(err)
+	}
+
+	// test flush
+	db.Flush(ctx)
+	if keys := allKeys(t, db); len(keys) != 0 {
+		t.Error("wrong keys ", keys)
+	}
+	if keys := allKeys(t, def); len(keys) != 1 {
+		t.Error("wrong keys ", keys)
+	}
+}
+
+func TestDatabases_Flushall(t *testing.T) {
+	dbs := cluster.NewDatabases(10, 2, 0)
+	dbs.Default().Set(ctx, "key", "default", 0)
+
+	db, err := dbs.Database("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Set(ctx, "key", "users", 0)
+
+	dbs.Flushall(ctx)
+	if n := dbs.Default().Dbsize(ctx) + db.Dbsize(ctx); n != 0 {
+		t.Errorf("expected dbsize = 0, got %d", n)
+	}
+}
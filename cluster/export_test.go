@@ -0,0 +1,13 @@
+package cluster
+
+import "github.com/alexxeis/keyval/storage"
+
+// Instances returns storage instances of cluster for tests
+func Instances(c *cluster) []storage.Storage {
+	return c.instances
+}
+
+// Index returns index of storage instance by key for tests
+func Index(c *cluster, key string) int {
+	return c.index(key)
+}
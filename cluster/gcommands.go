@@ -0,0 +1,23 @@
+package cluster
+
+import (
+	"context"
+
+	"github.com/alexxeis/keyval/storage"
+)
+
+func (c *cluster) Geoadd(ctx context.Context, key string, locations ...storage.GeoLocation) (int, error) {
+	return c.instance(key).Geoadd(ctx, key, locations...)
+}
+
+func (c *cluster) Geopos(ctx context.Context, key string, members ...string) ([]*storage.GeoLocation, error) {
+	return c.instance(key).Geopos(ctx, key, members...)
+}
+
+func (c *cluster) Geodist(ctx context.Context, key, member1, member2 string) (float64, error) {
+	return c.instance(key).Geodist(ctx, key, member1, member2)
+}
+
+func (c *cluster) Geosearch(ctx context.Context, key string, q storage.GeoQuery) ([]storage.GeoResult, error) {
+	return c.instance(key).Geosearch(ctx, key, q)
+}
@@ -0,0 +1,41 @@
+package cluster_test
+
+import (
+	"testing"
+
+	"github.com/alexxeis/keyval/cluster"
+	"github.com/alexxeis/keyval/storage"
+)
+
+func TestCluster_Geo(t *testing.T) {
+	c := cluster.NewCluster(10, 0)
+	key := "drivers"
+
+	n, err := c.Geoadd(ctx, key,
+		storage.GeoLocation{Member: "a", Longitude: 37.6173, Latitude: 55.7558},
+		storage.GeoLocation{Member: "b", Longitude: 37.6273, Latitude: 55.7558},
+		storage.GeoLocation{Member: "c", Longitude: 30.3158, Latitude: 59.9391},
+	)
+	if err != nil {
+		t.Error(err)
+	}
+	if n != 3 {
+		t.Errorf("expected added = %d, got %d", 3, n)
+	}
+
+	results, err := c.Geosearch(ctx, key, storage.GeoQuery{Member: "a", Radius: 1000})
+	if err != nil {
+		t.Error(err)
+	}
+	if len(results) != 2 || results[0].Member != "a" || results[1].Member != "b" {
+		t.Error("wrong results ", results)
+	}
+
+	d, err := c.Geodist(ctx, key, "a", "b")
+	if err != nil {
+		t.Error(err)
+	}
+	if d != results[1].Distance {
+		t.Errorf("expected distance = %f, got %f", results[1].Distance, d)
+	}
+}
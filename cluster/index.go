@@ -0,0 +1,73 @@
+package cluster
+
+import (
+	"context"
+	"sort"
+
+	"github.com/alexxeis/keyval/storage"
+)
+
+// IndexCreate declares index in every instance, each instance indexes its own hashes.
+// Instances declared before a failure are rolled back
+func (c *cluster) IndexCreate(ctx context.Context, name, prefix, field string) error {
+	c.indexMu.Lock()
+	defer c.indexMu.Unlock()
+
+	for i, s := range c.instances {
+		if err := s.IndexCreate(ctx, name, prefix, field); err != nil {
+			for _, done := range c.instances[:i] {
+				_ = done.IndexDrop(ctx, name)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// IndexDrop drops index from every instance, it returns ErrorNoIndex only if no instance has it
+func (c *cluster) IndexDrop(ctx context.Context, name string) error {
+	c.indexMu.Lock()
+	defer c.indexMu.Unlock()
+
+	dropped := false
+	for _, s := range c.instances {
+		err := s.IndexDrop(ctx, name)
+		if err == storage.ErrorNoIndex {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		dropped = true
+	}
+	if !dropped {
+		return storage.ErrorNoIndex
+	}
+	return nil
+}
+
+// IndexQuery queries every instance and merges ordered results.
+// It isn't atomic across instances, instances aren't queried after ctx is done
+func (c *cluster) IndexQuery(ctx context.Context, name, value, after string, count int) ([]string, error) {
+	var res []string
+	for idx := range c.instances {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		keys, err := c.instances[idx].IndexQuery(ctx, name, value, after, count)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, keys...)
+	}
+
+	sort.Strings(res)
+	if count > 0 && len(res) > count {
+		res = res[:count]
+	}
+	if res == nil {
+		res = []string{}
+	}
+	return res, nil
+}
@@ -0,0 +1,114 @@
+package cluster_test
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alexxeis/keyval/cluster"
+	"github.com/alexxeis/keyval/storage"
+)
+
+func TestCluster_Index(t *testing.T) {
+	c := cluster.NewCluster(10, 0)
+
+	// hashes are spread across instances
+	var expected []string
+	for i := 0; i < 20; i++ {
+		key := "user:" + strconv.Itoa(i)
+		country := "DE"
+		if i%2 == 1 {
+			country = "FR"
+		} else {
+			expected = append(expected, key)
+		}
+		if err := c.Hset(ctx, key, "country", country); err != nil {
+			t.Error(err)
+		}
+	}
+
+	if err := c.IndexCreate(ctx, "country", "user:", "country"); err != nil {
+		t.Error(err)
+	}
+
+	// query by pages
+	var keys []string
+	after := ""
+	for {
+		page, err := c.IndexQuery(ctx, "country", "DE", after, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		keys = append(keys, page...)
+		after = page[len(page)-1]
+	}
+
+	sort.Strings(expected)
+	if !reflect.DeepEqual(keys, expected) {
+		t.Error("wrong keys ", keys)
+	}
+
+	if err := c.IndexDrop(ctx, "country"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCluster_IndexRollback(t *testing.T) {
+	c := cluster.NewCluster(3, 0)
+	instances := cluster.Instances(c)
+
+	// index declared in the last instance only fails create in all of them
+	if err := instances[2].IndexCreate(ctx, "country", "user:", "country"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.IndexCreate(ctx, "country", "user:", "country"); err != storage.ErrorIndexExists {
+		t.Error("wrong error ", err)
+	}
+	for i, s := range instances[:2] {
+		if _, err := s.IndexQuery(ctx, "country", "DE", "", 0); err != storage.ErrorNoIndex {
+			t.Error("index isn't rolled back in instance ", i, err)
+		}
+	}
+
+	// drop removes partially declared index
+	if err := c.IndexDrop(ctx, "country"); err != nil {
+		t.Error(err)
+	}
+	if err := c.IndexDrop(ctx, "country"); err != storage.ErrorNoIndex {
+		t.Error("wrong error ", err)
+	}
+}
+
+func TestCluster_IndexConcurrent(t *testing.T) {
+	c := cluster.NewCluster(10, 0)
+
+	var wg sync.WaitGroup
+	var created int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.IndexCreate(ctx, "country", "user:", "country"); err == nil {
+				atomic.AddInt32(&created, 1)
+			} else if err != storage.ErrorIndexExists {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if created != 1 {
+		t.Error("wrong created count ", created)
+	}
+	for i, s := range cluster.Instances(c) {
+		if _, err := s.IndexQuery(ctx, "country", "DE", "", 0); err != nil {
+			t.Error("index isn't declared in instance ", i, err)
+		}
+	}
+}
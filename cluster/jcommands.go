@@ -0,0 +1,23 @@
+package cluster
+
+import "context"
+
+func (c *cluster) JSONSet(ctx context.Context, key, path string, value []byte) error {
+	return c.instance(key).JSONSet(ctx, key, path, value)
+}
+
+func (c *cluster) JSONGet(ctx context.Context, key, path string) ([]byte, error) {
+	return c.instance(key).JSONGet(ctx, key, path)
+}
+
+func (c *cluster) JSONDel(ctx context.Context, key, path string) (int, error) {
+	return c.instance(key).JSONDel(ctx, key, path)
+}
+
+func (c *cluster) JSONArrAppend(ctx context.Context, key, path string, values ...[]byte) (int, error) {
+	return c.instance(key).JSONArrAppend(ctx, key, path, values...)
+}
+
+func (c *cluster) JSONNumIncrBy(ctx context.Context, key, path string, incr float64) ([]byte, error) {
+	return c.instance(key).JSONNumIncrBy(ctx, key, path, incr)
+}
@@ -0,0 +1,40 @@
+package cluster
+
+import (
+	"context"
+
+	"github.com/alexxeis/keyval/storage"
+)
+
+func (c *cluster) Exists(ctx context.Context, keys ...string) int {
+	order, groups := c.group(keys)
+
+	n := 0
+	for _, idx := range order {
+		n += c.instances[idx].Exists(ctx, groups[idx]...)
+	}
+	return n
+}
+
+func (c *cluster) Type(ctx context.Context, key string) string {
+	return c.instance(key).Type(ctx, key)
+}
+
+func (c *cluster) Dbsize(ctx context.Context) int {
+	n := 0
+	for idx := range c.instances {
+		n += c.instances[idx].Dbsize(ctx)
+	}
+	return n
+}
+
+// Rename moves value between instances atomically, both instances are locked
+func (c *cluster) Rename(ctx context.Context, src, dst string) error {
+	_, err := storage.Transfer(ctx, c.instance(src), src, c.instance(dst), dst, true, true)
+	return err
+}
+
+// Copy copies value between instances atomically, both instances are locked
+func (c *cluster) Copy(ctx context.Context, src, dst string, replace bool) (bool, error) {
+	return storage.Transfer(ctx, c.instance(src), src, c.instance(dst), dst, false, replace)
+}
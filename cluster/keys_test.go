@@ -0,0 +1,49 @@
+package cluster_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/alexxeis/keyval/cluster"
+	"github.com/alexxeis/keyval/storage"
+)
+
+func TestCluster_RenameCopy(t *testing.T) {
+	c := cluster.NewCluster(10, 0)
+
+	// keys are spread across instances
+	var keys []string
+	for i := 0; i < 20; i++ {
+		key := "k" + strconv.Itoa(i)
+		keys = append(keys, key)
+		c.Set(ctx, key, strconv.Itoa(i), 0)
+	}
+
+	if n := c.Exists(ctx, append(keys, "missing")...); n != 20 {
+		t.Errorf("expected count = 20, got %d", n)
+	}
+	if n := c.Dbsize(ctx); n != 20 {
+		t.Errorf("expected dbsize = 20, got %d", n)
+	}
+	if typ := c.Type(ctx, "k1"); typ != storage.TypeString {
+		t.Errorf("expected type = string, got %s", typ)
+	}
+
+	// test rename and copy between all keys
+	for i, key := range keys {
+		dst := "renamed" + strconv.Itoa(i)
+		if err := c.Rename(ctx, key, dst); err != nil {
+			t.Error(err)
+		}
+		if ok, err := c.Copy(ctx, dst, key, false); err != nil || !ok {
+			t.Errorf("expected copied, got %v (%v)", ok, err)
+		}
+		if v, err := c.Get(ctx, key); err != nil || v != strconv.Itoa(i) {
+			t.Errorf("expected value = %d, got %s (%v)", i, v, err)
+		}
+	}
+
+	if n := c.Dbsize(ctx); n != 40 {
+		t.Errorf("expected dbsize = 40, got %d", n)
+	}
+}
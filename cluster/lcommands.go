@@ -0,0 +1,38 @@
+package cluster
+
+import (
+	"context"
+	"time"
+)
+
+func (c *cluster) Lpush(ctx context.Context, key string, vals ...string) (int, error) {
+	return c.instance(key).Lpush(ctx, key, vals...)
+}
+
+func (c *cluster) Rpush(ctx context.Context, key string, vals ...string) (int, error) {
+	return c.instance(key).Rpush(ctx, key, vals...)
+}
+
+func (c *cluster) Lpop(ctx context.Context, key string) (string, error) {
+	return c.instance(key).Lpop(ctx, key)
+}
+
+func (c *cluster) Rpop(ctx context.Context, key string) (string, error) {
+	return c.instance(key).Rpop(ctx, key)
+}
+
+func (c *cluster) Blpop(ctx context.Context, key string, timeout time.Duration) (string, error) {
+	return c.instance(key).Blpop(ctx, key, timeout)
+}
+
+func (c *cluster) Brpop(ctx context.Context, key string, timeout time.Duration) (string, error) {
+	return c.instance(key).Brpop(ctx, key, timeout)
+}
+
+func (c *cluster) Llen(ctx context.Context, key string) (int, error) {
+	return c.instance(key).Llen(ctx, key)
+}
+
+func (c *cluster) Lrange(ctx context.Context, key string, start, stop int) ([]string, error) {
+	return c.instance(key).Lrange(ctx, key, start, stop)
+}
@@ -0,0 +1,53 @@
+package cluster_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alexxeis/keyval/cluster"
+	"github.com/alexxeis/keyval/storage"
+)
+
+func TestCluster_List(t *testing.T) {
+	c := cluster.NewCluster(10, 0)
+	key := "l"
+
+	if n, err := c.Rpush(ctx, key, "a", "b"); err != nil || n != 2 {
+		t.Errorf("expected len = %d, got %d (%v)", 2, n, err)
+	}
+
+	vals, err := c.Lrange(ctx, key, 0, -1)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(vals) != 2 || vals[0] != "a" || vals[1] != "b" {
+		t.Error("wrong list ", vals)
+	}
+
+	v, err := c.Brpop(context.Background(), key, time.Second)
+	if err != nil || v != "b" {
+		t.Errorf("expected value = b, got %s (%v)", v, err)
+	}
+
+	done := make(chan string)
+	go func() {
+		v, err := c.Blpop(context.Background(), "other", time.Second)
+		if err != nil {
+			t.Error(err)
+		}
+		done <- v
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	if _, err = c.Lpush(ctx, "other", "x"); err != nil {
+		t.Error(err)
+	}
+	if v = <-done; v != "x" {
+		t.Error("expected value = x, got ", v)
+	}
+
+	if _, err = c.Lpop(ctx, "missing"); err != storage.ErrorNotFound {
+		t.Error(err)
+	}
+}
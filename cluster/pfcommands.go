@@ -0,0 +1,66 @@
+package cluster
+
+import (
+	"context"
+
+	"github.com/alexxeis/keyval/storage"
+)
+
+func (c *cluster) Pfadd(ctx context.Context, key string, elements ...string) (bool, error) {
+	return c.instance(key).Pfadd(ctx, key, elements...)
+}
+
+// Pfcount merges HyperLogLogs within every instance and then counts union of the results.
+// It isn't atomic across instances
+func (c *cluster) Pfcount(ctx context.Context, keys ...string) (int, error) {
+	order, _ := c.group(keys)
+	if len(order) <= 1 {
+		if len(keys) == 0 {
+			return 0, nil
+		}
+		return c.instance(keys[0]).Pfcount(ctx, keys...)
+	}
+
+	u, err := c.Pfget(ctx, keys...)
+	if err != nil {
+		return 0, err
+	}
+	return u.Count(), nil
+}
+
+// Pfmerge merges sources from other instances into dest.
+// It isn't atomic across instances
+func (c *cluster) Pfmerge(ctx context.Context, dest string, sources ...string) error {
+	order, _ := c.group(append([]string{dest}, sources...))
+	if len(order) == 1 {
+		return c.instance(dest).Pfmerge(ctx, dest, sources...)
+	}
+
+	u, err := c.Pfget(ctx, sources...)
+	if err != nil {
+		return err
+	}
+	return c.instance(dest).Pfstore(ctx, dest, u)
+}
+
+func (c *cluster) Pfget(ctx context.Context, keys ...string) (*storage.HyperLogLog, error) {
+	order, groups := c.group(keys)
+
+	u := storage.NewHyperLogLog()
+	for _, idx := range order {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		h, err := c.instances[idx].Pfget(ctx, groups[idx]...)
+		if err != nil {
+			return nil, err
+		}
+		u.Merge(h)
+	}
+	return u, nil
+}
+
+func (c *cluster) Pfstore(ctx context.Context, key string, h *storage.HyperLogLog) error {
+	return c.instance(key).Pfstore(ctx, key, h)
+}
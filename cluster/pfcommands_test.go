@@ -0,0 +1,61 @@
+package cluster_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/alexxeis/keyval/cluster"
+)
+
+func TestCluster_Pfcount(t *testing.T) {
+	c := cluster.NewCluster(10, 0)
+
+	// keys are spread across instances
+	keys := make([]string, 5)
+	for i := range keys {
+		keys[i] = "h" + strconv.Itoa(i)
+		if _, err := c.Pfadd(ctx, keys[i], "common", "e"+strconv.Itoa(i)); err != nil {
+			t.Error(err)
+		}
+	}
+
+	n, err := c.Pfcount(ctx, keys...)
+	if err != nil {
+		t.Error(err)
+	}
+	if n != 6 {
+		t.Errorf("expected count = %d, got %d", 6, n)
+	}
+
+	n, err = c.Pfcount(ctx, keys[0])
+	if err != nil {
+		t.Error(err)
+	}
+	if n != 2 {
+		t.Errorf("expected count = %d, got %d", 2, n)
+	}
+}
+
+func TestCluster_Pfmerge(t *testing.T) {
+	c := cluster.NewCluster(10, 0)
+
+	keys := make([]string, 5)
+	for i := range keys {
+		keys[i] = "h" + strconv.Itoa(i)
+		if _, err := c.Pfadd(ctx, keys[i], "common", "e"+strconv.Itoa(i)); err != nil {
+			t.Error(err)
+		}
+	}
+
+	if err := c.Pfmerge(ctx, "dest", keys...); err != nil {
+		t.Error(err)
+	}
+
+	n, err := c.Pfcount(ctx, "dest")
+	if err != nil {
+		t.Error(err)
+	}
+	if n != 6 {
+		t.Errorf("expected count = %d, got %d", 6, n)
+	}
+}
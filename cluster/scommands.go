@@ -0,0 +1,128 @@
+package cluster
+
+import "context"
+
+func (c *cluster) Sadd(ctx context.Context, key string, members ...string) (int, error) {
+	return c.instance(key).Sadd(ctx, key, members...)
+}
+
+func (c *cluster) Srem(ctx context.Context, key string, members ...string) (int, error) {
+	return c.instance(key).Srem(ctx, key, members...)
+}
+
+func (c *cluster) Sismember(ctx context.Context, key, member string) (bool, error) {
+	return c.instance(key).Sismember(ctx, key, member)
+}
+
+func (c *cluster) Smembers(ctx context.Context, key string) ([]string, error) {
+	return c.instance(key).Smembers(ctx, key)
+}
+
+// Sinter intersects sets within every instance and then intersects the results.
+// It isn't atomic across instances, instances aren't queried after ctx is done
+func (c *cluster) Sinter(ctx context.Context, keys ...string) ([]string, error) {
+	order, groups := c.group(keys)
+	if len(order) == 0 {
+		return []string{}, nil
+	}
+
+	res, err := c.instances[order[0]].Sinter(ctx, groups[order[0]]...)
+	if err != nil {
+		return nil, err
+	}
+
+	// every group is intersected even if the result is empty, so keys of wrong type are reported
+	for _, idx := range order[1:] {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		members, err := c.instances[idx].Sinter(ctx, groups[idx]...)
+		if err != nil {
+			return nil, err
+		}
+
+		in := toSet(members)
+		filtered := res[:0]
+		for _, m := range res {
+			if _, ok := in[m]; ok {
+				filtered = append(filtered, m)
+			}
+		}
+		res = filtered
+	}
+
+	return res, nil
+}
+
+// Sunion unites sets within every instance and then unites the results.
+// It isn't atomic across instances, instances aren't queried after ctx is done
+func (c *cluster) Sunion(ctx context.Context, keys ...string) ([]string, error) {
+	order, groups := c.group(keys)
+	if len(order) == 1 {
+		return c.instances[order[0]].Sunion(ctx, keys...)
+	}
+
+	u := make(map[string]struct{})
+	for _, idx := range order {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		members, err := c.instances[idx].Sunion(ctx, groups[idx]...)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, m := range members {
+			u[m] = struct{}{}
+		}
+	}
+
+	res := make([]string, 0, len(u))
+	for m := range u {
+		res = append(res, m)
+	}
+	return res, nil
+}
+
+// Sdiff subtracts union of other sets from the first one.
+// It isn't atomic across instances
+func (c *cluster) Sdiff(ctx context.Context, keys ...string) ([]string, error) {
+	if len(keys) == 0 {
+		return []string{}, nil
+	}
+
+	order, _ := c.group(keys)
+	if len(order) == 1 {
+		return c.instances[order[0]].Sdiff(ctx, keys...)
+	}
+
+	res, err := c.instance(keys[0]).Smembers(ctx, keys[0])
+	if err != nil {
+		return nil, err
+	}
+
+	others, err := c.Sunion(ctx, keys[1:]...)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := toSet(others)
+	filtered := res[:0]
+	for _, m := range res {
+		if _, ok := sub[m]; !ok {
+			filtered = append(filtered, m)
+		}
+	}
+	return filtered, nil
+}
+
+// toSet returns set of members
+func toSet(members []string) map[string]struct{} {
+	st := make(map[string]struct{}, len(members))
+	for _, m := range members {
+		st[m] = struct{}{}
+	}
+	return st
+}
@@ -0,0 +1,92 @@
+package cluster_test
+
+import (
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/alexxeis/keyval/cluster"
+	"github.com/alexxeis/keyval/storage"
+)
+
+func TestCluster_SetAlgebra(t *testing.T) {
+	c := cluster.NewCluster(10, 0)
+
+	// keys are spread across instances
+	keys := make([]string, 5)
+	for i := range keys {
+		keys[i] = "s" + strconv.Itoa(i)
+		if _, err := c.Sadd(ctx, keys[i], "common", "m"+strconv.Itoa(i)); err != nil {
+			t.Error(err)
+		}
+	}
+
+	members, err := c.Sinter(ctx, keys...)
+	if err != nil {
+		t.Error(err)
+	}
+	if !equalMembers(members, []string{"common"}) {
+		t.Error("wrong intersection ", members)
+	}
+
+	members, err = c.Sunion(ctx, keys...)
+	if err != nil {
+		t.Error(err)
+	}
+	if !equalMembers(members, []string{"common", "m0", "m1", "m2", "m3", "m4"}) {
+		t.Error("wrong union ", members)
+	}
+
+	members, err = c.Sdiff(ctx, keys...)
+	if err != nil {
+		t.Error(err)
+	}
+	if !equalMembers(members, []string{"m0"}) {
+		t.Error("wrong difference ", members)
+	}
+
+	// test missing key
+	members, err = c.Sinter(ctx, append(keys, "missing")...)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(members) != 0 {
+		t.Error("not empty intersection ", members)
+	}
+
+	// test wrong type
+	c.Set(ctx, "string", "v", 0)
+	if _, err = c.Sunion(ctx, append(keys, "string")...); err != storage.ErrorWrongType {
+		t.Error(err)
+	}
+
+	// test wrong type in partition after empty intersection
+	missing := "missing"
+	for i := 0; cluster.Index(c, missing) == cluster.Index(c, "string"); i++ {
+		missing = "missing" + strconv.Itoa(i)
+	}
+	if _, err = c.Sinter(ctx, missing, "string"); err != storage.ErrorWrongType {
+		t.Error(err)
+	}
+	if _, err = c.Sinter(ctx, "string", missing); err != storage.ErrorWrongType {
+		t.Error(err)
+	}
+}
+
+// equalMembers returns true if members are equal regardless of order
+func equalMembers(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	a = append([]string(nil), a...)
+	b = append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,40 @@
+package cluster
+
+import (
+	"context"
+	"time"
+
+	"github.com/alexxeis/keyval/storage"
+)
+
+func (c *cluster) Xadd(ctx context.Context, key, id string, fields map[string]string) (string, error) {
+	return c.instance(key).Xadd(ctx, key, id, fields)
+}
+
+func (c *cluster) Xlen(ctx context.Context, key string) (int, error) {
+	return c.instance(key).Xlen(ctx, key)
+}
+
+func (c *cluster) Xrange(ctx context.Context, key, start, end string, count int) ([]storage.StreamEntry, error) {
+	return c.instance(key).Xrange(ctx, key, start, end, count)
+}
+
+func (c *cluster) Xread(ctx context.Context, key, after string, count int, timeout time.Duration) ([]storage.StreamEntry, error) {
+	return c.instance(key).Xread(ctx, key, after, count, timeout)
+}
+
+func (c *cluster) XgroupCreate(ctx context.Context, key, group, start string) error {
+	return c.instance(key).XgroupCreate(ctx, key, group, start)
+}
+
+func (c *cluster) Xreadgroup(ctx context.Context, key, group, consumer, id string, count int, timeout time.Duration) ([]storage.StreamEntry, error) {
+	return c.instance(key).Xreadgroup(ctx, key, group, consumer, id, count, timeout)
+}
+
+func (c *cluster) Xack(ctx context.Context, key, group string, ids ...string) (int, error) {
+	return c.instance(key).Xack(ctx, key, group, ids...)
+}
+
+func (c *cluster) Xpending(ctx context.Context, key, group string) ([]storage.PendingEntry, error) {
+	return c.instance(key).Xpending(ctx, key, group)
+}
@@ -0,0 +1,43 @@
+package cluster_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alexxeis/keyval/cluster"
+)
+
+func TestCluster_Stream(t *testing.T) {
+	c := cluster.NewCluster(10, 0)
+	key := "x"
+	ctx := context.Background()
+
+	if err := c.XgroupCreate(ctx, key, "g", "$"); err != nil {
+		t.Error(err)
+	}
+
+	id, err := c.Xadd(ctx, key, "*", map[string]string{"f": "v"})
+	if err != nil {
+		t.Error(err)
+	}
+
+	entries, err := c.Xrange(ctx, key, "-", "+", 0)
+	if err != nil || len(entries) != 1 || entries[0].ID != id {
+		t.Error("wrong entries ", entries, err)
+	}
+
+	entries, err = c.Xreadgroup(ctx, key, "g", "c", ">", 0, time.Second)
+	if err != nil || len(entries) != 1 || entries[0].ID != id {
+		t.Error("wrong entries ", entries, err)
+	}
+
+	if n, err := c.Xack(ctx, key, "g", id); err != nil || n != 1 {
+		t.Errorf("expected acked = %d, got %d (%v)", 1, n, err)
+	}
+
+	pending, err := c.Xpending(ctx, key, "g")
+	if err != nil || len(pending) != 0 {
+		t.Error("wrong pending ", pending, err)
+	}
+}
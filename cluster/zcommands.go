@@ -0,0 +1,38 @@
+package cluster
+
+import (
+	"context"
+	"github.com/alexxeis/keyval/storage"
+)
+
+func (c *cluster) Zadd(ctx context.Context, key string, members ...storage.ZMember) (int, error) {
+	return c.instance(key).Zadd(ctx, key, members...)
+}
+
+func (c *cluster) Zrem(ctx context.Context, key string, members ...string) (int, error) {
+	return c.instance(key).Zrem(ctx, key, members...)
+}
+
+func (c *cluster) Zscore(ctx context.Context, key, member string) (float64, error) {
+	return c.instance(key).Zscore(ctx, key, member)
+}
+
+func (c *cluster) Zrank(ctx context.Context, key, member string) (int, error) {
+	return c.instance(key).Zrank(ctx, key, member)
+}
+
+func (c *cluster) Zrange(ctx context.Context, key string, start, stop int) ([]storage.ZMember, error) {
+	return c.instance(key).Zrange(ctx, key, start, stop)
+}
+
+func (c *cluster) ZrangeByScore(ctx context.Context, key string, min, max float64) ([]storage.ZMember, error) {
+	return c.instance(key).ZrangeByScore(ctx, key, min, max)
+}
+
+func (c *cluster) Zincrby(ctx context.Context, key, member string, incr float64) (float64, error) {
+	return c.instance(key).Zincrby(ctx, key, member, incr)
+}
+
+func (c *cluster) Zpopmin(ctx context.Context, key string, count int) ([]storage.ZMember, error) {
+	return c.instance(key).Zpopmin(ctx, key, count)
+}
@@ -0,0 +1,59 @@
+package cluster_test
+
+import (
+	"testing"
+
+	"github.com/alexxeis/keyval/cluster"
+	"github.com/alexxeis/keyval/storage"
+)
+
+func TestCluster_Zset(t *testing.T) {
+	c := cluster.NewCluster(10, 0)
+	key := "z"
+
+	n, err := c.Zadd(ctx, key, storage.ZMember{Member: "a", Score: 2}, storage.ZMember{Member: "b", Score: 1})
+	if err != nil {
+		t.Error(err)
+	}
+	if n != 2 {
+		t.Errorf("expected added = %d, got %d", 2, n)
+	}
+
+	rank, err := c.Zrank(ctx, key, "a")
+	if err != nil {
+		t.Error(err)
+	}
+	if rank != 1 {
+		t.Errorf("expected rank = %d, got %d", 1, rank)
+	}
+
+	score, err := c.Zincrby(ctx, key, "b", 2)
+	if err != nil {
+		t.Error(err)
+	}
+	if score != 3 {
+		t.Errorf("expected score = %v, got %v", 3, score)
+	}
+
+	members, err := c.Zpopmin(ctx, key, 1)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(members) != 1 || members[0].Member != "a" {
+		t.Errorf("wrong members %v", members)
+	}
+
+	members, err = c.ZrangeByScore(ctx, key, 0, 10)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(members) != 1 || members[0].Member != "b" {
+		t.Errorf("wrong members %v", members)
+	}
+
+	// test wrong type
+	c.Set(ctx, "string", "v", 0)
+	if _, err = c.Zscore(ctx, "string", "a"); err != storage.ErrorWrongType {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,189 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/alexxeis/keyval/storage"
+)
+
+// EnvPrefix is a prefix of environment variables, e.g. KEYVAL_STORAGE_PARTITIONS overrides storage.partitions
+const EnvPrefix = "KEYVAL_"
+
+// Config is a server configuration, settings tagged as runtime can be changed without restart
+type Config struct {
+	Listen  string        `toml:"listen"`
+	Storage StorageConfig `toml:"storage"`
+	Auth    AuthConfig    `toml:"auth"`
+	TLS     TLSConfig     `toml:"tls"`
+	Limits  LimitsConfig  `toml:"limits"`
+	Log     LogConfig     `toml:"log"`
+	Tracing TracingConfig `toml:"tracing"`
+}
+
+// StorageConfig is a storage configuration
+type StorageConfig struct {
+	Partitions        int      `toml:"partitions"`
+	CleanInterval     Duration `toml:"clean_interval" runtime:"true"`
+	Compression       string   `toml:"compression"`
+	CompressThreshold int      `toml:"compress_threshold"`
+	Databases         int      `toml:"databases"`
+}
+
+// AuthConfig is an authentication configuration, authentication is disabled if ACL file is empty
+type AuthConfig struct {
+	ACLFile string `toml:"acl_file"`
+}
+
+// TLSConfig is a TLS configuration, TLS is disabled if certificate file is empty
+type TLSConfig struct {
+	CertFile string `toml:"cert_file"`
+	KeyFile  string `toml:"key_file"`
+	CAFile   string `toml:"ca_file"`
+}
+
+// LimitsConfig is a request limits configuration, zero limits are disabled
+type LimitsConfig struct {
+	MaxBodySize    int64    `toml:"max_body_size" runtime:"true"`
+	MaxKeySize     int      `toml:"max_key_size" runtime:"true"`
+	MaxFieldSize   int      `toml:"max_field_size" runtime:"true"`
+	MaxValueSize   int      `toml:"max_value_size" runtime:"true"`
+	Rate           float64  `toml:"rate" runtime:"true"`
+	Burst          int      `toml:"burst" runtime:"true"`
+	RequestTimeout Duration `toml:"request_timeout" runtime:"true"`
+}
+
+// LogConfig is a logging configuration, log is written to stderr if file is empty.
+// Access log is disabled if access file is empty and written to stdout if it's "-"
+type LogConfig struct {
+	File          string   `toml:"file"`
+	AccessFile    string   `toml:"access_file"`
+	AccessSample  float64  `toml:"access_sample" runtime:"true"`
+	SlowThreshold Duration `toml:"slow_threshold" runtime:"true"`
+	SlowlogSize   int      `toml:"slowlog_size" runtime:"true"`
+}
+
+// TracingConfig is a tracing configuration, tracing is disabled if endpoint is empty
+type TracingConfig struct {
+	Endpoint    string  `toml:"endpoint"`
+	Service     string  `toml:"service"`
+	SampleRatio float64 `toml:"sample_ratio" runtime:"true"`
+}
+
+// Duration is a duration written like 1s or 500ms, integer is a count of milliseconds
+type Duration time.Duration
+
+// Default returns default configuration
+func Default() *Config {
+	return &Config{
+		Listen: ":8000",
+		Storage: StorageConfig{
+			Partitions:        100,
+			CleanInterval:     Duration(time.Second),
+			CompressThreshold: 1024,
+			Databases:         16,
+		},
+		Limits: LimitsConfig{
+			MaxBodySize:  16 << 20,
+			MaxKeySize:   1024,
+			MaxFieldSize: 1024,
+			Burst:        100,
+		},
+		Log: LogConfig{
+			AccessSample:  1,
+			SlowThreshold: Duration(10 * time.Millisecond),
+			SlowlogSize:   128,
+		},
+		Tracing: TracingConfig{
+			Service:     "keyval",
+			SampleRatio: 1,
+		},
+	}
+}
+
+// Load returns default configuration overridden by file if path isn't empty and by environment variables
+func Load(path string) (*Config, error) {
+	c := Default()
+	if path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		if err = c.decode(path, f); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := c.ApplyEnv(os.Environ()); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// ApplyEnv overrides configuration by environment variables like KEY=value with EnvPrefix
+func (c *Config) ApplyEnv(env []string) error {
+	for _, kv := range env {
+		if !strings.HasPrefix(kv, EnvPrefix) {
+			continue
+		}
+
+		i := strings.IndexByte(kv, '=')
+		if i < 0 {
+			continue
+		}
+		name, val := kv[:i], kv[i+1:]
+
+		key, ok := c.envKey(name)
+		if !ok {
+			return fmt.Errorf("%s: unknown setting", name)
+		}
+		if err := c.Set(key, val); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// Validate returns error describing all invalid settings
+func (c *Config) Validate() error {
+	var errs []string
+	check := func(ok bool, msg string) {
+		if !ok {
+			errs = append(errs, msg)
+		}
+	}
+
+	check(c.Listen != "", "listen must be set")
+	check(c.Storage.Partitions > 0, "storage.partitions must be positive")
+	check(c.Storage.CleanInterval >= 0, "storage.clean_interval can't be negative")
+	if c.Storage.Compression != "" {
+		_, ok := storage.CodecByName(c.Storage.Compression)
+		check(ok, fmt.Sprintf("storage.compression: unknown codec %q", c.Storage.Compression))
+	}
+	check(c.Storage.CompressThreshold >= 0, "storage.compress_threshold can't be negative")
+	check(c.Storage.Databases > 0, "storage.databases must be positive")
+	check((c.TLS.CertFile == "") == (c.TLS.KeyFile == ""), "tls.cert_file and tls.key_file must be set together")
+	check(c.TLS.CAFile == "" || c.TLS.CertFile != "", "tls.ca_file requires tls.cert_file")
+	check(c.Limits.MaxBodySize >= 0, "limits.max_body_size can't be negative")
+	check(c.Limits.MaxKeySize >= 0, "limits.max_key_size can't be negative")
+	check(c.Limits.MaxFieldSize >= 0, "limits.max_field_size can't be negative")
+	check(c.Limits.MaxValueSize >= 0, "limits.max_value_size can't be negative")
+	check(c.Limits.Rate >= 0, "limits.rate can't be negative")
+	check(c.Limits.Burst > 0, "limits.burst must be positive")
+	check(c.Limits.RequestTimeout >= 0, "limits.request_timeout can't be negative")
+	check(c.Log.AccessSample >= 0 && c.Log.AccessSample <= 1, "log.access_sample must be from 0 to 1")
+	check(c.Log.SlowThreshold >= 0, "log.slow_threshold can't be negative")
+	check(c.Log.SlowlogSize > 0, "log.slowlog_size must be positive")
+	check(c.Tracing.Service != "", "tracing.service must be set")
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio must be from 0 to 1")
+
+	if len(errs) > 0 {
+		return errors.New("invalid config: " + strings.Join(errs, "; "))
+	}
+	return nil
+}
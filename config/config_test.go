@@ -0,0 +1,220 @@
+package config_test
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexxeis/keyval/config"
+)
+
+// writeFile writes temporary config file and returns its path
+func writeFile(t *testing.T, data string) string {
+	f, err := ioutil.TempFile("", "keyval*.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if _, err = f.WriteString(data); err != nil {
+		t.Fatal(err)
+	}
+	return f.Name()
+}
+
+func TestLoad(t *testing.T) {
+	path := writeFile(t, `
+listen = ":9000" # comment
+[storage]
+partitions = 10
+clean_interval = "5s"
+compression = "gzip"
+
+[limits]
+rate = 2.5
+`)
+	defer os.Remove(path)
+
+	os.Setenv("KEYVAL_STORAGE_PARTITIONS", "20")
+	defer os.Unsetenv("KEYVAL_STORAGE_PARTITIONS")
+
+	c, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = c.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	if c.Listen != ":9000" {
+		t.Errorf("expected listen = :9000, got %s", c.Listen)
+	}
+	// test environment overrides file
+	if c.Storage.Partitions != 20 {
+		t.Errorf("expected partitions = 20, got %d", c.Storage.Partitions)
+	}
+	if time.Duration(c.Storage.CleanInterval) != 5*time.Second {
+		t.Errorf("expected clean interval = 5s, got %v", time.Duration(c.Storage.CleanInterval))
+	}
+	if c.Storage.Compression != "gzip" || c.Limits.Rate != 2.5 {
+		t.Errorf("wrong config %+v", c)
+	}
+	// test defaults
+	if c.Storage.Databases != 16 {
+		t.Errorf("expected databases = 16, got %d", c.Storage.Databases)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	files := map[string]string{
+		"unknown key":     "[storage]\nfoo = 1\n",
+		"wrong integer":   "[storage]\npartitions = ten\n",
+		"wrong string":    "listen = \":80\n",
+		"wrong header":    "[storage\n",
+		"missing value":   "listen\n",
+		"wrong duration":  "[storage]\nclean_interval = \"1 second\"\n",
+		"unknown section": "[foo]\nlisten = \":80\"\n",
+	}
+	for name, data := range files {
+		path := writeFile(t, data)
+		if _, err := config.Load(path); err == nil || !strings.HasPrefix(err.Error(), path+":") {
+			t.Errorf("%s: expected error with file position, got %v", name, err)
+		}
+		os.Remove(path)
+	}
+
+	os.Setenv("KEYVAL_FOO", "1")
+	defer os.Unsetenv("KEYVAL_FOO")
+	if _, err := config.Load(""); err == nil {
+		t.Error("expected unknown environment variable error")
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	c := config.Default()
+	if err := c.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	c.Storage.Partitions = 0
+	c.Storage.Compression = "foo"
+	c.TLS.KeyFile = "key.pem"
+	err := c.Validate()
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, key := range []string{"storage.partitions", "storage.compression", "tls.cert_file"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("expected %s in error: %v", key, err)
+		}
+	}
+}
+
+func TestRegisterFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	o := config.RegisterFlags(fs)
+	if err := fs.Parse([]string{"-p", "9000", "-i", "500", "-rate", "10"}); err != nil {
+		t.Fatal(err)
+	}
+
+	c := config.Default()
+	if err := o.Apply(c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Listen != ":9000" || time.Duration(c.Storage.CleanInterval) != 500*time.Millisecond || c.Limits.Rate != 10 {
+		t.Errorf("wrong config %+v", c)
+	}
+
+	if def := fs.Lookup("p").DefValue; def != "8000" {
+		t.Errorf("expected default port = 8000, got %s", def)
+	}
+}
+
+func TestConfig_Encode(t *testing.T) {
+	c := config.Default()
+	c.Log.File = "/var/log/keyval.log"
+
+	var buf bytes.Buffer
+	if err := c.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// test encoded config is loaded back
+	path := writeFile(t, buf.String())
+	defer os.Remove(path)
+
+	loaded, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *loaded != *c {
+		t.Errorf("expected %+v, got %+v", c, loaded)
+	}
+}
+
+func TestRuntime_Set(t *testing.T) {
+	var applied *config.Config
+	r := config.NewRuntime(config.Default(), func(c *config.Config) {
+		applied = c
+	})
+
+	err := r.Set(map[string]string{"limits.rate": "10", "storage.clean_interval": "5s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if applied == nil || applied.Limits.Rate != 10 || time.Duration(applied.Storage.CleanInterval) != 5*time.Second {
+		t.Errorf("wrong applied config %+v", applied)
+	}
+
+	values, err := r.Get("limits.rate", "storage.clean_interval")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values["limits.rate"] != "10" || values["storage.clean_interval"] != "5s" {
+		t.Errorf("wrong values %v", values)
+	}
+
+	for _, values := range []map[string]string{
+		{"storage.partitions": "10"},
+		{"limits.rate": "10", "unknown": "1"},
+		{"limits.burst": "0"},
+		{"limits.rate": "fast"},
+	} {
+		applied = nil
+		if err := r.Set(values); err == nil {
+			t.Errorf("no error for %v", values)
+		}
+		if applied != nil {
+			t.Errorf("config is applied for %v", values)
+		}
+	}
+	if c := r.Config(); c.Limits.Rate != 10 || c.Limits.Burst != 100 {
+		t.Errorf("config is changed on error %+v", c.Limits)
+	}
+}
+
+func TestRuntime_Reload(t *testing.T) {
+	var applied *config.Config
+	r := config.NewRuntime(config.Default(), func(c *config.Config) {
+		applied = c
+	})
+
+	c := config.Default()
+	c.Listen = ":9000"
+	c.Storage.Partitions = 10
+	c.Limits.MaxKeySize = 64
+	restart, err := r.Reload(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(restart, ",") != "listen,storage.partitions" {
+		t.Errorf("wrong restart settings %v", restart)
+	}
+	if applied == nil || applied.Limits.MaxKeySize != 64 || applied.Listen != ":8000" || applied.Storage.Partitions != 100 {
+		t.Errorf("wrong applied config %+v", applied)
+	}
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"flag"
+	"strings"
+)
+
+// flags are command line flags overriding settings, prefix is prepended to flag value
+var flags = []struct {
+	name, key, prefix, usage string
+}{
+	{name: "p", key: "listen", prefix: ":", usage: "listening port"},
+	{name: "c", key: "storage.partitions", usage: "cluster instances count"},
+	{name: "i", key: "storage.clean_interval", usage: "clean interval in milliseconds or duration like 1s"},
+	{name: "z", key: "storage.compression", usage: "values compression codec (gzip), disabled if empty"},
+	{name: "zt", key: "storage.compress_threshold", usage: "min value size in bytes to compress"},
+	{name: "d", key: "storage.databases", usage: "max logical databases count"},
+	{name: "a", key: "auth.acl_file", usage: "ACL file with API tokens, authentication is disabled if empty"},
+	{name: "cert", key: "tls.cert_file", usage: "TLS certificate file, TLS is disabled if empty"},
+	{name: "key", key: "tls.key_file", usage: "TLS private key file"},
+	{name: "ca", key: "tls.ca_file", usage: "client CA file, clients must present certificates signed by it if set"},
+	{name: "maxbody", key: "limits.max_body_size", usage: "max request body size in bytes, unlimited if 0"},
+	{name: "maxkey", key: "limits.max_key_size", usage: "max key size in bytes, unlimited if 0"},
+	{name: "maxfield", key: "limits.max_field_size", usage: "max hash field size in bytes, unlimited if 0"},
+	{name: "maxvalue", key: "limits.max_value_size", usage: "max value size in bytes, unlimited if 0"},
+	{name: "rate", key: "limits.rate", usage: "max requests per second of every client, rate limiting is disabled if 0"},
+	{name: "burst", key: "limits.burst", usage: "max burst of requests of every client"},
+	{name: "timeout", key: "limits.request_timeout", usage: "request processing timeout except blocking commands, unlimited if 0"},
+	{name: "log", key: "log.file", usage: "log file, log is written to stderr if empty"},
+	{name: "access", key: "log.access_file", usage: "JSON access log file, - for stdout, access log is disabled if empty"},
+	{name: "sample", key: "log.access_sample", usage: "fraction of successful requests written to access log, errors are always written"},
+	{name: "slow", key: "log.slow_threshold", usage: "min duration of command in slow log, slow log is disabled if 0"},
+	{name: "otlp", key: "tracing.endpoint", usage: "OTLP/HTTP traces endpoint like http://localhost:4318/v1/traces, tracing is disabled if empty"},
+}
+
+// Overrides are settings passed by command line flags, they are applied after file and environment
+type Overrides struct {
+	keys   []string
+	values []string
+}
+
+// flagValue is a flag of setting
+type flagValue struct {
+	o      *Overrides
+	key    string
+	prefix string
+	def    string
+}
+
+func (f *flagValue) String() string {
+	return f.def
+}
+
+func (f *flagValue) Set(val string) error {
+	f.o.keys = append(f.o.keys, f.key)
+	f.o.values = append(f.o.values, f.prefix+val)
+	return nil
+}
+
+// RegisterFlags registers flags of settings in fs with default values
+func RegisterFlags(fs *flag.FlagSet) *Overrides {
+	o := &Overrides{}
+	def := Default()
+	for _, f := range flags {
+		s, _ := def.setting(f.key)
+		val := strings.TrimPrefix(str(s.value), f.prefix)
+		fs.Var(&flagValue{o: o, key: f.key, prefix: f.prefix, def: val}, f.name, f.usage)
+	}
+	return o
+}
+
+// Apply sets settings passed by flags
+func (o *Overrides) Apply(c *Config) error {
+	for i, key := range o.keys {
+		if err := c.Set(key, o.values[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
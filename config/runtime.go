@@ -0,0 +1,110 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Runtime is a configuration of running server, runtime settings are changed without restart by apply function
+type Runtime struct {
+	mu    sync.Mutex
+	conf  Config
+	apply func(*Config)
+}
+
+// NewRuntime returns runtime configuration, apply is called with every changed configuration
+func NewRuntime(c *Config, apply func(*Config)) *Runtime {
+	return &Runtime{
+		conf:  *c,
+		apply: apply,
+	}
+}
+
+// Config returns copy of current configuration
+func (r *Runtime) Config() Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.conf
+}
+
+// Get returns values of settings by keys or all settings if keys are empty
+func (r *Runtime) Get(keys ...string) (map[string]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res := make(map[string]string)
+	if len(keys) == 0 {
+		for _, s := range r.conf.settings() {
+			res[s.key] = str(s.value)
+		}
+		return res, nil
+	}
+
+	for _, key := range keys {
+		s, ok := r.conf.setting(key)
+		if !ok {
+			return nil, fmt.Errorf("unknown setting %s", key)
+		}
+		res[key] = str(s.value)
+	}
+	return res, nil
+}
+
+// Set changes runtime settings by keys all together, nothing is changed on error
+func (r *Runtime) Set(values map[string]string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next := r.conf
+	for key, val := range values {
+		s, ok := next.setting(key)
+		if !ok {
+			return fmt.Errorf("unknown setting %s", key)
+		}
+		if !s.runtime {
+			return fmt.Errorf("setting %s can't be changed at runtime", key)
+		}
+		if err := next.Set(key, val); err != nil {
+			return err
+		}
+	}
+
+	return r.update(&next)
+}
+
+// Reload changes runtime settings by loaded configuration,
+// returns sorted keys of changed settings requiring restart, they are ignored
+func (r *Runtime) Reload(c *Config) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next := r.conf
+	var restart []string
+	cur, loaded := next.settings(), c.settings()
+	for i, s := range cur {
+		if str(s.value) == str(loaded[i].value) {
+			continue
+		}
+		if !s.runtime {
+			restart = append(restart, s.key)
+			continue
+		}
+		s.value.Set(loaded[i].value)
+	}
+	sort.Strings(restart)
+
+	return restart, r.update(&next)
+}
+
+// update validates and applies configuration, must be called under lock
+func (r *Runtime) update(c *Config) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	r.conf = *c
+	r.apply(c)
+	return nil
+}
@@ -0,0 +1,204 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// setting is a configuration field by dotted key like storage.partitions
+type setting struct {
+	key     string
+	value   reflect.Value
+	runtime bool
+}
+
+// settings returns configuration fields in order of declaration
+func (c *Config) settings() []setting {
+	var res []setting
+	v := reflect.ValueOf(c).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name := field.Tag.Get("toml")
+		section := v.Field(i)
+		if section.Kind() != reflect.Struct {
+			res = append(res, setting{name, section, field.Tag.Get("runtime") == "true"})
+			continue
+		}
+
+		for j := 0; j < section.NumField(); j++ {
+			field := section.Type().Field(j)
+			res = append(res, setting{name + "." + field.Tag.Get("toml"), section.Field(j), field.Tag.Get("runtime") == "true"})
+		}
+	}
+	return res
+}
+
+// setting returns configuration field by key
+func (c *Config) setting(key string) (setting, bool) {
+	for _, s := range c.settings() {
+		if s.key == key {
+			return s, true
+		}
+	}
+	return setting{}, false
+}
+
+// envKey returns key of setting by environment variable name
+func (c *Config) envKey(name string) (string, bool) {
+	for _, s := range c.settings() {
+		if EnvPrefix+strings.ToUpper(strings.Replace(s.key, ".", "_", -1)) == name {
+			return s.key, true
+		}
+	}
+	return "", false
+}
+
+// parseDuration parses duration like 1s or integer count of milliseconds
+func parseDuration(s string) (time.Duration, error) {
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// Set sets setting by key parsing value by its type
+func (c *Config) Set(key, val string) error {
+	s, ok := c.setting(key)
+	if !ok {
+		return fmt.Errorf("unknown setting %s", key)
+	}
+
+	if _, ok := s.value.Interface().(Duration); ok {
+		d, err := parseDuration(val)
+		if err != nil {
+			return fmt.Errorf("%s: wrong duration %q", key, val)
+		}
+		s.value.SetInt(int64(d))
+		return nil
+	}
+
+	switch s.value.Kind() {
+	case reflect.String:
+		s.value.SetString(val)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(val, 10, s.value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%s: wrong integer %q", key, val)
+		}
+		s.value.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return fmt.Errorf("%s: wrong number %q", key, val)
+		}
+		s.value.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return fmt.Errorf("%s: wrong boolean %q", key, val)
+		}
+		s.value.SetBool(b)
+	}
+	return nil
+}
+
+// str returns setting value as it's passed to Set
+func str(v reflect.Value) string {
+	if d, ok := v.Interface().(Duration); ok {
+		return time.Duration(d).String()
+	}
+	if v.Kind() == reflect.Float64 {
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// format returns setting value in TOML
+func format(v reflect.Value) string {
+	if _, ok := v.Interface().(Duration); ok || v.Kind() == reflect.String {
+		return strconv.Quote(str(v))
+	}
+	return str(v)
+}
+
+// stripComment returns line without comment, # in quoted strings is kept
+func stripComment(line string) string {
+	quoted := false
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '"':
+			quoted = !quoted
+		case '#':
+			if !quoted {
+				return line[:i]
+			}
+		}
+	}
+	return line
+}
+
+// decode reads settings from a subset of TOML: [section] tables, key = value pairs and # comments,
+// values are quoted strings, integers, floats and booleans. Name is used in errors
+func (c *Config) decode(name string, r io.Reader) error {
+	section := ""
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(stripComment(sc.Text()))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return fmt.Errorf("%s:%d: wrong table header", name, n)
+			}
+			section = strings.TrimSpace(line[1:len(line)-1]) + "."
+			continue
+		}
+
+		i := strings.IndexByte(line, '=')
+		if i < 0 {
+			return fmt.Errorf("%s:%d: expected key = value", name, n)
+		}
+		key := section + strings.TrimSpace(line[:i])
+		val := strings.TrimSpace(line[i+1:])
+
+		if strings.HasPrefix(val, `"`) {
+			s, err := strconv.Unquote(val)
+			if err != nil {
+				return fmt.Errorf("%s:%d: wrong string %s", name, n, val)
+			}
+			val = s
+		}
+
+		if err := c.Set(key, val); err != nil {
+			return fmt.Errorf("%s:%d: %v", name, n, err)
+		}
+	}
+	return sc.Err()
+}
+
+// Encode writes configuration in TOML
+func (c *Config) Encode(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	section := ""
+	for _, s := range c.settings() {
+		key := s.key
+		if i := strings.IndexByte(key, '.'); i >= 0 {
+			if key[:i] != section {
+				section = key[:i]
+				fmt.Fprintf(bw, "\n[%s]\n", section)
+			}
+			key = key[i+1:]
+		}
+		fmt.Fprintf(bw, "%s = %s\n", key, format(s.value))
+	}
+	return bw.Flush()
+}
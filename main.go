@@ -1,42 +1,227 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/alexxeis/keyval/api"
+	"github.com/alexxeis/keyval/certs"
 	"github.com/alexxeis/keyval/cluster"
+	"github.com/alexxeis/keyval/config"
+	"github.com/alexxeis/keyval/storage"
+	"github.com/alexxeis/keyval/tracing"
 	"github.com/gorilla/mux"
 )
 
+// shutdownTimeout limits waiting for active requests on shutdown
+const shutdownTimeout = 10 * time.Second
+
 func main() {
-	port := flag.String("p", "8000", "listening port")
-	count := flag.Int("c", 100, "cluster instances count")
-	cleanInterval := flag.Int64("i", 1000, "clean interval in milliseconds")
+	configPath := flag.String("config", "", "config file, settings are overridden by "+config.EnvPrefix+"* environment variables and flags")
+	printConfig := flag.Bool("print-config", false, "print effective config and exit")
+	overrides := config.RegisterFlags(flag.CommandLine)
 	flag.Parse()
 
-	if *port == "" || *count < 1 || *cleanInterval < 0 {
-		flag.PrintDefaults()
-		os.Exit(1)
+	conf, err := config.Load(*configPath)
+	if err == nil {
+		err = overrides.Apply(conf)
+	}
+	if err == nil {
+		err = conf.Validate()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *printConfig {
+		if err = conf.Encode(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if conf.Log.File != "" {
+		f, err := os.OpenFile(conf.Log.File, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.SetOutput(f)
+	}
+
+	var opts []storage.Option
+	if conf.Storage.Compression != "" {
+		codec, _ := storage.CodecByName(conf.Storage.Compression)
+		opts = append(opts, storage.WithCompression(codec, conf.Storage.CompressThreshold))
+	}
+
+	var acl *api.ACL
+	if conf.Auth.ACLFile != "" {
+		if acl, err = api.LoadACL(conf.Auth.ACLFile); err != nil {
+			log.Fatal(err)
+		}
 	}
 
-	ci := time.Duration(*cleanInterval) * time.Millisecond
-	c := cluster.NewCluster(*count, ci)
-	handler := api.NewHandler(c)
+	dbs := cluster.NewDatabases(conf.Storage.Partitions, conf.Storage.Databases, time.Duration(conf.Storage.CleanInterval), opts...)
+
+	var accessLog *api.AccessLogger
+	switch conf.Log.AccessFile {
+	case "":
+	case "-":
+		accessLog = api.NewAccessLogger(os.Stdout, conf.Log.AccessSample)
+	default:
+		f, err := os.OpenFile(conf.Log.AccessFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			log.Fatal(err)
+		}
+		accessLog = api.NewAccessLogger(f, conf.Log.AccessSample)
+	}
+	slowLog := api.NewSlowLog(time.Duration(conf.Log.SlowThreshold), conf.Log.SlowlogSize)
+
+	var tracer *tracing.Tracer
+	var exporter *tracing.Exporter
+	if conf.Tracing.Endpoint != "" {
+		exporter = tracing.NewExporter(conf.Tracing.Endpoint, conf.Tracing.Service, nil)
+		tracer = tracing.NewTracer(conf.Tracing.Service, conf.Tracing.SampleRatio, exporter)
+	}
+
+	sizeLimiter := api.NewSizeLimiter(limits(conf))
+	limiter := api.NewRateLimiter(conf.Limits.Rate, conf.Limits.Burst)
+	timeout := api.NewRequestTimeout(time.Duration(conf.Limits.RequestTimeout))
+
+	settings := config.NewRuntime(conf, func(c *config.Config) {
+		dbs.SetCleanInterval(time.Duration(c.Storage.CleanInterval))
+		sizeLimiter.SetLimits(limits(c))
+		limiter.SetRate(c.Limits.Rate, c.Limits.Burst)
+		timeout.SetTimeout(time.Duration(c.Limits.RequestTimeout))
+		if accessLog != nil {
+			accessLog.SetSample(c.Log.AccessSample)
+		}
+		slowLog.SetThreshold(time.Duration(c.Log.SlowThreshold), c.Log.SlowlogSize)
+		if tracer != nil {
+			tracer.SetRatio(c.Tracing.SampleRatio)
+		}
+	})
+
+	// span includes all middlewares, access log is written next to log rejected requests, limits are checked next,
+	// so other middlewares get limited body, limiter identifies clients by token and limits unauthenticated requests
+	// before ACL rejects them, timeout and slow log cover handler only
+	middlewares := []mux.MiddlewareFunc{
+		api.Tracing(tracer), accessLog.Middleware, sizeLimiter.Middleware, acl.Identify, limiter.Middleware,
+		acl.Middleware, timeout.Middleware, slowLog.Middleware,
+	}
+	wrap := func(h http.Handler) http.Handler {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			h = middlewares[i](h)
+		}
+		return h
+	}
 
 	router := mux.NewRouter()
-	router.HandleFunc("/api/keys", handler.Keys).Methods(http.MethodGet)
-	router.HandleFunc("/api/get/{key}", handler.Get).Methods(http.MethodGet)
-	router.HandleFunc("/api/set/{key}", handler.Set).Methods(http.MethodPost)
-	router.HandleFunc("/api/remove/{key}", handler.Remove).Methods(http.MethodPost)
-	router.HandleFunc("/api/expire/{key}", handler.Expire).Methods(http.MethodPost)
-	router.HandleFunc("/api/hget/{key}/{field}", handler.Hget).Methods(http.MethodGet)
-	router.HandleFunc("/api/hset/{key}/{field}", handler.Hset).Methods(http.MethodPost)
-	router.HandleFunc("/api/hdel/{key}/{field}", handler.Hdel).Methods(http.MethodPost)
-
-	// TODO: graceful shutdown
-	log.Fatal(http.ListenAndServe(":"+*port, router))
+	// databases are created on routing, so requests without access to the database are rejected before it,
+	// they are rate limited first, limiter of database routes doesn't count them again
+	router.PathPrefix(api.DatabasePrefix + "/").Handler(acl.Identify(limiter.Middleware(
+		acl.AuthorizeDatabase(api.NewDatabasesHandler(dbs, middlewares...)))))
+	router.Handle("/api/flushall", wrap(api.Flushall(dbs))).Methods(http.MethodPost).Name("flushall")
+	router.Handle("/api/metrics", wrap(api.MetricsHandler(sizeLimiter, limiter))).Methods(http.MethodGet).Name("metrics")
+	router.Handle("/api/config", wrap(api.ConfigGet(settings))).Methods(http.MethodGet).Name("configget")
+	router.Handle("/api/config", wrap(api.ConfigSet(settings))).Methods(http.MethodPost).Name("configset")
+	router.Handle("/api/slowlog", wrap(api.SlowLogHandler(slowLog))).Methods(http.MethodGet).Name("slowlog")
+	router.Handle("/api/slowlog", wrap(api.SlowLogReset(slowLog))).Methods(http.MethodDelete).Name("slowlogreset")
+
+	apiRouter := router.PathPrefix("/api").Subrouter()
+	apiRouter.Use(middlewares...)
+	api.NewHandler(dbs.Default()).Register(apiRouter)
+
+	var reloader *certs.Reloader
+	if conf.TLS.CertFile != "" {
+		if reloader, err = certs.NewReloader(conf.TLS.CertFile, conf.TLS.KeyFile, conf.TLS.CAFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+	go reload(settings, *configPath, overrides, reloader)
+
+	srv := &http.Server{Addr: conf.Listen, Handler: router}
+	stopped := make(chan struct{})
+	go shutdown(srv, stopped)
+
+	if reloader == nil {
+		err = srv.ListenAndServe()
+	} else {
+		srv.TLSConfig = reloader.TLSConfig()
+		err = srv.ListenAndServeTLS("", "")
+	}
+	if err != http.ErrServerClosed {
+		log.Fatal(err)
+	}
+	<-stopped
+
+	// spans of the last requests are exported before exit
+	if exporter != nil {
+		exporter.Shutdown()
+	}
+}
+
+// shutdown stops server gracefully on SIGINT or SIGTERM and closes stopped after active requests are done
+func shutdown(srv *http.Server, stopped chan<- struct{}) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	<-ch
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Print("shutdown failed: ", err)
+	}
+	close(stopped)
+}
+
+// limits returns request size limits by config
+func limits(c *config.Config) api.Limits {
+	return api.Limits{
+		MaxBodySize:  c.Limits.MaxBodySize,
+		MaxKeySize:   c.Limits.MaxKeySize,
+		MaxFieldSize: c.Limits.MaxFieldSize,
+		MaxValueSize: c.Limits.MaxValueSize,
+	}
+}
+
+// reload reloads runtime settings and TLS certificates on SIGHUP, flags override reloaded config
+func reload(settings *config.Runtime, path string, overrides *config.Overrides, r *certs.Reloader) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGHUP)
+
+	for range ch {
+		conf, err := config.Load(path)
+		if err == nil {
+			err = overrides.Apply(conf)
+		}
+		var restart []string
+		if err == nil {
+			restart, err = settings.Reload(conf)
+		}
+		if err != nil {
+			log.Print("config reload failed: ", err)
+		} else {
+			log.Print("config reloaded")
+			if len(restart) > 0 {
+				log.Print("changed settings require restart: ", strings.Join(restart, ", "))
+			}
+		}
+
+		if r == nil {
+			continue
+		}
+		if err = r.Reload(); err != nil {
+			log.Print("certificates reload failed: ", err)
+			continue
+		}
+		log.Print("certificates reloaded")
+	}
 }
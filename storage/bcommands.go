@@ -0,0 +1,155 @@
+package storage
+
+import "context"
+
+// bytesByKey returns string value data by key, returned data must not be modified, must be called under lock
+func (s *storage) bytesByKey(key string) ([]byte, string, error) {
+	i, ok := s.items[key]
+	if !ok || i.expired() {
+		return nil, "", ErrorNotFound
+	}
+
+	switch val := i.value.(type) {
+	case string:
+		return []byte(val), "", nil
+	case raw:
+		return val.data, val.contentType, nil
+	case *bitmap:
+		return val.data, val.contentType, nil
+	case compressed:
+		data, err := val.decompress()
+		return data, val.contentType, err
+	}
+
+	return nil, "", ErrorWrongType
+}
+
+// bitmapByKey returns bitmap by key converting string value to it, creates new one if key is missing,
+// must be called under lock
+func (s *storage) bitmapByKey(key string) (*bitmap, error) {
+	i, ok := s.items[key]
+	if !ok || i.expired() {
+		b := &bitmap{}
+		s.setItem(key, item{value: b})
+		return b, nil
+	}
+
+	if b, ok := i.value.(*bitmap); ok {
+		return b, nil
+	}
+
+	data, contentType, err := s.bytesByKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// raw data can be shared with clients, so it's copied before modifications
+	b := &bitmap{
+		data:        append([]byte(nil), data...),
+		contentType: contentType,
+	}
+	i.value = b
+	s.items[key] = i
+	return b, nil
+}
+
+func (s *storage) Setbit(ctx context.Context, key string, offset int, bit bool) (bool, error) {
+	if offset < 0 || offset > MaxBitOffset {
+		return false, ErrorBitOffset
+	}
+
+	s.lock(ctx)
+	defer s.mu.Unlock()
+
+	b, err := s.bitmapByKey(key)
+	if err != nil {
+		return false, err
+	}
+
+	i := offset / 8
+	if i >= len(b.data) {
+		b.data = append(b.data, make([]byte, i+1-len(b.data))...)
+	}
+
+	old := getBit(b.data, offset)
+	mask := byte(0x80 >> uint(offset%8))
+	if bit {
+		b.data[i] |= mask
+	} else {
+		b.data[i] &^= mask
+	}
+	return old, nil
+}
+
+func (s *storage) Getbit(ctx context.Context, key string, offset int) (bool, error) {
+	if offset < 0 || offset > MaxBitOffset {
+		return false, ErrorBitOffset
+	}
+
+	s.rlock(ctx)
+	defer s.mu.RUnlock()
+
+	data, _, err := s.bytesByKey(key)
+	if err == ErrorNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return getBit(data, offset), nil
+}
+
+func (s *storage) Bitcount(ctx context.Context, key string, start, end int) (int, error) {
+	s.rlock(ctx)
+	defer s.mu.RUnlock()
+
+	data, _, err := s.bytesByKey(key)
+	if err == ErrorNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return bitCount(data, start, end), nil
+}
+
+func (s *storage) Bitpos(ctx context.Context, key string, bit bool, start, end int) (int, error) {
+	s.rlock(ctx)
+	defer s.mu.RUnlock()
+
+	data, _, err := s.bytesByKey(key)
+	if err != nil && err != ErrorNotFound {
+		return 0, err
+	}
+
+	return bitPos(data, bit, start, end), nil
+}
+
+func (s *storage) Bitop(ctx context.Context, op BitOp, dest string, keys ...string) (int, error) {
+	s.lock(ctx)
+	defer s.mu.Unlock()
+
+	values := make([][]byte, len(keys))
+	for i, key := range keys {
+		data, _, err := s.bytesByKey(key)
+		if err != nil && err != ErrorNotFound {
+			return 0, err
+		}
+		values[i] = data
+	}
+
+	res, err := op.Apply(values)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(res) == 0 {
+		s.deleteItem(dest)
+		return 0, nil
+	}
+
+	s.setItem(dest, item{value: &bitmap{data: res}})
+	return len(res), nil
+}
@@ -0,0 +1,206 @@
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/alexxeis/keyval/storage"
+)
+
+func TestStorage_Setbit(t *testing.T) {
+	s := storage.NewStorage(0)
+	key := "b"
+
+	old, err := s.Setbit(ctx, key, 7, true)
+	if err != nil {
+		t.Error(err)
+	}
+	if old {
+		t.Error("expected previous bit 0")
+	}
+
+	old, err = s.Setbit(ctx, key, 7, false)
+	if err != nil {
+		t.Error(err)
+	}
+	if !old {
+		t.Error("expected previous bit 1")
+	}
+
+	if _, err = s.Setbit(ctx, key, 1, true); err != nil {
+		t.Error(err)
+	}
+
+	// bits are numbered from the most significant one
+	val, err := s.Get(ctx, key)
+	if err != nil {
+		t.Error(err)
+	}
+	if val != "\x40" {
+		t.Errorf("expected value %q, got %q", "\x40", val)
+	}
+
+	// test string value modification
+	s.Set(ctx, "str", "a", 0)
+	if _, err = s.Setbit(ctx, "str", 6, true); err != nil {
+		t.Error(err)
+	}
+	if val, err = s.Get(ctx, "str"); err != nil || val != "c" {
+		t.Errorf("expected value %q, got %q, %v", "c", val, err)
+	}
+
+	// test raw value is copied before modification
+	data := []byte{0x00}
+	s.SetRaw(ctx, "raw", data, "application/octet-stream", 0)
+	if _, err = s.Setbit(ctx, "raw", 0, true); err != nil {
+		t.Error(err)
+	}
+	if data[0] != 0 {
+		t.Error("raw value is modified")
+	}
+	res, contentType, err := s.GetRaw(ctx, "raw")
+	if err != nil {
+		t.Error(err)
+	}
+	if len(res) != 1 || res[0] != 0x80 || contentType != "application/octet-stream" {
+		t.Errorf("wrong raw value %v, %q", res, contentType)
+	}
+
+	// test wrong offset
+	if _, err = s.Setbit(ctx, key, -1, true); err != storage.ErrorBitOffset {
+		t.Error(err)
+	}
+
+	// test wrong type
+	if _, err = s.Sadd(ctx, "set", "a"); err != nil {
+		t.Error(err)
+	}
+	if _, err = s.Setbit(ctx, "set", 0, true); err != storage.ErrorWrongType {
+		t.Error(err)
+	}
+}
+
+func TestStorage_Getbit(t *testing.T) {
+	s := storage.NewStorage(0)
+	s.Set(ctx, "str", "a", 0)
+
+	for offset, expected := range []bool{false, true, true, false, false, false, false, true, false} {
+		bit, err := s.Getbit(ctx, "str", offset)
+		if err != nil {
+			t.Error(err)
+		}
+		if bit != expected {
+			t.Errorf("wrong bit at offset %d", offset)
+		}
+	}
+
+	// test missing key
+	bit, err := s.Getbit(ctx, "missing", 0)
+	if err != nil {
+		t.Error(err)
+	}
+	if bit {
+		t.Error("expected bit 0")
+	}
+}
+
+func TestStorage_Bitcount(t *testing.T) {
+	s := storage.NewStorage(0)
+	s.Set(ctx, "str", "foobar", 0)
+
+	for _, c := range []struct {
+		start, end, count int
+	}{
+		{0, -1, 26},
+		{0, 0, 4},
+		{1, 1, 6},
+		{-2, -1, 7},
+		{5, 100, 4},
+		{3, 1, 0},
+	} {
+		n, err := s.Bitcount(ctx, "str", c.start, c.end)
+		if err != nil {
+			t.Error(err)
+		}
+		if n != c.count {
+			t.Errorf("expected count = %d from %d to %d, got %d", c.count, c.start, c.end, n)
+		}
+	}
+}
+
+func TestStorage_Bitpos(t *testing.T) {
+	s := storage.NewStorage(0)
+	s.SetRaw(ctx, "b", []byte{0xff, 0xf0, 0x00}, "", 0)
+	s.SetRaw(ctx, "full", []byte{0xff, 0xff}, "", 0)
+
+	for _, c := range []struct {
+		key        string
+		bit        bool
+		start, end int
+		pos        int
+	}{
+		{"b", false, 0, -1, 12},
+		{"b", true, 0, -1, 0},
+		{"b", true, 2, -1, -1},
+		{"full", false, 0, -1, 16},
+		{"full", false, 0, 1, -1},
+		{"missing", false, 0, -1, 0},
+		{"missing", true, 0, -1, -1},
+	} {
+		pos, err := s.Bitpos(ctx, c.key, c.bit, c.start, c.end)
+		if err != nil {
+			t.Error(err)
+		}
+		if pos != c.pos {
+			t.Errorf("expected position = %d for %s, got %d", c.pos, c.key, pos)
+		}
+	}
+}
+
+func TestStorage_Bitop(t *testing.T) {
+	s := storage.NewStorage(0)
+	s.SetRaw(ctx, "a", []byte{0xf0, 0x0f}, "", 0)
+	s.SetRaw(ctx, "b", []byte{0x3c}, "", 0)
+
+	for _, c := range []struct {
+		op       storage.BitOp
+		keys     []string
+		expected []byte
+	}{
+		{storage.BitAnd, []string{"a", "b"}, []byte{0x30, 0x00}},
+		{storage.BitOr, []string{"a", "b"}, []byte{0xfc, 0x0f}},
+		{storage.BitXor, []string{"a", "b", "missing"}, []byte{0xcc, 0x0f}},
+		{storage.BitNot, []string{"a"}, []byte{0x0f, 0xf0}},
+	} {
+		n, err := s.Bitop(ctx, c.op, "dest", c.keys...)
+		if err != nil {
+			t.Error(err)
+		}
+		if n != len(c.expected) {
+			t.Errorf("expected length = %d, got %d", len(c.expected), n)
+		}
+
+		res, _, err := s.GetRaw(ctx, "dest")
+		if err != nil {
+			t.Error(err)
+		}
+		if string(res) != string(c.expected) {
+			t.Errorf("wrong %s result %v", c.op, res)
+		}
+	}
+
+	// test empty result
+	if _, err := s.Bitop(ctx, storage.BitOr, "dest", "missing"); err != nil {
+		t.Error(err)
+	}
+	if _, err := s.Get(ctx, "dest"); err != storage.ErrorNotFound {
+		t.Error(err)
+	}
+
+	// test wrong operation
+	if _, err := s.Bitop(ctx, storage.BitNot, "dest", "a", "b"); err != storage.ErrorBitOp {
+		t.Error(err)
+	}
+	if _, err := s.Bitop(ctx, "nand", "dest", "a", "b"); err != storage.ErrorBitOp {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,145 @@
+package storage
+
+import "math/bits"
+
+// MaxBitOffset is the max bit offset of a bitmap
+const MaxBitOffset = 1<<32 - 1
+
+// BitOp is a bitwise operation between string values
+type BitOp string
+
+// Bitwise operations
+const (
+	BitAnd BitOp = "and"
+	BitOr  BitOp = "or"
+	BitXor BitOp = "xor"
+	BitNot BitOp = "not"
+)
+
+// bitmap is a binary value modified in place by bit commands
+type bitmap struct {
+	data        []byte
+	contentType string
+}
+
+// Apply returns result of the operation over values, shorter values are padded with zero bytes.
+// Returns ErrorBitOp if operation is unknown or NOT is applied to more than one value
+func (op BitOp) Apply(values [][]byte) ([]byte, error) {
+	switch op {
+	case BitAnd, BitOr, BitXor:
+	case BitNot:
+		if len(values) != 1 {
+			return nil, ErrorBitOp
+		}
+	default:
+		return nil, ErrorBitOp
+	}
+
+	size := 0
+	for _, v := range values {
+		if len(v) > size {
+			size = len(v)
+		}
+	}
+
+	res := make([]byte, size)
+	if len(values) == 0 {
+		return res, nil
+	}
+	copy(res, values[0])
+
+	if op == BitNot {
+		for i := range res {
+			res[i] = ^res[i]
+		}
+		return res, nil
+	}
+
+	for _, v := range values[1:] {
+		for i := range res {
+			var b byte
+			if i < len(v) {
+				b = v[i]
+			}
+
+			switch op {
+			case BitAnd:
+				res[i] &= b
+			case BitOr:
+				res[i] |= b
+			case BitXor:
+				res[i] ^= b
+			}
+		}
+	}
+	return res, nil
+}
+
+// getBit returns bit at offset, bits after the end are zero
+func getBit(data []byte, offset int) bool {
+	i := offset / 8
+	if i >= len(data) {
+		return false
+	}
+	return data[i]&(0x80>>uint(offset%8)) != 0
+}
+
+// byteRange normalizes byte range from start to end inclusive, negative indexes count from the end.
+// Returns false if range is empty
+func byteRange(size, start, end int) (int, int, bool) {
+	if start < 0 {
+		start += size
+	}
+	if end < 0 {
+		end += size
+	}
+	if start < 0 {
+		start = 0
+	}
+	if end >= size {
+		end = size - 1
+	}
+	return start, end, start <= end
+}
+
+// bitCount returns count of set bits in bytes from start to end inclusive
+func bitCount(data []byte, start, end int) int {
+	start, end, ok := byteRange(len(data), start, end)
+	if !ok {
+		return 0
+	}
+
+	n := 0
+	for _, b := range data[start : end+1] {
+		n += bits.OnesCount8(b)
+	}
+	return n
+}
+
+// bitPos returns position of the first bit equal to bit in bytes from start to end inclusive or -1.
+// Clear bit is found right after the value if range isn't limited by end, as value is padded with zeros
+func bitPos(data []byte, bit bool, start, end int) int {
+	unlimited := end == -1
+	start, end, ok := byteRange(len(data), start, end)
+	if !ok {
+		if !bit && unlimited && start >= len(data) {
+			return start * 8
+		}
+		return -1
+	}
+
+	for i := start; i <= end; i++ {
+		b := data[i]
+		if !bit {
+			b = ^b
+		}
+		if b != 0 {
+			return i*8 + bits.LeadingZeros8(b)
+		}
+	}
+
+	if !bit && unlimited {
+		return (end + 1) * 8
+	}
+	return -1
+}
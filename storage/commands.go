@@ -1,14 +1,47 @@
 package storage
 
 import (
+	"context"
 	"errors"
+	"math"
 	"time"
 )
 
+// NoTtl is a TTL of value without expiration
+const NoTtl time.Duration = -1
+
 var (
-	ErrorWrongType = errors.New("operation against a key holding the wrong kind of value")
+	ErrorWrongType  = errors.New("operation against a key holding the wrong kind of value")
+	ErrorNotFound   = errors.New("no such key")
+	ErrorNotANumber = errors.New("score is not a number")
+	ErrorTimeout    = errors.New("timeout")
+
+	ErrorScoreOverflow = errors.New("finite score is incremented to infinity")
+
+	ErrorStreamID    = errors.New("invalid stream ID or ID is equal or smaller than the last one")
+	ErrorNoGroup     = errors.New("no such key or consumer group")
+	ErrorGroupExists = errors.New("consumer group already exists")
+
+	ErrorBitOffset = errors.New("bit offset is not an integer or out of range")
+	ErrorBitOp     = errors.New("unknown bit operation or NOT with more than one key")
+
+	ErrorCoordinates = errors.New("invalid longitude or latitude")
+	ErrorGeoQuery    = errors.New("radius or width and height must be positive")
+
+	ErrorJSON     = errors.New("invalid JSON value")
+	ErrorJSONPath = errors.New("invalid JSON path")
+	ErrorNoPath   = errors.New("no such JSON path")
+
+	ErrorIndexExists = errors.New("index already exists")
+	ErrorNoIndex     = errors.New("no such index")
+
+	ErrorSameKey        = errors.New("source and destination keys are the same")
+	ErrorForeignStorage = errors.New("storage instance isn't created by NewStorage")
 )
 
+// maxExpiration is the latest time of nanoseconds timestamp
+var maxExpiration = time.Unix(0, math.MaxInt64)
+
 // getExpiration returns expiration timestamp by TTL
 func getExpiration(ttl time.Duration) int64 {
 	if ttl < 0 {
@@ -16,16 +49,20 @@ func getExpiration(ttl time.Duration) int64 {
 	}
 
 	if ttl > 0 {
-		return time.Now().Add(ttl).UnixNano()
+		// expiration beyond nanoseconds range never comes
+		if exp := time.Now().Add(ttl); exp.Before(maxExpiration) {
+			return exp.UnixNano()
+		}
+		return math.MaxInt64
 	}
 
 	return 0
 }
 
-func (s *storage) Expire(key string, ttl time.Duration) bool {
+func (s *storage) Expire(ctx context.Context, key string, ttl time.Duration) bool {
 	exp := getExpiration(ttl)
 
-	s.mu.Lock()
+	s.lock(ctx)
 	defer s.mu.Unlock()
 
 	i, ok := s.items[key]
@@ -38,116 +75,283 @@ func (s *storage) Expire(key string, ttl time.Duration) bool {
 	return true
 }
 
-func (s *storage) Set(key, val string, ttl time.Duration) {
+func (s *storage) Set(ctx context.Context, key, val string, ttl time.Duration) {
 	exp := getExpiration(ttl)
 
-	s.mu.Lock()
-	s.items[key] = item{
-		value:      val,
-		expiration: exp,
+	var v interface{} = val
+	if s.compressible(len(val)) {
+		if c, ok := s.compress([]byte(val), ""); ok {
+			v = c
+		}
 	}
+
+	s.lock(ctx)
+	s.setItem(key, item{
+		value:      v,
+		expiration: exp,
+	})
 	s.mu.Unlock()
 }
 
-func (s *storage) Get(key string) (string, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+func (s *storage) Get(ctx context.Context, key string) (string, error) {
+	s.rlock(ctx)
+	i, ok := s.items[key]
+	if b, isBitmap := i.value.(*bitmap); isBitmap {
+		// bitmap is modified in place, so it's copied under lock
+		i.value = string(b.data)
+	}
+	s.mu.RUnlock()
+
+	if !ok || i.expired() {
+		return "", ErrorNotFound
+	}
+
+	switch val := i.value.(type) {
+	case string:
+		return val, nil
+	case raw:
+		return string(val.data), nil
+	case compressed:
+		// decompress without lock, compressed data is never modified
+		data, err := val.decompress()
+		return string(data), err
+	}
+
+	return "", ErrorWrongType
+}
+
+func (s *storage) SetRaw(ctx context.Context, key string, val []byte, contentType string, ttl time.Duration) {
+	exp := getExpiration(ttl)
 
+	var v interface{} = raw{
+		data:        val,
+		contentType: contentType,
+	}
+	if s.compressible(len(val)) {
+		if c, ok := s.compress(val, contentType); ok {
+			v = c
+		}
+	}
+
+	s.lock(ctx)
+	s.setItem(key, item{
+		value:      v,
+		expiration: exp,
+	})
+	s.mu.Unlock()
+}
+
+func (s *storage) GetRaw(ctx context.Context, key string) ([]byte, string, error) {
+	s.rlock(ctx)
 	i, ok := s.items[key]
-	if !ok {
-		return "", nil
+	if b, isBitmap := i.value.(*bitmap); isBitmap {
+		// bitmap is modified in place, so it's copied under lock
+		i.value = raw{
+			data:        append([]byte(nil), b.data...),
+			contentType: b.contentType,
+		}
 	}
+	s.mu.RUnlock()
 
-	if i.expired() {
-		return "", nil
+	if !ok || i.expired() {
+		return nil, "", ErrorNotFound
 	}
 
-	val, ok := i.value.(string)
-	if !ok {
-		return "", ErrorWrongType
+	switch val := i.value.(type) {
+	case raw:
+		return val.data, val.contentType, nil
+	case string:
+		return []byte(val), "", nil
+	case compressed:
+		data, err := val.decompress()
+		return data, val.contentType, err
 	}
 
-	return val, nil
+	return nil, "", ErrorWrongType
 }
 
-func (s *storage) Remove(key string) {
-	s.mu.Lock()
-	delete(s.items, key)
+func (s *storage) Remove(ctx context.Context, key string) {
+	s.lock(ctx)
+	s.deleteItem(key)
 	s.mu.Unlock()
 }
 
-func (s *storage) Keys() []string {
-	s.mu.RLock()
+func (s *storage) Flush(ctx context.Context) {
+	s.lock(ctx)
+	s.items = make(map[string]item)
+	for _, idx := range s.indexes {
+		idx.keys = make(map[string]map[string]struct{})
+	}
+	s.mu.Unlock()
+}
+
+func (s *storage) Keys(ctx context.Context) ([]string, error) {
+	s.rlock(ctx)
+	defer s.mu.RUnlock()
 
 	keys := make([]string, 0, len(s.items))
+	n := 0
 	for k, v := range s.items {
+		if n%cancelCheck == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		n++
+
 		if !v.expired() {
 			keys = append(keys, k)
 		}
 	}
 
-	s.mu.RUnlock()
-	return keys
+	return keys, nil
 }
 
-func (s *storage) Hget(key, field string) (string, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
+// hashByKey returns hash by key, creates new one if create is true, must be called under lock
+func (s *storage) hashByKey(key string, create bool) (*hash, error) {
 	i, ok := s.items[key]
+	if !ok || i.expired() {
+		if !create {
+			return nil, ErrorNotFound
+		}
+
+		h := newHash()
+		s.setItem(key, item{value: h})
+		return h, nil
+	}
+
+	h, ok := i.value.(*hash)
 	if !ok {
-		return "", nil
+		return nil, ErrorWrongType
 	}
 
-	if i.expired() {
-		return "", nil
+	return h, nil
+}
+
+func (s *storage) Hget(ctx context.Context, key, field string) (string, error) {
+	s.rlock(ctx)
+	defer s.mu.RUnlock()
+
+	h, err := s.hashByKey(key, false)
+	if err != nil {
+		return "", err
 	}
 
-	hmap, ok := i.value.(map[string]string)
+	val, ok := h.get(field)
 	if !ok {
-		return "", ErrorWrongType
+		return "", ErrorNotFound
 	}
 
-	return hmap[field], nil
+	return val, nil
 }
 
-func (s *storage) Hset(key, field, val string) error {
-	s.mu.Lock()
+func (s *storage) Hset(ctx context.Context, key, field, val string) error {
+	s.lock(ctx)
 	defer s.mu.Unlock()
 
-	i, ok := s.items[key]
-	if !ok {
-		s.items[key] = item{
-			value: map[string]string{
-				field: val,
-			},
-		}
-		return nil
+	h, err := s.hashByKey(key, true)
+	if err != nil {
+		return err
 	}
 
-	hmap, ok := i.value.(map[string]string)
-	if !ok {
-		return ErrorWrongType
+	s.indexHash(key, h, false)
+	h.set(field, val)
+	s.indexHash(key, h, true)
+	return nil
+}
+
+func (s *storage) Hdel(ctx context.Context, key, field string) error {
+	s.lock(ctx)
+	defer s.mu.Unlock()
+
+	h, err := s.hashByKey(key, false)
+	if err == ErrorNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
 	}
 
-	hmap[field] = val
+	s.indexHash(key, h, false)
+	h.del(field)
+	s.indexHash(key, h, true)
 	return nil
 }
 
-func (s *storage) Hdel(key, field string) error {
-	s.mu.Lock()
+func (s *storage) Hexpire(ctx context.Context, key, field string, ttl time.Duration) (bool, error) {
+	exp := getExpiration(ttl)
+
+	s.lock(ctx)
 	defer s.mu.Unlock()
 
-	i, ok := s.items[key]
-	if !ok {
-		return nil
+	h, err := s.hashByKey(key, false)
+	if err == ErrorNotFound {
+		return false, nil
 	}
+	if err != nil {
+		return false, err
+	}
+
+	return h.expire(field, exp), nil
+}
 
-	hmap, ok := i.value.(map[string]string)
+func (s *storage) Httl(ctx context.Context, key, field string) (time.Duration, error) {
+	s.rlock(ctx)
+	defer s.mu.RUnlock()
+
+	h, err := s.hashByKey(key, false)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, ok := h.get(field); !ok {
+		return 0, ErrorNotFound
+	}
+
+	exp, ok := h.expirations[field]
 	if !ok {
-		return ErrorWrongType
+		return NoTtl, nil
 	}
 
-	delete(hmap, field)
-	return nil
+	ttl := time.Duration(exp - time.Now().UnixNano())
+	if ttl < 0 {
+		ttl = 0
+	}
+	return ttl, nil
+}
+
+func (s *storage) Hpersist(ctx context.Context, key, field string) (bool, error) {
+	s.lock(ctx)
+	defer s.mu.Unlock()
+
+	h, err := s.hashByKey(key, false)
+	if err == ErrorNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return h.expire(field, 0), nil
+}
+
+func (s *storage) Stats(ctx context.Context) Stats {
+	var st Stats
+
+	s.rlock(ctx)
+	for _, i := range s.items {
+		if i.expired() {
+			continue
+		}
+
+		st.Keys++
+		if c, ok := i.value.(compressed); ok {
+			st.Compressed++
+			st.RawSize += int64(c.size)
+			st.CompressedSize += int64(len(c.data))
+		}
+	}
+	s.mu.RUnlock()
+
+	return st
 }
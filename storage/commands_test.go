@@ -1,29 +1,41 @@
 package storage_test
 
 import (
+	"bytes"
+	"context"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/alexxeis/keyval/storage"
 )
 
+// ctx is a context of commands in tests
+var ctx = context.Background()
+
+// allKeys returns keys of storage, test fails on error
+func allKeys(t *testing.T, s storage.Storage) []string {
+	keys, err := s.Keys(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
+
 func TestStorage_Get(t *testing.T) {
 	s := storage.NewStorage(0)
 
 	// test missing key
-	v, err := s.Get("missing")
-	if err != nil {
+	_, err := s.Get(ctx, "missing")
+	if err != storage.ErrorNotFound {
 		t.Error(err)
 	}
-	if v != "" {
-		t.Error("not empty value")
-	}
 
 	// test wrong type
-	if err = s.Hset("hset", "f", "v"); err != nil {
+	if err = s.Hset(ctx, "hset", "f", "v"); err != nil {
 		t.Error(err)
 	}
-	if _, err = s.Get("hset"); err != storage.ErrorWrongType {
+	if _, err = s.Get(ctx, "hset"); err != storage.ErrorWrongType {
 		t.Error(err)
 	}
 }
@@ -33,8 +45,8 @@ func TestStorage_Set(t *testing.T) {
 	key := "k"
 
 	// test write
-	s.Set(key, "v1", 0)
-	v, err := s.Get(key)
+	s.Set(ctx, key, "v1", 0)
+	v, err := s.Get(ctx, key)
 	if err != nil {
 		t.Error(err)
 	}
@@ -43,8 +55,8 @@ func TestStorage_Set(t *testing.T) {
 	}
 
 	// test rewrite
-	s.Set(key, "v2", 0)
-	v, err = s.Get(key)
+	s.Set(ctx, key, "v2", 0)
+	v, err = s.Get(ctx, key)
 	if err != nil {
 		t.Error(err)
 	}
@@ -52,60 +64,113 @@ func TestStorage_Set(t *testing.T) {
 		t.Error("expected value = v2, got ", v)
 	}
 
-	// test expire
-	s.Set(key, "expired", time.Nanosecond)
-	time.Sleep(time.Nanosecond)
-	v, err = s.Get(key)
+	// test empty value
+	s.Set(ctx, key, "", 0)
+	v, err = s.Get(ctx, key)
 	if err != nil {
 		t.Error(err)
 	}
 	if v != "" {
 		t.Error("not empty value")
 	}
+
+	// test expire
+	s.Set(ctx, key, "expired", time.Nanosecond)
+	time.Sleep(time.Nanosecond)
+	if _, err = s.Get(ctx, key); err != storage.ErrorNotFound {
+		t.Error(err)
+	}
 }
 
-func TestStorage_Expire(t *testing.T) {
+func TestStorage_SetRaw(t *testing.T) {
 	s := storage.NewStorage(0)
 	key := "k"
+	data := []byte{0, 255, 'v'}
 
-	s.Set(key, "v", 0)
-	s.Expire(key, time.Nanosecond)
-	time.Sleep(time.Nanosecond)
+	// test write
+	s.SetRaw(ctx, key, data, "application/octet-stream", 0)
+	val, ct, err := s.GetRaw(ctx, key)
+	if err != nil {
+		t.Error(err)
+	}
+	if !bytes.Equal(val, data) {
+		t.Errorf("expected value = %v, got %v", data, val)
+	}
+	if ct != "application/octet-stream" {
+		t.Error("expected content type = application/octet-stream, got ", ct)
+	}
 
-	val, err := s.Get(key)
+	// test read as string
+	v, err := s.Get(ctx, key)
 	if err != nil {
 		t.Error(err)
 	}
-	if val != "" {
-		t.Error("not empty value")
+	if v != string(data) {
+		t.Errorf("expected value = %q, got %q", data, v)
+	}
+
+	// test string read as raw
+	s.Set(ctx, key, "v", 0)
+	val, ct, err = s.GetRaw(ctx, key)
+	if err != nil {
+		t.Error(err)
+	}
+	if string(val) != "v" || ct != "" {
+		t.Errorf("expected value = v, got %q (%s)", val, ct)
+	}
+
+	// test expire
+	s.SetRaw(ctx, key, data, "", time.Nanosecond)
+	time.Sleep(time.Nanosecond)
+	if _, _, err = s.GetRaw(ctx, key); err != storage.ErrorNotFound {
+		t.Error(err)
+	}
+
+	// test wrong type
+	if err = s.Hset(ctx, "hset", "f", "v"); err != nil {
+		t.Error(err)
+	}
+	if _, _, err = s.GetRaw(ctx, "hset"); err != storage.ErrorWrongType {
+		t.Error(err)
 	}
 }
 
-func TestStorage_Remove(t *testing.T) {
+func TestStorage_Expire(t *testing.T) {
 	s := storage.NewStorage(0)
 	key := "k"
 
-	s.Set(key, "v", 0)
-	s.Remove(key)
+	s.Set(ctx, key, "v", 0)
+	s.Expire(ctx, key, time.Nanosecond)
+	time.Sleep(time.Nanosecond)
 
-	v, err := s.Get(key)
-	if err != nil {
+	_, err := s.Get(ctx, key)
+	if err != storage.ErrorNotFound {
 		t.Error(err)
 	}
-	if v != "" {
-		t.Error("not empty value")
+}
+
+func TestStorage_Remove(t *testing.T) {
+	s := storage.NewStorage(0)
+	key := "k"
+
+	s.Set(ctx, key, "v", 0)
+	s.Remove(ctx, key)
+
+	_, err := s.Get(ctx, key)
+	if err != storage.ErrorNotFound {
+		t.Error(err)
 	}
 }
 
 func TestStorage_Keys(t *testing.T) {
 	s := storage.NewStorage(0)
 
-	s.Set("k1", "v", 0)
-	s.Set("k2", "v", time.Nanosecond)
-	s.Set("k3", "v", 0)
+	s.Set(ctx, "k1", "v", 0)
+	s.Set(ctx, "k2", "v", time.Nanosecond)
+	s.Set(ctx, "k3", "v", 0)
 	time.Sleep(time.Nanosecond)
 
-	keys := s.Keys()
+	keys := allKeys(t, s)
 	l := len(keys)
 	if l != 2 {
 		t.Errorf("expected len is %d, got %d", 2, l)
@@ -119,33 +184,39 @@ func TestStorage_Keys(t *testing.T) {
 	}
 }
 
+func TestStorage_KeysCanceled(t *testing.T) {
+	s := storage.NewStorage(0)
+	for i := 0; i < 2048; i++ {
+		s.Set(ctx, strconv.Itoa(i), "v", 0)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := s.Keys(canceled); err != context.Canceled {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+}
+
 func TestStorage_Hget(t *testing.T) {
 	s := storage.NewStorage(0)
 
 	// test missing key
-	v, err := s.Hget("missing", "f")
-	if err != nil {
+	_, err := s.Hget(ctx, "missing", "f")
+	if err != storage.ErrorNotFound {
 		t.Error(err)
 	}
-	if v != "" {
-		t.Error("not empty value")
-	}
 
 	// test missing field
-	if err = s.Hset("hset", "f", "v"); err != nil {
+	if err = s.Hset(ctx, "hset", "f", "v"); err != nil {
 		t.Error(err)
 	}
-	v, err = s.Hget("hset", "missing")
-	if err != nil {
+	if _, err = s.Hget(ctx, "hset", "missing"); err != storage.ErrorNotFound {
 		t.Error(err)
 	}
-	if v != "" {
-		t.Error("not empty value")
-	}
 
 	// test wrong type
-	s.Set("string", "v", 0)
-	if _, err = s.Hget("string", "f"); err != storage.ErrorWrongType {
+	s.Set(ctx, "string", "v", 0)
+	if _, err = s.Hget(ctx, "string", "f"); err != storage.ErrorWrongType {
 		t.Error(err)
 	}
 }
@@ -162,14 +233,14 @@ func TestStorage_Hset(t *testing.T) {
 	var err error
 
 	// test write
-	if err = s.Hset(key, field1, val1); err != nil {
+	if err = s.Hset(ctx, key, field1, val1); err != nil {
 		t.Error(err)
 	}
-	if err = s.Hset(key, field2, val2); err != nil {
+	if err = s.Hset(ctx, key, field2, val2); err != nil {
 		t.Error(err)
 	}
 
-	v, err := s.Hget(key, field1)
+	v, err := s.Hget(ctx, key, field1)
 	if err != nil {
 		t.Error(err)
 	}
@@ -177,7 +248,7 @@ func TestStorage_Hset(t *testing.T) {
 		t.Errorf("expected value = %s, got %s", val1, v)
 	}
 
-	v, err = s.Hget(key, field2)
+	v, err = s.Hget(ctx, key, field2)
 	if err != nil {
 		t.Error(err)
 	}
@@ -186,11 +257,11 @@ func TestStorage_Hset(t *testing.T) {
 	}
 
 	// test rewrite
-	if err = s.Hset(key, field1, val3); err != nil {
+	if err = s.Hset(ctx, key, field1, val3); err != nil {
 		t.Error(err)
 	}
 
-	v, err = s.Hget(key, field1)
+	v, err = s.Hget(ctx, key, field1)
 	if err != nil {
 		t.Error(err)
 	}
@@ -204,29 +275,167 @@ func TestStorage_Hdel(t *testing.T) {
 	var err error
 
 	// test missing key
-	if err = s.Hdel("missing", "missing"); err != nil {
+	if err = s.Hdel(ctx, "missing", "missing"); err != nil {
 		t.Error(err)
 	}
 
 	// test remove
-	if err = s.Hset("removed", "removed", "v"); err != nil {
+	if err = s.Hset(ctx, "removed", "removed", "v"); err != nil {
 		t.Error(err)
 	}
-	if err = s.Hdel("removed", "removed"); err != nil {
+	if err = s.Hdel(ctx, "removed", "removed"); err != nil {
 		t.Error(err)
 	}
 
-	v, err := s.Hget("removed", "removed")
+	if _, err = s.Hget(ctx, "removed", "removed"); err != storage.ErrorNotFound {
+		t.Error(err)
+	}
+
+	// test wrong type
+	s.Set(ctx, "string", "v", 0)
+	if err = s.Hdel(ctx, "string", "missing"); err != storage.ErrorWrongType {
+		t.Error(err)
+	}
+}
+
+func TestStorage_Hexpire(t *testing.T) {
+	s := storage.NewStorage(0)
+
+	// test missing key
+	ok, err := s.Hexpire(ctx, "missing", "f", time.Second)
 	if err != nil {
 		t.Error(err)
 	}
-	if v != "" {
-		t.Error("not empty value")
+	if ok {
+		t.Error("expired missing key")
+	}
+
+	if err = s.Hset(ctx, "k", "f1", "v"); err != nil {
+		t.Error(err)
+	}
+	if err = s.Hset(ctx, "k", "f2", "v"); err != nil {
+		t.Error(err)
+	}
+
+	// test missing field
+	if ok, err = s.Hexpire(ctx, "k", "missing", time.Second); err != nil || ok {
+		t.Error("expired missing field ", err)
+	}
+
+	// test expire
+	if ok, err = s.Hexpire(ctx, "k", "f1", time.Nanosecond); err != nil || !ok {
+		t.Error("field is not expired ", err)
+	}
+	time.Sleep(time.Nanosecond)
+	if _, err = s.Hget(ctx, "k", "f1"); err != storage.ErrorNotFound {
+		t.Error(err)
+	}
+	if _, err = s.Hget(ctx, "k", "f2"); err != nil {
+		t.Error(err)
+	}
+
+	// test rewrite clears expiration
+	if _, err = s.Hexpire(ctx, "k", "f2", time.Nanosecond); err != nil {
+		t.Error(err)
+	}
+	if err = s.Hset(ctx, "k", "f2", "v2"); err != nil {
+		t.Error(err)
+	}
+	time.Sleep(time.Nanosecond)
+	if _, err = s.Hget(ctx, "k", "f2"); err != nil {
+		t.Error(err)
 	}
 
 	// test wrong type
-	s.Set("string", "v", 0)
-	if err = s.Hdel("string", "missing"); err != storage.ErrorWrongType {
+	s.Set(ctx, "string", "v", 0)
+	if _, err = s.Hexpire(ctx, "string", "f", time.Second); err != storage.ErrorWrongType {
 		t.Error(err)
 	}
 }
+
+func TestStorage_HexpireCleaner(t *testing.T) {
+	s := storage.NewStorage(time.Millisecond)
+	defer s.Shutdown()
+
+	if err := s.Hset(ctx, "k", "f", "v"); err != nil {
+		t.Error(err)
+	}
+	if _, err := s.Hexpire(ctx, "k", "f", time.Nanosecond); err != nil {
+		t.Error(err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if len(allKeys(t, s)) != 0 {
+		t.Error("hash without fields is not removed")
+	}
+}
+
+func TestStorage_SetCleanInterval(t *testing.T) {
+	s := storage.NewStorage(0)
+	defer s.Shutdown()
+
+	if err := s.Hset(ctx, "k", "f", "v"); err != nil {
+		t.Error(err)
+	}
+	if _, err := s.Hexpire(ctx, "k", "f", time.Nanosecond); err != nil {
+		t.Error(err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if len(allKeys(t, s)) != 1 {
+		t.Error("hash is removed with disabled cleaner")
+	}
+
+	s.SetCleanInterval(time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	if len(allKeys(t, s)) != 0 {
+		t.Error("hash without fields is not removed")
+	}
+}
+
+func TestStorage_Httl(t *testing.T) {
+	s := storage.NewStorage(0)
+
+	if _, err := s.Httl(ctx, "missing", "f"); err != storage.ErrorNotFound {
+		t.Error(err)
+	}
+
+	if err := s.Hset(ctx, "k", "f", "v"); err != nil {
+		t.Error(err)
+	}
+	ttl, err := s.Httl(ctx, "k", "f")
+	if err != nil {
+		t.Error(err)
+	}
+	if ttl != storage.NoTtl {
+		t.Error("expected no ttl, got ", ttl)
+	}
+
+	if _, err = s.Hexpire(ctx, "k", "f", time.Minute); err != nil {
+		t.Error(err)
+	}
+	ttl, err = s.Httl(ctx, "k", "f")
+	if err != nil {
+		t.Error(err)
+	}
+	if ttl <= 0 || ttl > time.Minute {
+		t.Error("wrong ttl ", ttl)
+	}
+
+	// test persist
+	ok, err := s.Hpersist(ctx, "k", "f")
+	if err != nil || !ok {
+		t.Error("field is not persisted ", err)
+	}
+	ttl, err = s.Httl(ctx, "k", "f")
+	if err != nil {
+		t.Error(err)
+	}
+	if ttl != storage.NoTtl {
+		t.Error("expected no ttl, got ", ttl)
+	}
+
+	if ok, err = s.Hpersist(ctx, "k", "missing"); err != nil || ok {
+		t.Error("persisted missing field ", err)
+	}
+}
@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"sync"
+)
+
+// Codec is the interface for value compression
+type Codec interface {
+	// Compress returns compressed data
+	Compress(data []byte) ([]byte, error)
+
+	// Decompress returns data decompressed from the compressed one
+	Decompress(data []byte) ([]byte, error)
+}
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[string]Codec{
+		"gzip": GzipCodec{Level: gzip.DefaultCompression},
+	}
+)
+
+// RegisterCodec makes codec available by the name
+func RegisterCodec(name string, c Codec) {
+	if c == nil {
+		panic("nil codec")
+	}
+
+	codecsMu.Lock()
+	codecs[name] = c
+	codecsMu.Unlock()
+}
+
+// CodecByName returns registered codec by the name
+func CodecByName(name string) (Codec, bool) {
+	codecsMu.RLock()
+	c, ok := codecs[name]
+	codecsMu.RUnlock()
+	return c, ok
+}
+
+// GzipCodec is a Codec using gzip compression
+type GzipCodec struct {
+	Level int
+}
+
+func (c GzipCodec) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, c.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = w.Write(data); err != nil {
+		return nil, err
+	}
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (c GzipCodec) Decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return ioutil.ReadAll(r)
+}
+
+// compressed is a value compressed by codec
+type compressed struct {
+	data        []byte
+	size        int
+	contentType string
+	codec       Codec
+}
+
+// decompress returns original value data
+func (c compressed) decompress() ([]byte, error) {
+	return c.codec.Decompress(c.data)
+}
+
+// compressible returns true if value of the given size should be compressed
+func (s *storage) compressible(size int) bool {
+	return s.codec != nil && size >= s.compressThreshold
+}
+
+// compress returns compressed value or false if compression isn't worth it
+func (s *storage) compress(data []byte, contentType string) (compressed, bool) {
+	c, err := s.codec.Compress(data)
+	if err != nil || len(c) >= len(data) {
+		return compressed{}, false
+	}
+
+	return compressed{
+		data:        c,
+		size:        len(data),
+		contentType: contentType,
+		codec:       s.codec,
+	}, true
+}
@@ -0,0 +1,59 @@
+package storage_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/alexxeis/keyval/storage"
+)
+
+func TestStorage_Compression(t *testing.T) {
+	codec, ok := storage.CodecByName("gzip")
+	if !ok {
+		t.Fatal("missing gzip codec")
+	}
+	s := storage.NewStorage(0, storage.WithCompression(codec, 100))
+
+	large := strings.Repeat("value", 100)
+	s.Set(ctx, "large", large, 0)
+	s.Set(ctx, "small", "v", 0)
+	s.SetRaw(ctx, "raw", []byte(large), "text/plain", 0)
+
+	// test decompress
+	v, err := s.Get(ctx, "large")
+	if err != nil {
+		t.Error(err)
+	}
+	if v != large {
+		t.Error("wrong decompressed value")
+	}
+
+	data, ct, err := s.GetRaw(ctx, "raw")
+	if err != nil {
+		t.Error(err)
+	}
+	if !bytes.Equal(data, []byte(large)) || ct != "text/plain" {
+		t.Errorf("wrong decompressed raw value (%s)", ct)
+	}
+
+	// test stats
+	st := s.Stats(ctx)
+	if st.Keys != 3 {
+		t.Errorf("expected keys = %d, got %d", 3, st.Keys)
+	}
+	if st.Compressed != 2 {
+		t.Errorf("expected compressed = %d, got %d", 2, st.Compressed)
+	}
+	if st.RawSize != int64(2*len(large)) {
+		t.Errorf("expected raw size = %d, got %d", 2*len(large), st.RawSize)
+	}
+	if st.CompressedSize <= 0 || st.CompressedSize >= st.RawSize {
+		t.Error("wrong compressed size ", st.CompressedSize)
+	}
+
+	// test wrong type
+	if err = s.Hset(ctx, "large", "f", "v"); err != storage.ErrorWrongType {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"context"
+	"math"
+	"sort"
+)
+
+// geoScore returns sorted set score of coordinates
+func geoScore(lon, lat float64) float64 {
+	return float64(geohashEncode(lon, lat, geoStep))
+}
+
+// geoPosition returns coordinates by sorted set score
+func geoPosition(score float64) (float64, float64) {
+	return geohashDecode(uint64(score), geoStep)
+}
+
+func (s *storage) Geoadd(ctx context.Context, key string, locations ...GeoLocation) (int, error) {
+	for _, l := range locations {
+		if !validCoordinates(l.Longitude, l.Latitude) {
+			return 0, ErrorCoordinates
+		}
+	}
+
+	s.lock(ctx)
+	defer s.mu.Unlock()
+
+	z, err := s.zsetByKey(key, true)
+	if err != nil {
+		return 0, err
+	}
+
+	added := 0
+	for _, l := range locations {
+		if z.add(l.Member, geoScore(l.Longitude, l.Latitude)) {
+			added++
+		}
+	}
+
+	if z.len() == 0 {
+		delete(s.items, key)
+	}
+	return added, nil
+}
+
+func (s *storage) Geopos(ctx context.Context, key string, members ...string) ([]*GeoLocation, error) {
+	s.rlock(ctx)
+	defer s.mu.RUnlock()
+
+	res := make([]*GeoLocation, len(members))
+
+	z, err := s.zsetByKey(key, false)
+	if err == ErrorNotFound {
+		return res, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i, m := range members {
+		if score, ok := z.dict[m]; ok {
+			lon, lat := geoPosition(score)
+			res[i] = &GeoLocation{Member: m, Longitude: lon, Latitude: lat}
+		}
+	}
+	return res, nil
+}
+
+func (s *storage) Geodist(ctx context.Context, key, member1, member2 string) (float64, error) {
+	s.rlock(ctx)
+	defer s.mu.RUnlock()
+
+	z, err := s.zsetByKey(key, false)
+	if err != nil {
+		return 0, err
+	}
+
+	score1, ok1 := z.dict[member1]
+	score2, ok2 := z.dict[member2]
+	if !ok1 || !ok2 {
+		return 0, ErrorNotFound
+	}
+
+	lon1, lat1 := geoPosition(score1)
+	lon2, lat2 := geoPosition(score2)
+	return geoDistance(lon1, lat1, lon2, lat2), nil
+}
+
+func (s *storage) Geosearch(ctx context.Context, key string, q GeoQuery) ([]GeoResult, error) {
+	radius := q.Radius
+	if radius <= 0 {
+		if q.Width <= 0 || q.Height <= 0 {
+			return nil, ErrorGeoQuery
+		}
+		radius = math.Sqrt(q.Width*q.Width+q.Height*q.Height) / 2
+	}
+
+	if q.Member == "" && !validCoordinates(q.Longitude, q.Latitude) {
+		return nil, ErrorCoordinates
+	}
+
+	s.rlock(ctx)
+	defer s.mu.RUnlock()
+
+	z, err := s.zsetByKey(key, false)
+	if err == ErrorNotFound && q.Member == "" {
+		return []GeoResult{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if q.Member != "" {
+		score, ok := z.dict[q.Member]
+		if !ok {
+			return nil, ErrorNotFound
+		}
+		q.Longitude, q.Latitude = geoPosition(score)
+	}
+
+	cells, step := geohashArea(q.Longitude, q.Latitude, radius)
+	shift := 2 * (geoStep - step)
+
+	res := []GeoResult{}
+	for _, h := range cells {
+		min := float64(h << shift)
+		max := float64((h+1)<<shift - 1)
+		for _, m := range z.rangeByScore(min, max) {
+			lon, lat := geoPosition(m.Score)
+			if d, ok := geoMatch(q, lon, lat); ok {
+				res = append(res, GeoResult{
+					GeoLocation: GeoLocation{Member: m.Member, Longitude: lon, Latitude: lat},
+					Distance:    d,
+				})
+			}
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Distance < res[j].Distance
+	})
+	if q.Count > 0 && len(res) > q.Count {
+		res = res[:q.Count]
+	}
+	return res, nil
+}
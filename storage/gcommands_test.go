@@ -0,0 +1,244 @@
+package storage_test
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/alexxeis/keyval/storage"
+)
+
+// sicily returns storage with geo set of Sicily cities
+func sicily(t *testing.T) storage.Storage {
+	s := storage.NewStorage(0)
+	n, err := s.Geoadd(ctx, "sicily",
+		storage.GeoLocation{Member: "Palermo", Longitude: 13.361389, Latitude: 38.115556},
+		storage.GeoLocation{Member: "Catania", Longitude: 15.087269, Latitude: 37.502669},
+	)
+	if err != nil {
+		t.Error(err)
+	}
+	if n != 2 {
+		t.Errorf("expected added = %d, got %d", 2, n)
+	}
+	return s
+}
+
+func TestStorage_Geoadd(t *testing.T) {
+	s := sicily(t)
+
+	// test update
+	n, err := s.Geoadd(ctx, "sicily", storage.GeoLocation{Member: "Palermo", Longitude: 13.36, Latitude: 38.11})
+	if err != nil {
+		t.Error(err)
+	}
+	if n != 0 {
+		t.Errorf("expected added = %d, got %d", 0, n)
+	}
+
+	// test geo set is a sorted set
+	members, err := s.Zrange(ctx, "sicily", 0, -1)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(members) != 2 {
+		t.Error("wrong members ", members)
+	}
+
+	// test wrong coordinates
+	if _, err = s.Geoadd(ctx, "sicily", storage.GeoLocation{Member: "Pole", Longitude: 0, Latitude: 90}); err != storage.ErrorCoordinates {
+		t.Error(err)
+	}
+
+	// test wrong type
+	s.Set(ctx, "string", "v", 0)
+	if _, err = s.Geoadd(ctx, "string", storage.GeoLocation{Member: "a"}); err != storage.ErrorWrongType {
+		t.Error(err)
+	}
+}
+
+func TestStorage_Geopos(t *testing.T) {
+	s := sicily(t)
+
+	locations, err := s.Geopos(ctx, "sicily", "Palermo", "missing")
+	if err != nil {
+		t.Error(err)
+	}
+	if len(locations) != 2 || locations[0] == nil || locations[1] != nil {
+		t.Fatal("wrong locations ", locations)
+	}
+	if math.Abs(locations[0].Longitude-13.361389) > 1e-5 || math.Abs(locations[0].Latitude-38.115556) > 1e-5 {
+		t.Error("wrong location ", *locations[0])
+	}
+
+	// test missing key
+	locations, err = s.Geopos(ctx, "missing", "Palermo")
+	if err != nil {
+		t.Error(err)
+	}
+	if len(locations) != 1 || locations[0] != nil {
+		t.Error("wrong locations ", locations)
+	}
+}
+
+func TestStorage_Geodist(t *testing.T) {
+	s := sicily(t)
+
+	d, err := s.Geodist(ctx, "sicily", "Palermo", "Catania")
+	if err != nil {
+		t.Error(err)
+	}
+	if math.Abs(d-166274.15) > 1 {
+		t.Errorf("expected distance = %f, got %f", 166274.15, d)
+	}
+
+	if _, err = s.Geodist(ctx, "sicily", "Palermo", "missing"); err != storage.ErrorNotFound {
+		t.Error(err)
+	}
+	if _, err = s.Geodist(ctx, "missing", "Palermo", "Catania"); err != storage.ErrorNotFound {
+		t.Error(err)
+	}
+}
+
+func TestStorage_Geosearch(t *testing.T) {
+	s := sicily(t)
+
+	for _, c := range []struct {
+		q       storage.GeoQuery
+		members []string
+	}{
+		{storage.GeoQuery{Longitude: 15, Latitude: 37, Radius: 100000}, []string{"Catania"}},
+		{storage.GeoQuery{Longitude: 15, Latitude: 37, Radius: 200000}, []string{"Catania", "Palermo"}},
+		{storage.GeoQuery{Longitude: 15, Latitude: 37, Radius: 200000, Count: 1}, []string{"Catania"}},
+		{storage.GeoQuery{Longitude: 15, Latitude: 37, Width: 400000, Height: 400000}, []string{"Catania", "Palermo"}},
+		{storage.GeoQuery{Longitude: 15, Latitude: 37, Width: 200000, Height: 400000}, []string{"Catania"}},
+		{storage.GeoQuery{Member: "Palermo", Radius: 1000}, []string{"Palermo"}},
+		{storage.GeoQuery{Longitude: 0, Latitude: 0, Radius: 1000000}, []string{}},
+	} {
+		results, err := s.Geosearch(ctx, "sicily", c.q)
+		if err != nil {
+			t.Error(err)
+		}
+		if len(results) != len(c.members) {
+			t.Errorf("expected %v for %+v, got %v", c.members, c.q, results)
+			continue
+		}
+		for i, r := range results {
+			if r.Member != c.members[i] {
+				t.Errorf("expected %v for %+v, got %v", c.members, c.q, results)
+			}
+		}
+	}
+
+	results, err := s.Geosearch(ctx, "sicily", storage.GeoQuery{Longitude: 15, Latitude: 37, Radius: 200000})
+	if err != nil {
+		t.Error(err)
+	}
+	if len(results) != 2 || math.Abs(results[0].Distance-56441.26) > 1 || math.Abs(results[1].Distance-190442.42) > 1 {
+		t.Error("wrong distances ", results)
+	}
+
+	// test search across antimeridian
+	if _, err = s.Geoadd(ctx, "edge", storage.GeoLocation{Member: "east", Longitude: 179.99, Latitude: 0}); err != nil {
+		t.Error(err)
+	}
+	results, err = s.Geosearch(ctx, "edge", storage.GeoQuery{Longitude: -179.99, Latitude: 0, Radius: 5000})
+	if err != nil {
+		t.Error(err)
+	}
+	if len(results) != 1 {
+		t.Error("wrong results ", results)
+	}
+
+	// test wrong query
+	if _, err = s.Geosearch(ctx, "sicily", storage.GeoQuery{Longitude: 15, Latitude: 37}); err != storage.ErrorGeoQuery {
+		t.Error(err)
+	}
+	if _, err = s.Geosearch(ctx, "sicily", storage.GeoQuery{Member: "missing", Radius: 1000}); err != storage.ErrorNotFound {
+		t.Error(err)
+	}
+}
+
+// distance returns haversine distance in meters with the storage earth radius
+func distance(lon1, lat1, lon2, lat2 float64) float64 {
+	lat1r, lat2r := lat1*math.Pi/180, lat2*math.Pi/180
+	u := math.Sin((lat2r - lat1r) / 2)
+	v := math.Sin((lon2 - lon1) * math.Pi / 180 / 2)
+	return 2 * 6372797.560856 * math.Asin(math.Sqrt(u*u+math.Cos(lat1r)*math.Cos(lat2r)*v*v))
+}
+
+func TestStorage_GeosearchBruteForce(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	type search struct {
+		lon, lat, radius float64
+	}
+	searches := []search{
+		{-16.7914, 65.2409, 300000},
+		{-101.511, 65.5827, 300000},
+	}
+	for i := 0; i < 300; i++ {
+		searches = append(searches, search{rnd.Float64()*360 - 180, rnd.Float64()*170 - 85, 1000 + rnd.Float64()*1000000})
+	}
+
+	for _, c := range searches {
+		s := storage.NewStorage(0)
+
+		// locations are placed around the center up to 1.2 radius, mostly near the circle
+		var members []string
+		for i := 0; i < 100; i++ {
+			d := c.radius * (0.9 + rnd.Float64()*0.3)
+			lon, lat := destination(c.lon, c.lat, rnd.Float64()*2*math.Pi, d)
+			if lat < -85 || lat > 85 {
+				continue
+			}
+			member := strconv.Itoa(i)
+			if _, err := s.Geoadd(ctx, "g", storage.GeoLocation{Member: member, Longitude: lon, Latitude: lat}); err != nil {
+				t.Fatal(err)
+			}
+			members = append(members, member)
+		}
+		if len(members) == 0 {
+			continue
+		}
+
+		// expected members are found by stored positions
+		positions, err := s.Geopos(ctx, "g", members...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var expected []string
+		for i, p := range positions {
+			if distance(c.lon, c.lat, p.Longitude, p.Latitude) <= c.radius {
+				expected = append(expected, members[i])
+			}
+		}
+
+		results, err := s.Geosearch(ctx, "g", storage.GeoQuery{Longitude: c.lon, Latitude: c.lat, Radius: c.radius})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var found []string
+		for _, r := range results {
+			found = append(found, r.Member)
+		}
+
+		sort.Strings(expected)
+		sort.Strings(found)
+		if !reflect.DeepEqual(found, expected) {
+			t.Errorf("search at %v, %v with radius %v: expected %v, got %v", c.lon, c.lat, c.radius, expected, found)
+		}
+	}
+}
+
+// destination returns coordinates of point at distance in meters from the start by bearing in radians
+func destination(lon, lat, bearing, d float64) (float64, float64) {
+	a := d / 6372797.560856
+	lat1, lon1 := lat*math.Pi/180, lon*math.Pi/180
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(a) + math.Cos(lat1)*math.Sin(a)*math.Cos(bearing))
+	lon2 := lon1 + math.Atan2(math.Sin(bearing)*math.Sin(a)*math.Cos(lat1), math.Cos(a)-math.Sin(lat1)*math.Sin(lat2))
+	lon2 = math.Mod(lon2*180/math.Pi+540, 360) - 180
+	return lon2, lat2 * 180 / math.Pi
+}
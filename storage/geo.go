@@ -0,0 +1,227 @@
+package storage
+
+import "math"
+
+const (
+	geoLatMin = -85.05112878
+	geoLatMax = 85.05112878
+	geoLonMin = -180
+	geoLonMax = 180
+
+	// geoStep is a precision of stored geohash, 52 bits fit into sorted set score exactly
+	geoStep = 26
+	// geoEarthRadius is the earth radius in meters used for distances
+	geoEarthRadius = 6372797.560856
+	// geoMercatorMax is a half of the earth circumference in meters
+	geoMercatorMax = 20037726.37
+)
+
+// GeoLocation is a member of geo set with its coordinates
+type GeoLocation struct {
+	Member    string
+	Longitude float64
+	Latitude  float64
+}
+
+// GeoResult is a geo search result with distance to the center in meters
+type GeoResult struct {
+	GeoLocation
+	Distance float64
+}
+
+// GeoQuery is a geo search area, radius or width and height in meters must be set.
+// Center is the member location if member is set
+type GeoQuery struct {
+	Member    string
+	Longitude float64
+	Latitude  float64
+	Radius    float64
+	Width     float64
+	Height    float64
+	// Count limits results count, zero count is unlimited
+	Count int
+}
+
+// validCoordinates returns true if coordinates can be encoded
+func validCoordinates(lon, lat float64) bool {
+	return lon >= geoLonMin && lon <= geoLonMax && lat >= geoLatMin && lat <= geoLatMax
+}
+
+// spread returns v with bits moved to even positions
+func spread(v uint64) uint64 {
+	var res uint64
+	for i := uint(0); i < 32; i++ {
+		res |= (v >> i & 1) << (2 * i)
+	}
+	return res
+}
+
+// squash returns even bits of v
+func squash(v uint64) uint64 {
+	var res uint64
+	for i := uint(0); i < 32; i++ {
+		res |= (v >> (2 * i) & 1) << i
+	}
+	return res
+}
+
+// geohashEncode returns geohash of coordinates with step bits per coordinate
+func geohashEncode(lon, lat float64, step uint) uint64 {
+	cells := float64(uint64(1) << step)
+	latBits := uint64((lat - geoLatMin) / (geoLatMax - geoLatMin) * cells)
+	lonBits := uint64((lon - geoLonMin) / (geoLonMax - geoLonMin) * cells)
+
+	// the max coordinate belongs to the last cell
+	max := uint64(1)<<step - 1
+	if latBits > max {
+		latBits = max
+	}
+	if lonBits > max {
+		lonBits = max
+	}
+
+	return spread(latBits) | spread(lonBits)<<1
+}
+
+// geohashDecode returns center coordinates of geohash cell
+func geohashDecode(hash uint64, step uint) (float64, float64) {
+	cells := float64(uint64(1) << step)
+	latBits := squash(hash)
+	lonBits := squash(hash >> 1)
+
+	lat := geoLatMin + (float64(latBits)+0.5)*(geoLatMax-geoLatMin)/cells
+	lon := geoLonMin + (float64(lonBits)+0.5)*(geoLonMax-geoLonMin)/cells
+	return lon, lat
+}
+
+// geoDistance returns distance in meters between two points
+func geoDistance(lon1, lat1, lon2, lat2 float64) float64 {
+	lat1r, lat2r := lat1*math.Pi/180, lat2*math.Pi/180
+	u := math.Sin((lat2r - lat1r) / 2)
+	v := math.Sin((lon2 - lon1) * math.Pi / 180 / 2)
+	return 2 * geoEarthRadius * math.Asin(math.Sqrt(u*u+math.Cos(lat1r)*math.Cos(lat2r)*v*v))
+}
+
+// geoStepByRadius returns geohash step with cells not smaller than radius
+func geoStepByRadius(radius, lat float64) uint {
+	if radius <= 0 {
+		return geoStep
+	}
+
+	step := 1
+	for r := radius; r < geoMercatorMax; r *= 2 {
+		step++
+	}
+	step -= 2
+
+	// cells are narrower near the poles
+	if lat > 66 || lat < -66 {
+		step--
+		if lat > 80 || lat < -80 {
+			step--
+		}
+	}
+
+	if step < 1 {
+		step = 1
+	}
+	if step > geoStep {
+		step = geoStep
+	}
+	return uint(step)
+}
+
+// geoBoundingBox returns longitude and latitude deltas of box around the circle with radius
+func geoBoundingBox(lat, radius float64) (float64, float64) {
+	latDelta := radius / geoEarthRadius * 180 / math.Pi
+	// circle is the widest at the latitude nearest to the pole
+	far := math.Abs(lat) + latDelta
+	if far >= 90 {
+		return 180, latDelta
+	}
+	lonDelta := radius / geoEarthRadius / math.Cos(far*math.Pi/180) * 180 / math.Pi
+	return math.Min(lonDelta, 180), latDelta
+}
+
+// geohashArea returns geohash cells covering circle with radius around the center.
+// Step is decreased until the center cell and its neighbours cover bounding box of the circle
+func geohashArea(lon, lat, radius float64) ([]uint64, uint) {
+	lonDelta, latDelta := geoBoundingBox(lat, radius)
+
+	step := geoStepByRadius(radius, lat)
+	for ; step > 1; step-- {
+		if geohashCovers(lon, lat, lonDelta, latDelta, step) {
+			break
+		}
+	}
+
+	cells := float64(uint64(1) << step)
+	lonSize := (geoLonMax - geoLonMin) / cells
+	latSize := (geoLatMax - geoLatMin) / cells
+
+	seen := make(map[uint64]struct{}, 9)
+	var res []uint64
+	for _, dlat := range []float64{-1, 0, 1} {
+		nlat := lat + dlat*latSize
+		if nlat < geoLatMin || nlat > geoLatMax {
+			continue
+		}
+
+		for _, dlon := range []float64{-1, 0, 1} {
+			nlon := lon + dlon*lonSize
+			if nlon < geoLonMin {
+				nlon += geoLonMax - geoLonMin
+			} else if nlon > geoLonMax {
+				nlon -= geoLonMax - geoLonMin
+			}
+
+			h := geohashEncode(nlon, nlat, step)
+			if _, ok := seen[h]; !ok {
+				seen[h] = struct{}{}
+				res = append(res, h)
+			}
+		}
+	}
+	return res, step
+}
+
+// geohashCovers returns true if the cell of the center with its neighbours covers the box with deltas
+func geohashCovers(lon, lat, lonDelta, latDelta float64, step uint) bool {
+	cells := float64(uint64(1) << step)
+	lonSize := (geoLonMax - geoLonMin) / cells
+	latSize := (geoLatMax - geoLatMin) / cells
+
+	// bounds of the center cell
+	minLon, minLat := geohashDecode(geohashEncode(lon, lat, step), step)
+	minLon -= lonSize / 2
+	minLat -= latSize / 2
+
+	// rows out of the encoded range have no locations
+	if minLat-latSize > geoLatMin && lat-latDelta < minLat-latSize {
+		return false
+	}
+	if minLat+2*latSize < geoLatMax && lat+latDelta > minLat+2*latSize {
+		return false
+	}
+	// columns wrap around the antimeridian
+	if 3*lonSize >= geoLonMax-geoLonMin {
+		return true
+	}
+	return lon-lonDelta >= minLon-lonSize && lon+lonDelta <= minLon+2*lonSize
+}
+
+// geoMatch returns distance from the center to the location and true if it's in the query area
+func geoMatch(q GeoQuery, lon, lat float64) (float64, bool) {
+	if q.Radius > 0 {
+		d := geoDistance(q.Longitude, q.Latitude, lon, lat)
+		return d, d <= q.Radius
+	}
+
+	if geoDistance(q.Longitude, q.Latitude, q.Longitude, lat) > q.Height/2 {
+		return 0, false
+	}
+	if geoDistance(q.Longitude, lat, lon, lat) > q.Width/2 {
+		return 0, false
+	}
+	return geoDistance(q.Longitude, q.Latitude, lon, lat), true
+}
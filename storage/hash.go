@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"time"
+)
+
+// hash is a map of fields with optional per-field expiration
+type hash struct {
+	fields      map[string]string
+	expirations map[string]int64
+}
+
+// newHash returns new empty hash
+func newHash() *hash {
+	return &hash{
+		fields: make(map[string]string),
+	}
+}
+
+// fieldExpired returns true if field expiration time is passed
+func (h *hash) fieldExpired(field string, now int64) bool {
+	exp, ok := h.expirations[field]
+	return ok && now > exp
+}
+
+// get returns not expired field value
+func (h *hash) get(field string) (string, bool) {
+	val, ok := h.fields[field]
+	if !ok || h.fieldExpired(field, time.Now().UnixNano()) {
+		return "", false
+	}
+	return val, true
+}
+
+// set sets field value and clears its expiration
+func (h *hash) set(field, val string) {
+	h.fields[field] = val
+	delete(h.expirations, field)
+}
+
+// del deletes field
+func (h *hash) del(field string) {
+	delete(h.fields, field)
+	delete(h.expirations, field)
+}
+
+// expire sets field expiration timestamp, 0 clears expiration, returns false if field is missing
+func (h *hash) expire(field string, exp int64) bool {
+	if _, ok := h.get(field); !ok {
+		return false
+	}
+
+	if exp == 0 {
+		delete(h.expirations, field)
+		return true
+	}
+
+	if h.expirations == nil {
+		h.expirations = make(map[string]int64)
+	}
+	h.expirations[field] = exp
+	return true
+}
+
+// deleteExpired deletes all expired fields
+func (h *hash) deleteExpired(now int64) {
+	for field := range h.expirations {
+		if h.fieldExpired(field, now) {
+			h.del(field)
+		}
+	}
+}
+
+// len returns fields count including expired ones
+func (h *hash) len() int {
+	return len(h.fields)
+}
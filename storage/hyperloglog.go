@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"hash/fnv"
+	"math"
+	"math/bits"
+	"sort"
+)
+
+const (
+	hllPrecision = 14
+	hllRegisters = 1 << hllPrecision
+	// hllSparseMax is a max count of sparse registers, dense representation is used after it
+	hllSparseMax = 3000
+)
+
+// HyperLogLog is a cardinality estimator.
+// It keeps sparse sorted list of non-zero registers while there are few of them and dense registers array after
+type HyperLogLog struct {
+	// sparse registers are encoded as index<<8 | value
+	sparse []uint32
+	dense  []uint8
+}
+
+// NewHyperLogLog returns new empty HyperLogLog
+func NewHyperLogLog() *HyperLogLog {
+	return &HyperLogLog{}
+}
+
+// hllHash returns 64-bit hash of element
+func hllHash(element string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(element))
+	x := h.Sum64()
+
+	// splitmix64 finalizer for better bits distribution
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// Add adds element, returns true if estimation could be changed
+func (h *HyperLogLog) Add(element string) bool {
+	x := hllHash(element)
+	idx := uint16(x & (hllRegisters - 1))
+	rho := uint8(bits.TrailingZeros64(x>>hllPrecision|1<<(64-hllPrecision))) + 1
+	return h.set(idx, rho)
+}
+
+// set sets register to value if it's greater than the current one
+func (h *HyperLogLog) set(idx uint16, val uint8) bool {
+	if h.dense != nil {
+		if h.dense[idx] >= val {
+			return false
+		}
+		h.dense[idx] = val
+		return true
+	}
+
+	i := sort.Search(len(h.sparse), func(i int) bool {
+		return uint16(h.sparse[i]>>8) >= idx
+	})
+	if i < len(h.sparse) && uint16(h.sparse[i]>>8) == idx {
+		if uint8(h.sparse[i]) >= val {
+			return false
+		}
+		h.sparse[i] = uint32(idx)<<8 | uint32(val)
+		return true
+	}
+
+	h.sparse = append(h.sparse, 0)
+	copy(h.sparse[i+1:], h.sparse[i:])
+	h.sparse[i] = uint32(idx)<<8 | uint32(val)
+
+	if len(h.sparse) > hllSparseMax {
+		h.toDense()
+	}
+	return true
+}
+
+// toDense converts sparse representation to dense one
+func (h *HyperLogLog) toDense() {
+	h.dense = make([]uint8, hllRegisters)
+	for _, r := range h.sparse {
+		h.dense[r>>8] = uint8(r)
+	}
+	h.sparse = nil
+}
+
+// Sparse returns true if sparse representation is used
+func (h *HyperLogLog) Sparse() bool {
+	return h.dense == nil
+}
+
+// Merge merges other HyperLogLog into h
+func (h *HyperLogLog) Merge(other *HyperLogLog) {
+	if other.dense == nil {
+		for _, r := range other.sparse {
+			h.set(uint16(r>>8), uint8(r))
+		}
+		return
+	}
+
+	if h.dense == nil {
+		h.toDense()
+	}
+	for i, v := range other.dense {
+		if v > h.dense[i] {
+			h.dense[i] = v
+		}
+	}
+}
+
+// Clone returns a copy of h
+func (h *HyperLogLog) Clone() *HyperLogLog {
+	c := &HyperLogLog{}
+	if h.dense != nil {
+		c.dense = append([]uint8(nil), h.dense...)
+	} else {
+		c.sparse = append([]uint32(nil), h.sparse...)
+	}
+	return c
+}
+
+// Count returns estimated cardinality
+func (h *HyperLogLog) Count() int {
+	const m = float64(hllRegisters)
+	alpha := 0.7213 / (1 + 1.079/m)
+
+	sum := 0.0
+	zeros := 0
+	if h.dense != nil {
+		for _, v := range h.dense {
+			sum += math.Ldexp(1, -int(v))
+			if v == 0 {
+				zeros++
+			}
+		}
+	} else {
+		zeros = hllRegisters - len(h.sparse)
+		sum = float64(zeros)
+		for _, r := range h.sparse {
+			sum += math.Ldexp(1, -int(uint8(r)))
+		}
+	}
+
+	estimate := alpha * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		// linear counting for small cardinalities
+		estimate = m * math.Log(m/float64(zeros))
+	}
+
+	return int(estimate + 0.5)
+}
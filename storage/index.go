@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// index is a secondary index of hash field values for keys with prefix
+type index struct {
+	prefix string
+	field  string
+	// keys are sets of indexed keys by field value
+	keys map[string]map[string]struct{}
+}
+
+// add adds key to the index by field value
+func (idx *index) add(key, val string) {
+	keys, ok := idx.keys[val]
+	if !ok {
+		keys = make(map[string]struct{})
+		idx.keys[val] = keys
+	}
+	keys[key] = struct{}{}
+}
+
+// remove removes key from the index by field value
+func (idx *index) remove(key, val string) {
+	keys, ok := idx.keys[val]
+	if !ok {
+		return
+	}
+	delete(keys, key)
+	if len(keys) == 0 {
+		delete(idx.keys, val)
+	}
+}
+
+// indexHash adds hash fields to the indexes matching the key or removes them, must be called under lock
+func (s *storage) indexHash(key string, h *hash, add bool) {
+	for _, idx := range s.indexes {
+		if !strings.HasPrefix(key, idx.prefix) {
+			continue
+		}
+
+		val, ok := h.fields[idx.field]
+		if !ok {
+			continue
+		}
+
+		if add {
+			idx.add(key, val)
+		} else {
+			idx.remove(key, val)
+		}
+	}
+}
+
+// setItem adds or replaces item removing replaced hash from the indexes, must be called under lock
+func (s *storage) setItem(key string, i item) {
+	if len(s.indexes) > 0 {
+		if h, ok := s.items[key].value.(*hash); ok {
+			s.indexHash(key, h, false)
+		}
+		if h, ok := i.value.(*hash); ok {
+			s.indexHash(key, h, true)
+		}
+	}
+	s.items[key] = i
+}
+
+// deleteItem deletes item removing deleted hash from the indexes, must be called under lock
+func (s *storage) deleteItem(key string) {
+	if len(s.indexes) > 0 {
+		if h, ok := s.items[key].value.(*hash); ok {
+			s.indexHash(key, h, false)
+		}
+	}
+	delete(s.items, key)
+}
+
+func (s *storage) IndexCreate(ctx context.Context, name, prefix, field string) error {
+	s.lock(ctx)
+	defer s.mu.Unlock()
+
+	if _, ok := s.indexes[name]; ok {
+		return ErrorIndexExists
+	}
+
+	idx := &index{
+		prefix: prefix,
+		field:  field,
+		keys:   make(map[string]map[string]struct{}),
+	}
+	for k, i := range s.items {
+		if h, ok := i.value.(*hash); ok && strings.HasPrefix(k, prefix) {
+			if val, ok := h.fields[field]; ok {
+				idx.add(k, val)
+			}
+		}
+	}
+
+	s.indexes[name] = idx
+	return nil
+}
+
+func (s *storage) IndexDrop(ctx context.Context, name string) error {
+	s.lock(ctx)
+	defer s.mu.Unlock()
+
+	if _, ok := s.indexes[name]; !ok {
+		return ErrorNoIndex
+	}
+
+	delete(s.indexes, name)
+	return nil
+}
+
+func (s *storage) IndexQuery(ctx context.Context, name, value, after string, count int) ([]string, error) {
+	s.rlock(ctx)
+	defer s.mu.RUnlock()
+
+	idx, ok := s.indexes[name]
+	if !ok {
+		return nil, ErrorNoIndex
+	}
+
+	res := []string{}
+	for key := range idx.keys[value] {
+		if key <= after {
+			continue
+		}
+
+		// expired keys and fields can be still indexed until cleaner deletes them
+		h, err := s.hashByKey(key, false)
+		if err != nil {
+			continue
+		}
+		if val, ok := h.get(idx.field); !ok || val != value {
+			continue
+		}
+
+		res = append(res, key)
+	}
+
+	sort.Strings(res)
+	if count > 0 && len(res) > count {
+		res = res[:count]
+	}
+	return res, nil
+}
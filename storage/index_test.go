@@ -0,0 +1,140 @@
+package storage_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alexxeis/keyval/storage"
+)
+
+// queryIndex returns all keys from the index by value
+func queryIndex(t *testing.T, s storage.Storage, name, value string) []string {
+	keys, err := s.IndexQuery(ctx, name, value, "", 0)
+	if err != nil {
+		t.Error(err)
+	}
+	return keys
+}
+
+func TestStorage_IndexCreate(t *testing.T) {
+	s := storage.NewStorage(0)
+
+	if err := s.Hset(ctx, "user:1", "country", "DE"); err != nil {
+		t.Error(err)
+	}
+	if err := s.Hset(ctx, "order:1", "country", "DE"); err != nil {
+		t.Error(err)
+	}
+
+	if err := s.IndexCreate(ctx, "country", "user:", "country"); err != nil {
+		t.Error(err)
+	}
+	if err := s.IndexCreate(ctx, "country", "user:", "country"); err != storage.ErrorIndexExists {
+		t.Error(err)
+	}
+
+	// test existing hashes are indexed
+	if keys := queryIndex(t, s, "country", "DE"); !reflect.DeepEqual(keys, []string{"user:1"}) {
+		t.Error("wrong keys ", keys)
+	}
+
+	if err := s.IndexDrop(ctx, "country"); err != nil {
+		t.Error(err)
+	}
+	if err := s.IndexDrop(ctx, "country"); err != storage.ErrorNoIndex {
+		t.Error(err)
+	}
+	if _, err := s.IndexQuery(ctx, "country", "DE", "", 0); err != storage.ErrorNoIndex {
+		t.Error(err)
+	}
+}
+
+func TestStorage_IndexMaintenance(t *testing.T) {
+	s := storage.NewStorage(0)
+	if err := s.IndexCreate(ctx, "country", "user:", "country"); err != nil {
+		t.Error(err)
+	}
+
+	for _, key := range []string{"user:1", "user:2", "user:3", "user:4"} {
+		if err := s.Hset(ctx, key, "country", "DE"); err != nil {
+			t.Error(err)
+		}
+	}
+
+	// test field update
+	if err := s.Hset(ctx, "user:1", "country", "FR"); err != nil {
+		t.Error(err)
+	}
+	// test field deletion
+	if err := s.Hdel(ctx, "user:2", "country"); err != nil {
+		t.Error(err)
+	}
+	// test key removal and replacement
+	s.Remove(ctx, "user:3")
+	if err := s.Hset(ctx, "user:3", "name", "foo"); err != nil {
+		t.Error(err)
+	}
+	s.Set(ctx, "user:4", "v", 0)
+
+	if keys := queryIndex(t, s, "country", "DE"); len(keys) != 0 {
+		t.Error("wrong keys ", keys)
+	}
+	if keys := queryIndex(t, s, "country", "FR"); !reflect.DeepEqual(keys, []string{"user:1"}) {
+		t.Error("wrong keys ", keys)
+	}
+}
+
+func TestStorage_IndexExpiration(t *testing.T) {
+	s := storage.NewStorage(10 * time.Millisecond)
+	defer s.Shutdown()
+
+	if err := s.IndexCreate(ctx, "country", "user:", "country"); err != nil {
+		t.Error(err)
+	}
+
+	for _, key := range []string{"user:1", "user:2", "user:3"} {
+		if err := s.Hset(ctx, key, "country", "DE"); err != nil {
+			t.Error(err)
+		}
+	}
+	s.Expire(ctx, "user:1", 5*time.Millisecond)
+	if _, err := s.Hexpire(ctx, "user:2", "country", 5*time.Millisecond); err != nil {
+		t.Error(err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	if keys := queryIndex(t, s, "country", "DE"); !reflect.DeepEqual(keys, []string{"user:3"}) {
+		t.Error("wrong keys ", keys)
+	}
+}
+
+func TestStorage_IndexQuery(t *testing.T) {
+	s := storage.NewStorage(0)
+	if err := s.IndexCreate(ctx, "country", "user:", "country"); err != nil {
+		t.Error(err)
+	}
+
+	for _, key := range []string{"user:3", "user:1", "user:4", "user:2"} {
+		if err := s.Hset(ctx, key, "country", "DE"); err != nil {
+			t.Error(err)
+		}
+	}
+
+	keys, err := s.IndexQuery(ctx, "country", "DE", "", 2)
+	if err != nil {
+		t.Error(err)
+	}
+	if !reflect.DeepEqual(keys, []string{"user:1", "user:2"}) {
+		t.Error("wrong keys ", keys)
+	}
+
+	keys, err = s.IndexQuery(ctx, "country", "DE", "user:2", 2)
+	if err != nil {
+		t.Error(err)
+	}
+	if !reflect.DeepEqual(keys, []string{"user:3", "user:4"}) {
+		t.Error("wrong keys ", keys)
+	}
+}
@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// documentByKey returns JSON document by key, must be called under lock
+func (s *storage) documentByKey(key string) (*document, error) {
+	i, ok := s.items[key]
+	if !ok || i.expired() {
+		return nil, ErrorNotFound
+	}
+
+	d, ok := i.value.(*document)
+	if !ok {
+		return nil, ErrorWrongType
+	}
+
+	return d, nil
+}
+
+func (s *storage) JSONSet(ctx context.Context, key, path string, value []byte) error {
+	segs, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+
+	v, err := decodeJSON(value)
+	if err != nil {
+		return err
+	}
+
+	s.lock(ctx)
+	defer s.mu.Unlock()
+
+	d, err := s.documentByKey(key)
+	if err == ErrorNotFound && len(segs) == 0 {
+		s.setItem(key, item{value: &document{root: v}})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return d.set(segs, v)
+}
+
+func (s *storage) JSONGet(ctx context.Context, key, path string) ([]byte, error) {
+	segs, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s.rlock(ctx)
+	defer s.mu.RUnlock()
+
+	d, err := s.documentByKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	v, ok := d.get(segs)
+	if !ok {
+		return nil, ErrorNoPath
+	}
+
+	return json.Marshal(v)
+}
+
+func (s *storage) JSONDel(ctx context.Context, key, path string) (int, error) {
+	segs, err := parsePath(path)
+	if err != nil {
+		return 0, err
+	}
+
+	s.lock(ctx)
+	defer s.mu.Unlock()
+
+	d, err := s.documentByKey(key)
+	if err == ErrorNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if len(segs) == 0 {
+		delete(s.items, key)
+		return 1, nil
+	}
+
+	if !d.del(segs) {
+		return 0, nil
+	}
+	return 1, nil
+}
+
+func (s *storage) JSONArrAppend(ctx context.Context, key, path string, values ...[]byte) (int, error) {
+	segs, err := parsePath(path)
+	if err != nil {
+		return 0, err
+	}
+
+	vals := make([]interface{}, len(values))
+	for i, v := range values {
+		if vals[i], err = decodeJSON(v); err != nil {
+			return 0, err
+		}
+	}
+
+	s.lock(ctx)
+	defer s.mu.Unlock()
+
+	d, err := s.documentByKey(key)
+	if err != nil {
+		return 0, err
+	}
+
+	v, ok := d.get(segs)
+	if !ok {
+		return 0, ErrorNoPath
+	}
+
+	arr, ok := v.([]interface{})
+	if !ok {
+		return 0, ErrorWrongType
+	}
+
+	arr = append(arr, vals...)
+	if err = d.set(segs, arr); err != nil {
+		return 0, err
+	}
+	return len(arr), nil
+}
+
+func (s *storage) JSONNumIncrBy(ctx context.Context, key, path string, incr float64) ([]byte, error) {
+	segs, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s.lock(ctx)
+	defer s.mu.Unlock()
+
+	d, err := s.documentByKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	v, ok := d.get(segs)
+	if !ok {
+		return nil, ErrorNoPath
+	}
+
+	n, ok := v.(json.Number)
+	if !ok {
+		return nil, ErrorWrongType
+	}
+
+	if n, err = addNumbers(n, incr); err != nil {
+		return nil, err
+	}
+	if err = d.set(segs, n); err != nil {
+		return nil, err
+	}
+	return []byte(n), nil
+}
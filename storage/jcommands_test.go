@@ -0,0 +1,182 @@
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/alexxeis/keyval/storage"
+)
+
+// jsonGet returns JSON value at path as string
+func jsonGet(t *testing.T, s storage.Storage, key, path string) string {
+	val, err := s.JSONGet(ctx, key, path)
+	if err != nil {
+		t.Error(err)
+	}
+	return string(val)
+}
+
+func TestStorage_JSONSet(t *testing.T) {
+	s := storage.NewStorage(0)
+	key := "doc"
+
+	// test missing document
+	if err := s.JSONSet(ctx, key, "$.a", []byte(`1`)); err != storage.ErrorNotFound {
+		t.Error(err)
+	}
+
+	if err := s.JSONSet(ctx, key, "$", []byte(`{"a":{"b":[1,2,3]},"c":"d"}`)); err != nil {
+		t.Error(err)
+	}
+
+	for _, c := range []struct {
+		path, value string
+	}{
+		{"$.a.b[1]", `"x"`},
+		{"$.a.b[-1]", `{"y":null}`},
+		{"$['c']", `true`},
+		{"$.e", `12345678901234567890`},
+	} {
+		if err := s.JSONSet(ctx, key, c.path, []byte(c.value)); err != nil {
+			t.Error(err)
+		}
+		if val := jsonGet(t, s, key, c.path); val != c.value {
+			t.Errorf("expected %s at %s, got %s", c.value, c.path, val)
+		}
+	}
+
+	expected := `{"a":{"b":[1,"x",{"y":null}]},"c":true,"e":12345678901234567890}`
+	if val := jsonGet(t, s, key, "$"); val != expected {
+		t.Errorf("expected %s, got %s", expected, val)
+	}
+
+	// test missing parent
+	if err := s.JSONSet(ctx, key, "$.x.y", []byte(`1`)); err != storage.ErrorNoPath {
+		t.Error(err)
+	}
+	if err := s.JSONSet(ctx, key, "$.a.b[5]", []byte(`1`)); err != storage.ErrorNoPath {
+		t.Error(err)
+	}
+
+	// test wrong path and value
+	if err := s.JSONSet(ctx, key, "a", []byte(`1`)); err != storage.ErrorJSONPath {
+		t.Error(err)
+	}
+	if err := s.JSONSet(ctx, key, "$..a", []byte(`1`)); err != storage.ErrorJSONPath {
+		t.Error(err)
+	}
+	if err := s.JSONSet(ctx, key, "$.a", []byte(`{`)); err != storage.ErrorJSON {
+		t.Error(err)
+	}
+
+	// test wrong type
+	s.Set(ctx, "string", "v", 0)
+	if err := s.JSONSet(ctx, "string", "$.a", []byte(`1`)); err != storage.ErrorWrongType {
+		t.Error(err)
+	}
+}
+
+func TestStorage_JSONDel(t *testing.T) {
+	s := storage.NewStorage(0)
+	key := "doc"
+
+	if err := s.JSONSet(ctx, key, "$", []byte(`{"a":[1,2,3],"b":1}`)); err != nil {
+		t.Error(err)
+	}
+
+	for _, c := range []struct {
+		path  string
+		count int
+	}{
+		{"$.a[0]", 1},
+		{"$.b", 1},
+		{"$.b", 0},
+		{"$.a[5]", 0},
+	} {
+		n, err := s.JSONDel(ctx, key, c.path)
+		if err != nil {
+			t.Error(err)
+		}
+		if n != c.count {
+			t.Errorf("expected deleted = %d at %s, got %d", c.count, c.path, n)
+		}
+	}
+
+	if val := jsonGet(t, s, key, "$"); val != `{"a":[2,3]}` {
+		t.Error("wrong document ", val)
+	}
+
+	n, err := s.JSONDel(ctx, key, "$")
+	if err != nil {
+		t.Error(err)
+	}
+	if n != 1 {
+		t.Errorf("expected deleted = %d, got %d", 1, n)
+	}
+	if _, err = s.JSONGet(ctx, key, "$"); err != storage.ErrorNotFound {
+		t.Error(err)
+	}
+}
+
+func TestStorage_JSONArrAppend(t *testing.T) {
+	s := storage.NewStorage(0)
+	key := "doc"
+
+	if err := s.JSONSet(ctx, key, "$", []byte(`{"a":[1],"b":"c"}`)); err != nil {
+		t.Error(err)
+	}
+
+	n, err := s.JSONArrAppend(ctx, key, "$.a", []byte(`2`), []byte(`"x"`))
+	if err != nil {
+		t.Error(err)
+	}
+	if n != 3 {
+		t.Errorf("expected length = %d, got %d", 3, n)
+	}
+	if val := jsonGet(t, s, key, "$.a"); val != `[1,2,"x"]` {
+		t.Error("wrong array ", val)
+	}
+
+	if _, err = s.JSONArrAppend(ctx, key, "$.b", []byte(`1`)); err != storage.ErrorWrongType {
+		t.Error(err)
+	}
+	if _, err = s.JSONArrAppend(ctx, key, "$.missing", []byte(`1`)); err != storage.ErrorNoPath {
+		t.Error(err)
+	}
+}
+
+func TestStorage_JSONNumIncrBy(t *testing.T) {
+	s := storage.NewStorage(0)
+	key := "doc"
+
+	if err := s.JSONSet(ctx, key, "$", []byte(`{"i":1,"f":1.5,"s":"a","max":9223372036854775807,"min":-9223372036854775808,"big":1e308}`)); err != nil {
+		t.Error(err)
+	}
+
+	for _, c := range []struct {
+		path     string
+		incr     float64
+		expected string
+	}{
+		{"$.i", 2, "3"},
+		{"$.i", 0.5, "3.5"},
+		{"$.f", -1, "0.5"},
+		// overflowed integers become floats
+		{"$.max", 1, "9.223372036854776e+18"},
+		{"$.min", -1, "-9.223372036854776e+18"},
+	} {
+		val, err := s.JSONNumIncrBy(ctx, key, c.path, c.incr)
+		if err != nil {
+			t.Error(err)
+		}
+		if string(val) != c.expected {
+			t.Errorf("expected %s at %s, got %s", c.expected, c.path, val)
+		}
+	}
+
+	if _, err := s.JSONNumIncrBy(ctx, key, "$.s", 1); err != storage.ErrorWrongType {
+		t.Error(err)
+	}
+	if _, err := s.JSONNumIncrBy(ctx, key, "$.big", 1e308); err != storage.ErrorNotANumber {
+		t.Error("expected not a number error, got ", err)
+	}
+}
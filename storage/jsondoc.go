@@ -0,0 +1,208 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// document is a JSON document, values are decoded with numbers as json.Number
+type document struct {
+	root interface{}
+}
+
+// pathSegment is a JSON path segment: object member name or array index
+type pathSegment struct {
+	name  string
+	index int
+	isIdx bool
+}
+
+// parsePath parses JSON path like $.a.b[0]['c'], "$" is the document root
+func parsePath(path string) ([]pathSegment, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, ErrorJSONPath
+	}
+
+	var segs []pathSegment
+	for p := path[1:]; p != ""; {
+		switch p[0] {
+		case '.':
+			end := strings.IndexAny(p[1:], ".[")
+			if end < 0 {
+				end = len(p) - 1
+			}
+			if end == 0 {
+				return nil, ErrorJSONPath
+			}
+			segs = append(segs, pathSegment{name: p[1 : end+1]})
+			p = p[end+1:]
+		case '[':
+			end := strings.IndexByte(p, ']')
+			if end < 0 {
+				return nil, ErrorJSONPath
+			}
+			inner := p[1:end]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				segs = append(segs, pathSegment{name: inner[1 : len(inner)-1]})
+			} else {
+				i, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, ErrorJSONPath
+				}
+				segs = append(segs, pathSegment{index: i, isIdx: true})
+			}
+			p = p[end+1:]
+		default:
+			return nil, ErrorJSONPath
+		}
+	}
+	return segs, nil
+}
+
+// decodeJSON decodes JSON value keeping numbers as json.Number
+func decodeJSON(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, ErrorJSON
+	}
+	if dec.More() {
+		return nil, ErrorJSON
+	}
+	return v, nil
+}
+
+// arrayIndex returns array index by segment index, negative index counts from the end
+func arrayIndex(arr []interface{}, idx int) (int, bool) {
+	if idx < 0 {
+		idx += len(arr)
+	}
+	return idx, idx >= 0 && idx < len(arr)
+}
+
+// child returns value of container by segment
+func child(v interface{}, seg pathSegment) (interface{}, bool) {
+	if seg.isIdx {
+		arr, ok := v.([]interface{})
+		if !ok {
+			return nil, false
+		}
+		i, ok := arrayIndex(arr, seg.index)
+		if !ok {
+			return nil, false
+		}
+		return arr[i], true
+	}
+
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	c, ok := obj[seg.name]
+	return c, ok
+}
+
+// get returns value by path
+func (d *document) get(segs []pathSegment) (interface{}, bool) {
+	v := d.root
+	for _, seg := range segs {
+		var ok bool
+		if v, ok = child(v, seg); !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// set replaces value by path or adds object member, parent must exist
+func (d *document) set(segs []pathSegment, val interface{}) error {
+	if len(segs) == 0 {
+		d.root = val
+		return nil
+	}
+
+	parent, ok := d.get(segs[:len(segs)-1])
+	if !ok {
+		return ErrorNoPath
+	}
+
+	seg := segs[len(segs)-1]
+	if seg.isIdx {
+		arr, ok := parent.([]interface{})
+		if !ok {
+			return ErrorNoPath
+		}
+		i, ok := arrayIndex(arr, seg.index)
+		if !ok {
+			return ErrorNoPath
+		}
+		arr[i] = val
+		return nil
+	}
+
+	obj, ok := parent.(map[string]interface{})
+	if !ok {
+		return ErrorNoPath
+	}
+	obj[seg.name] = val
+	return nil
+}
+
+// del deletes value by path, returns false if it's missing
+func (d *document) del(segs []pathSegment) bool {
+	parent, ok := d.get(segs[:len(segs)-1])
+	if !ok {
+		return false
+	}
+
+	seg := segs[len(segs)-1]
+	if seg.isIdx {
+		arr, ok := parent.([]interface{})
+		if !ok {
+			return false
+		}
+		i, ok := arrayIndex(arr, seg.index)
+		if !ok {
+			return false
+		}
+		// array is shrunk in place, so it's replaced in its parent
+		return d.set(segs[:len(segs)-1], append(arr[:i], arr[i+1:]...)) == nil
+	}
+
+	obj, ok := parent.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	if _, ok = obj[seg.name]; !ok {
+		return false
+	}
+	delete(obj, seg.name)
+	return true
+}
+
+// addNumbers returns sum of JSON number and increment, integers are kept if both are integers
+// and the sum doesn't overflow int64, float sum is returned otherwise
+func addNumbers(n json.Number, incr float64) (json.Number, error) {
+	if i, err := n.Int64(); err == nil && incr == math.Trunc(incr) && math.Abs(incr) < 1<<53 {
+		j := int64(incr)
+		if sum := i + j; (j >= 0) == (sum >= i) {
+			return json.Number(strconv.FormatInt(sum, 10)), nil
+		}
+	}
+
+	f, err := n.Float64()
+	if err != nil {
+		return "", ErrorWrongType
+	}
+
+	res := f + incr
+	if math.IsInf(res, 0) || math.IsNaN(res) {
+		return "", ErrorNotANumber
+	}
+	return json.Number(strconv.FormatFloat(res, 'g', -1, 64)), nil
+}
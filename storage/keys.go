@@ -0,0 +1,240 @@
+package storage
+
+import (
+	"container/list"
+	"context"
+)
+
+// Key types returned by Type
+const (
+	TypeNone        = "none"
+	TypeString      = "string"
+	TypeHash        = "hash"
+	TypeZset        = "zset"
+	TypeSet         = "set"
+	TypeList        = "list"
+	TypeStream      = "stream"
+	TypeHyperLogLog = "hyperloglog"
+	TypeJSON        = "json"
+)
+
+// typeName returns type name of item value
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case string, raw, compressed, *bitmap:
+		return TypeString
+	case *hash:
+		return TypeHash
+	case *zset:
+		return TypeZset
+	case set:
+		return TypeSet
+	case *list.List:
+		return TypeList
+	case *stream:
+		return TypeStream
+	case *HyperLogLog:
+		return TypeHyperLogLog
+	case *document:
+		return TypeJSON
+	}
+	return TypeNone
+}
+
+// cloneValue returns deep copy of item value, immutable values are shared
+func cloneValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case *bitmap:
+		return &bitmap{
+			data:        append([]byte(nil), val.data...),
+			contentType: val.contentType,
+		}
+	case *hash:
+		h := newHash()
+		for f, fv := range val.fields {
+			h.fields[f] = fv
+		}
+		if len(val.expirations) > 0 {
+			h.expirations = make(map[string]int64, len(val.expirations))
+			for f, exp := range val.expirations {
+				h.expirations[f] = exp
+			}
+		}
+		return h
+	case *zset:
+		z := newZset()
+		for m, score := range val.dict {
+			z.add(m, score)
+		}
+		return z
+	case set:
+		st := make(set, len(val))
+		for m := range val {
+			st[m] = struct{}{}
+		}
+		return st
+	case *list.List:
+		l := list.New()
+		l.PushBackList(val)
+		return l
+	case *stream:
+		st := newStream()
+		// entries are never modified, but the slice is appended
+		st.entries = append([]streamEntry(nil), val.entries...)
+		st.lastID = val.lastID
+		for name, g := range val.groups {
+			pending := make(map[streamID]*pendingEntry, len(g.pending))
+			for id, p := range g.pending {
+				cp := *p
+				pending[id] = &cp
+			}
+			st.groups[name] = &streamGroup{lastID: g.lastID, pending: pending}
+		}
+		return st
+	case *HyperLogLog:
+		return val.Clone()
+	case *document:
+		return &document{root: cloneJSON(val.root)}
+	}
+	return v
+}
+
+// cloneJSON returns deep copy of decoded JSON value
+func cloneJSON(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		obj := make(map[string]interface{}, len(val))
+		for k, c := range val {
+			obj[k] = cloneJSON(c)
+		}
+		return obj
+	case []interface{}:
+		arr := make([]interface{}, len(val))
+		for i, c := range val {
+			arr[i] = cloneJSON(c)
+		}
+		return arr
+	}
+	return v
+}
+
+// lockPair locks storage instances in order of their ids
+func lockPair(ctx context.Context, a, b *storage) {
+	if a == b {
+		a.lock(ctx)
+		return
+	}
+	if a.id > b.id {
+		a, b = b, a
+	}
+	a.lock(ctx)
+	b.lock(ctx)
+}
+
+// unlockPair unlocks storage instances locked by lockPair
+func unlockPair(a, b *storage) {
+	a.mu.Unlock()
+	if a != b {
+		b.mu.Unlock()
+	}
+}
+
+// Transfer moves or copies item with its TTL from src key of one instance to dst key of another one atomically.
+// Existing dst is replaced only if replace is true, returns false if it isn't replaced.
+// Instances must be created by NewStorage, ErrorForeignStorage is returned otherwise
+func Transfer(ctx context.Context, from Storage, src string, to Storage, dst string, move, replace bool) (bool, error) {
+	f, ok := from.(*storage)
+	if !ok {
+		return false, ErrorForeignStorage
+	}
+	t, ok := to.(*storage)
+	if !ok {
+		return false, ErrorForeignStorage
+	}
+	if f == t && src == dst {
+		if move {
+			f.rlock(ctx)
+			defer f.mu.RUnlock()
+
+			if i, ok := f.items[src]; !ok || i.expired() {
+				return false, ErrorNotFound
+			}
+			return true, nil
+		}
+		return false, ErrorSameKey
+	}
+
+	lockPair(ctx, f, t)
+	defer unlockPair(f, t)
+
+	i, ok := f.items[src]
+	if !ok || i.expired() {
+		return false, ErrorNotFound
+	}
+
+	if d, ok := t.items[dst]; ok && !d.expired() && !replace {
+		return false, nil
+	}
+
+	if move {
+		f.deleteItem(src)
+	} else {
+		i.value = cloneValue(i.value)
+	}
+	t.setItem(dst, i)
+
+	// wake up clients blocked on the new key
+	switch val := i.value.(type) {
+	case *list.List:
+		t.serve(dst, val)
+	case *stream:
+		t.notify(dst)
+	}
+	return true, nil
+}
+
+func (s *storage) Exists(ctx context.Context, keys ...string) int {
+	s.rlock(ctx)
+	defer s.mu.RUnlock()
+
+	n := 0
+	for _, key := range keys {
+		if i, ok := s.items[key]; ok && !i.expired() {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *storage) Type(ctx context.Context, key string) string {
+	s.rlock(ctx)
+	defer s.mu.RUnlock()
+
+	i, ok := s.items[key]
+	if !ok || i.expired() {
+		return TypeNone
+	}
+	return typeName(i.value)
+}
+
+func (s *storage) Dbsize(ctx context.Context) int {
+	s.rlock(ctx)
+	defer s.mu.RUnlock()
+
+	n := 0
+	for _, i := range s.items {
+		if !i.expired() {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *storage) Rename(ctx context.Context, src, dst string) error {
+	_, err := Transfer(ctx, s, src, s, dst, true, true)
+	return err
+}
+
+func (s *storage) Copy(ctx context.Context, src, dst string, replace bool) (bool, error) {
+	return Transfer(ctx, s, src, s, dst, false, replace)
+}
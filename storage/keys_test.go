@@ -0,0 +1,179 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alexxeis/keyval/storage"
+)
+
+func TestStorage_Exists(t *testing.T) {
+	s := storage.NewStorage(0)
+	s.Set(ctx, "a", "1", 0)
+	s.Set(ctx, "b", "2", time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+
+	// test repeated keys are counted and expired keys are skipped
+	if n := s.Exists(ctx, "a", "a", "b", "c"); n != 2 {
+		t.Errorf("expected count = 2, got %d", n)
+	}
+	if n := s.Dbsize(ctx); n != 1 {
+		t.Errorf("expected dbsize = 1, got %d", n)
+	}
+}
+
+func TestStorage_Type(t *testing.T) {
+	s := storage.NewStorage(0)
+	s.Set(ctx, "str", "v", 0)
+	if err := s.Hset(ctx, "hash", "f", "v"); err != nil {
+		t.Error(err)
+	}
+	if _, err := s.Rpush(ctx, "list", "a"); err != nil {
+		t.Error(err)
+	}
+	if _, err := s.Setbit(ctx, "bits", 1, true); err != nil {
+		t.Error(err)
+	}
+
+	types := map[string]string{
+		"str":     storage.TypeString,
+		"hash":    storage.TypeHash,
+		"list":    storage.TypeList,
+		"bits":    storage.TypeString,
+		"missing": storage.TypeNone,
+	}
+	for key, expected := range types {
+		if typ := s.Type(ctx, key); typ != expected {
+			t.Errorf("expected type of %s = %s, got %s", key, expected, typ)
+		}
+	}
+}
+
+func TestStorage_Rename(t *testing.T) {
+	s := storage.NewStorage(0)
+
+	if err := s.Rename(ctx, "a", "b"); err != storage.ErrorNotFound {
+		t.Error(err)
+	}
+
+	s.Set(ctx, "a", "1", time.Hour)
+	s.Set(ctx, "b", "2", 0)
+	if err := s.Rename(ctx, "a", "b"); err != nil {
+		t.Error(err)
+	}
+	if n := s.Exists(ctx, "a"); n != 0 {
+		t.Error("expected a is renamed")
+	}
+	if v, err := s.Get(ctx, "b"); err != nil || v != "1" {
+		t.Errorf("expected value = 1, got %s (%v)", v, err)
+	}
+
+	// test TTL is kept
+	if !s.Expire(ctx, "b", time.Millisecond) {
+		t.Error("expected b exists")
+	}
+	if err := s.Rename(ctx, "b", "c"); err != nil {
+		t.Error(err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	if _, err := s.Get(ctx, "c"); err != storage.ErrorNotFound {
+		t.Error(err)
+	}
+
+	// test same key
+	s.Set(ctx, "d", "1", 0)
+	if err := s.Rename(ctx, "d", "d"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStorage_Copy(t *testing.T) {
+	s := storage.NewStorage(0)
+
+	if _, err := s.Copy(ctx, "a", "b", false); err != storage.ErrorNotFound {
+		t.Error(err)
+	}
+
+	if err := s.Hset(ctx, "a", "f", "1"); err != nil {
+		t.Error(err)
+	}
+	if ok, err := s.Copy(ctx, "a", "b", false); err != nil || !ok {
+		t.Errorf("expected copied, got %v (%v)", ok, err)
+	}
+
+	// test copy is independent
+	if err := s.Hset(ctx, "b", "f", "2"); err != nil {
+		t.Error(err)
+	}
+	if v, err := s.Hget(ctx, "a", "f"); err != nil || v != "1" {
+		t.Errorf("expected value = 1, got %s (%v)", v, err)
+	}
+
+	// test existing dst
+	if ok, err := s.Copy(ctx, "a", "b", false); err != nil || ok {
+		t.Errorf("expected not copied, got %v (%v)", ok, err)
+	}
+	if ok, err := s.Copy(ctx, "a", "b", true); err != nil || !ok {
+		t.Errorf("expected copied, got %v (%v)", ok, err)
+	}
+	if v, err := s.Hget(ctx, "b", "f"); err != nil || v != "1" {
+		t.Errorf("expected value = 1, got %s (%v)", v, err)
+	}
+
+	if _, err := s.Copy(ctx, "a", "a", true); err != storage.ErrorSameKey {
+		t.Error(err)
+	}
+}
+
+func TestStorage_CopyWakesWaiters(t *testing.T) {
+	s := storage.NewStorage(0)
+	if _, err := s.Rpush(ctx, "src", "a"); err != nil {
+		t.Error(err)
+	}
+
+	done := make(chan string)
+	go func() {
+		v, err := s.Blpop(context.Background(), "dst", time.Second)
+		if err != nil {
+			t.Error(err)
+		}
+		done <- v
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	if ok, err := s.Copy(ctx, "src", "dst", false); err != nil || !ok {
+		t.Errorf("expected copied, got %v (%v)", ok, err)
+	}
+
+	if v := <-done; v != "a" {
+		t.Errorf("expected value = a, got %s", v)
+	}
+	if n, err := s.Llen(ctx, "src"); err != nil || n != 1 {
+		t.Errorf("expected len = 1, got %d (%v)", n, err)
+	}
+	if n := s.Exists(ctx, "dst"); n != 0 {
+		t.Error("expected empty dst is deleted")
+	}
+}
+
+// foreignStorage is a Storage implementation not created by NewStorage
+type foreignStorage struct {
+	storage.Storage
+}
+
+func TestTransfer_ForeignStorage(t *testing.T) {
+	s := storage.NewStorage(0)
+	s.Set(ctx, "a", "1", 0)
+	f := foreignStorage{storage.NewStorage(0)}
+
+	if _, err := storage.Transfer(ctx, s, "a", f, "b", true, true); err != storage.ErrorForeignStorage {
+		t.Error("wrong error ", err)
+	}
+	if _, err := storage.Transfer(ctx, f, "a", s, "b", false, true); err != storage.ErrorForeignStorage {
+		t.Error("wrong error ", err)
+	}
+	if n := s.Exists(ctx, "a"); n != 1 {
+		t.Error("expected a isn't moved")
+	}
+}
@@ -0,0 +1,248 @@
+package storage
+
+import (
+	"container/list"
+	"context"
+	"time"
+)
+
+// waiter is a client blocked on list pop
+type waiter struct {
+	ch   chan string
+	left bool
+	elem *list.Element
+}
+
+// listByKey returns list by key, creates new one if create is true, must be called under lock
+func (s *storage) listByKey(key string, create bool) (*list.List, error) {
+	i, ok := s.items[key]
+	if !ok || i.expired() {
+		if !create {
+			return nil, ErrorNotFound
+		}
+
+		l := list.New()
+		s.setItem(key, item{value: l})
+		return l, nil
+	}
+
+	l, ok := i.value.(*list.List)
+	if !ok {
+		return nil, ErrorWrongType
+	}
+
+	return l, nil
+}
+
+// pop deletes and returns first or last list element, deletes empty list, must be called under lock
+func (s *storage) pop(key string, l *list.List, left bool) string {
+	e := l.Back()
+	if left {
+		e = l.Front()
+	}
+
+	l.Remove(e)
+	if l.Len() == 0 {
+		delete(s.items, key)
+	}
+	return e.Value.(string)
+}
+
+// push adds values to the list and serves blocked clients, returns list length
+func (s *storage) push(ctx context.Context, key string, left bool, vals []string) (int, error) {
+	s.lock(ctx)
+	defer s.mu.Unlock()
+
+	l, err := s.listByKey(key, true)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, v := range vals {
+		if left {
+			l.PushFront(v)
+		} else {
+			l.PushBack(v)
+		}
+	}
+	n := l.Len()
+
+	s.serve(key, l)
+	return n, nil
+}
+
+// serve hands list values over to blocked clients in order of waiting, deletes empty list,
+// must be called under lock
+func (s *storage) serve(key string, l *list.List) {
+	ws := s.waiters[key]
+	for ws != nil && ws.Len() > 0 && l.Len() > 0 {
+		w := ws.Remove(ws.Front()).(*waiter)
+		w.elem = nil
+		w.ch <- s.pop(key, l, w.left)
+	}
+	if ws != nil && ws.Len() == 0 {
+		delete(s.waiters, key)
+	}
+	if l.Len() == 0 {
+		delete(s.items, key)
+	}
+}
+
+// bpop pops list element, blocks until element is pushed, timeout is passed or ctx is done
+func (s *storage) bpop(ctx context.Context, key string, timeout time.Duration, left bool) (string, error) {
+	s.lock(ctx)
+
+	l, err := s.listByKey(key, false)
+	if err == nil {
+		v := s.pop(key, l, left)
+		s.mu.Unlock()
+		return v, nil
+	}
+	if err != ErrorNotFound {
+		s.mu.Unlock()
+		return "", err
+	}
+
+	w := &waiter{
+		ch:   make(chan string, 1),
+		left: left,
+	}
+	ws, ok := s.waiters[key]
+	if !ok {
+		ws = list.New()
+		s.waiters[key] = ws
+	}
+	w.elem = ws.PushBack(w)
+
+	s.mu.Unlock()
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	select {
+	case v := <-w.ch:
+		return v, nil
+	case <-expired:
+	case <-ctx.Done():
+	}
+
+	s.lock(ctx)
+	if w.elem != nil {
+		ws.Remove(w.elem)
+		if ws.Len() == 0 && s.waiters[key] == ws {
+			delete(s.waiters, key)
+		}
+	}
+	s.mu.Unlock()
+
+	// value could be handed over before waiter was removed
+	select {
+	case v := <-w.ch:
+		return v, nil
+	default:
+	}
+
+	if err = ctx.Err(); err != nil {
+		return "", err
+	}
+	return "", ErrorTimeout
+}
+
+func (s *storage) Lpush(ctx context.Context, key string, vals ...string) (int, error) {
+	return s.push(ctx, key, true, vals)
+}
+
+func (s *storage) Rpush(ctx context.Context, key string, vals ...string) (int, error) {
+	return s.push(ctx, key, false, vals)
+}
+
+func (s *storage) Lpop(ctx context.Context, key string) (string, error) {
+	s.lock(ctx)
+	defer s.mu.Unlock()
+
+	l, err := s.listByKey(key, false)
+	if err != nil {
+		return "", err
+	}
+
+	return s.pop(key, l, true), nil
+}
+
+func (s *storage) Rpop(ctx context.Context, key string) (string, error) {
+	s.lock(ctx)
+	defer s.mu.Unlock()
+
+	l, err := s.listByKey(key, false)
+	if err != nil {
+		return "", err
+	}
+
+	return s.pop(key, l, false), nil
+}
+
+func (s *storage) Blpop(ctx context.Context, key string, timeout time.Duration) (string, error) {
+	return s.bpop(ctx, key, timeout, true)
+}
+
+func (s *storage) Brpop(ctx context.Context, key string, timeout time.Duration) (string, error) {
+	return s.bpop(ctx, key, timeout, false)
+}
+
+func (s *storage) Llen(ctx context.Context, key string) (int, error) {
+	s.rlock(ctx)
+	defer s.mu.RUnlock()
+
+	l, err := s.listByKey(key, false)
+	if err == ErrorNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return l.Len(), nil
+}
+
+func (s *storage) Lrange(ctx context.Context, key string, start, stop int) ([]string, error) {
+	s.rlock(ctx)
+	defer s.mu.RUnlock()
+
+	l, err := s.listByKey(key, false)
+	if err == ErrorNotFound {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	n := l.Len()
+	if start < 0 {
+		start += n
+	}
+	if stop < 0 {
+		stop += n
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= n {
+		stop = n - 1
+	}
+	if start > stop {
+		return []string{}, nil
+	}
+
+	res := make([]string, 0, stop-start+1)
+	idx := 0
+	for e := l.Front(); e != nil && idx <= stop; e = e.Next() {
+		if idx >= start {
+			res = append(res, e.Value.(string))
+		}
+		idx++
+	}
+	return res, nil
+}
@@ -0,0 +1,185 @@
+package storage_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alexxeis/keyval/storage"
+)
+
+func TestStorage_Push(t *testing.T) {
+	s := storage.NewStorage(0)
+	key := "l"
+
+	n, err := s.Rpush(ctx, key, "b", "c")
+	if err != nil {
+		t.Error(err)
+	}
+	if n != 2 {
+		t.Errorf("expected len = %d, got %d", 2, n)
+	}
+
+	if n, err = s.Lpush(ctx, key, "a"); err != nil || n != 3 {
+		t.Errorf("expected len = %d, got %d (%v)", 3, n, err)
+	}
+
+	vals, err := s.Lrange(ctx, key, 0, -1)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(vals) != 3 || vals[0] != "a" || vals[1] != "b" || vals[2] != "c" {
+		t.Error("wrong list ", vals)
+	}
+
+	vals, err = s.Lrange(ctx, key, -2, 10)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(vals) != 2 || vals[0] != "b" || vals[1] != "c" {
+		t.Error("wrong range ", vals)
+	}
+
+	// test wrong type
+	s.Set(ctx, "string", "v", 0)
+	if _, err = s.Lpush(ctx, "string", "v"); err != storage.ErrorWrongType {
+		t.Error(err)
+	}
+	if _, err = s.Get(ctx, key); err != storage.ErrorWrongType {
+		t.Error(err)
+	}
+}
+
+func TestStorage_Pop(t *testing.T) {
+	s := storage.NewStorage(0)
+	key := "l"
+
+	if _, err := s.Lpop(ctx, "missing"); err != storage.ErrorNotFound {
+		t.Error(err)
+	}
+
+	if _, err := s.Rpush(ctx, key, "a", "b", "c"); err != nil {
+		t.Error(err)
+	}
+
+	v, err := s.Lpop(ctx, key)
+	if err != nil || v != "a" {
+		t.Errorf("expected value = a, got %s (%v)", v, err)
+	}
+	if v, err = s.Rpop(ctx, key); err != nil || v != "c" {
+		t.Errorf("expected value = c, got %s (%v)", v, err)
+	}
+
+	n, err := s.Llen(ctx, key)
+	if err != nil || n != 1 {
+		t.Errorf("expected len = %d, got %d (%v)", 1, n, err)
+	}
+
+	// test empty list is removed
+	if _, err = s.Rpop(ctx, key); err != nil {
+		t.Error(err)
+	}
+	if _, err = s.Rpop(ctx, key); err != storage.ErrorNotFound {
+		t.Error(err)
+	}
+	if len(allKeys(t, s)) != 0 {
+		t.Error("empty list is not removed")
+	}
+}
+
+func TestStorage_Blpop(t *testing.T) {
+	s := storage.NewStorage(0)
+	key := "l"
+
+	// test not empty list
+	if _, err := s.Rpush(ctx, key, "a"); err != nil {
+		t.Error(err)
+	}
+	v, err := s.Blpop(context.Background(), key, time.Second)
+	if err != nil || v != "a" {
+		t.Errorf("expected value = a, got %s (%v)", v, err)
+	}
+
+	// test timeout
+	if _, err = s.Blpop(context.Background(), key, time.Millisecond); err != storage.ErrorTimeout {
+		t.Error(err)
+	}
+
+	// test cancel
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err = s.Brpop(ctx, key, 0); err != context.Canceled {
+		t.Error(err)
+	}
+
+	// test cancelled waiter doesn't consume values
+	if _, err = s.Rpush(ctx, key, "b"); err != nil {
+		t.Error(err)
+	}
+	if v, err = s.Lpop(ctx, key); err != nil || v != "b" {
+		t.Errorf("expected value = b, got %s (%v)", v, err)
+	}
+
+	// test wake up in order of waiting
+	results := make([]string, 2)
+	wg := sync.WaitGroup{}
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			v, err := s.Blpop(context.Background(), key, time.Second)
+			if err != nil {
+				t.Error(err)
+			}
+			results[i] = v
+		}(i)
+		// let waiter block before the next one
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if _, err = s.Rpush(ctx, key, "c", "d"); err != nil {
+		t.Error(err)
+	}
+	wg.Wait()
+
+	if results[0] != "c" || results[1] != "d" {
+		t.Error("wrong results ", results)
+	}
+	if n, _ := s.Llen(ctx, key); n != 0 {
+		t.Errorf("expected len = %d, got %d", 0, n)
+	}
+}
+
+func TestStorage_BlpopConcurrent(t *testing.T) {
+	s := storage.NewStorage(0)
+	key := "l"
+	count := 100
+
+	got := make(chan string, count)
+	wg := sync.WaitGroup{}
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := s.Brpop(context.Background(), key, 5*time.Second)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			got <- v
+		}()
+	}
+
+	for i := 0; i < count; i++ {
+		if _, err := s.Lpush(ctx, key, "v"); err != nil {
+			t.Error(err)
+		}
+	}
+	wg.Wait()
+	close(got)
+
+	if len(got) != count {
+		t.Errorf("expected %d values, got %d", count, len(got))
+	}
+}
@@ -0,0 +1,121 @@
+package storage
+
+import "context"
+
+// hllByKey returns HyperLogLog by key, creates new one if create is true, must be called under lock
+func (s *storage) hllByKey(key string, create bool) (*HyperLogLog, error) {
+	i, ok := s.items[key]
+	if !ok || i.expired() {
+		if !create {
+			return nil, ErrorNotFound
+		}
+
+		h := NewHyperLogLog()
+		s.setItem(key, item{value: h})
+		return h, nil
+	}
+
+	h, ok := i.value.(*HyperLogLog)
+	if !ok {
+		return nil, ErrorWrongType
+	}
+
+	return h, nil
+}
+
+// hllUnion returns a new HyperLogLog merged from HyperLogLogs by keys, must be called under lock
+func (s *storage) hllUnion(keys []string) (*HyperLogLog, error) {
+	u := NewHyperLogLog()
+	for _, key := range keys {
+		h, err := s.hllByKey(key, false)
+		if err == ErrorNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		u.Merge(h)
+	}
+	return u, nil
+}
+
+func (s *storage) Pfadd(ctx context.Context, key string, elements ...string) (bool, error) {
+	s.lock(ctx)
+	defer s.mu.Unlock()
+
+	h, err := s.hllByKey(key, false)
+	changed := err == ErrorNotFound
+	if changed {
+		h, err = s.hllByKey(key, true)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	for _, e := range elements {
+		if h.Add(e) {
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+func (s *storage) Pfcount(ctx context.Context, keys ...string) (int, error) {
+	s.rlock(ctx)
+	defer s.mu.RUnlock()
+
+	if len(keys) == 1 {
+		h, err := s.hllByKey(keys[0], false)
+		if err == ErrorNotFound {
+			return 0, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		return h.Count(), nil
+	}
+
+	u, err := s.hllUnion(keys)
+	if err != nil {
+		return 0, err
+	}
+	return u.Count(), nil
+}
+
+func (s *storage) Pfmerge(ctx context.Context, dest string, sources ...string) error {
+	s.lock(ctx)
+	defer s.mu.Unlock()
+
+	u, err := s.hllUnion(sources)
+	if err != nil {
+		return err
+	}
+
+	h, err := s.hllByKey(dest, true)
+	if err != nil {
+		return err
+	}
+
+	h.Merge(u)
+	return nil
+}
+
+func (s *storage) Pfget(ctx context.Context, keys ...string) (*HyperLogLog, error) {
+	s.rlock(ctx)
+	defer s.mu.RUnlock()
+
+	return s.hllUnion(keys)
+}
+
+func (s *storage) Pfstore(ctx context.Context, key string, h *HyperLogLog) error {
+	s.lock(ctx)
+	defer s.mu.Unlock()
+
+	dest, err := s.hllByKey(key, true)
+	if err != nil {
+		return err
+	}
+
+	dest.Merge(h)
+	return nil
+}
@@ -0,0 +1,156 @@
+package storage_test
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/alexxeis/keyval/storage"
+)
+
+// withinError returns true if estimation is within 3 standard errors from the exact value
+func withinError(estimation, exact int) bool {
+	return math.Abs(float64(estimation-exact)) <= 3*0.0081*float64(exact)+1
+}
+
+func TestHyperLogLog_Count(t *testing.T) {
+	h := storage.NewHyperLogLog()
+	if n := h.Count(); n != 0 {
+		t.Errorf("expected count = %d, got %d", 0, n)
+	}
+
+	for i := 0; i < 100; i++ {
+		h.Add("e" + strconv.Itoa(i))
+	}
+	if !h.Sparse() {
+		t.Error("expected sparse representation")
+	}
+	if n := h.Count(); n != 100 {
+		t.Errorf("expected count = %d, got %d", 100, n)
+	}
+
+	for i := 0; i < 100000; i++ {
+		h.Add("e" + strconv.Itoa(i))
+	}
+	if h.Sparse() {
+		t.Error("expected dense representation")
+	}
+	if n := h.Count(); !withinError(n, 100000) {
+		t.Errorf("expected count about %d, got %d", 100000, n)
+	}
+}
+
+func TestStorage_Pfadd(t *testing.T) {
+	s := storage.NewStorage(0)
+	key := "hll"
+
+	changed, err := s.Pfadd(ctx, key, "a", "b", "c")
+	if err != nil {
+		t.Error(err)
+	}
+	if !changed {
+		t.Error("expected changed")
+	}
+
+	changed, err = s.Pfadd(ctx, key, "a", "b")
+	if err != nil {
+		t.Error(err)
+	}
+	if changed {
+		t.Error("expected not changed")
+	}
+
+	n, err := s.Pfcount(ctx, key)
+	if err != nil {
+		t.Error(err)
+	}
+	if n != 3 {
+		t.Errorf("expected count = %d, got %d", 3, n)
+	}
+
+	// test key creation without elements
+	changed, err = s.Pfadd(ctx, "empty")
+	if err != nil {
+		t.Error(err)
+	}
+	if !changed {
+		t.Error("expected changed")
+	}
+
+	// test wrong type
+	s.Set(ctx, "string", "v", 0)
+	if _, err = s.Pfadd(ctx, "string", "a"); err != storage.ErrorWrongType {
+		t.Error(err)
+	}
+	if _, err = s.Pfcount(ctx, "string"); err != storage.ErrorWrongType {
+		t.Error(err)
+	}
+	if _, err = s.Get(ctx, key); err != storage.ErrorWrongType {
+		t.Error(err)
+	}
+}
+
+func TestStorage_Pfcount(t *testing.T) {
+	s := storage.NewStorage(0)
+
+	// test missing key
+	n, err := s.Pfcount(ctx, "missing")
+	if err != nil {
+		t.Error(err)
+	}
+	if n != 0 {
+		t.Errorf("expected count = %d, got %d", 0, n)
+	}
+
+	for i := 0; i < 20000; i++ {
+		if _, err = s.Pfadd(ctx, "h1", "e"+strconv.Itoa(i)); err != nil {
+			t.Error(err)
+		}
+		if _, err = s.Pfadd(ctx, "h2", "e"+strconv.Itoa(i+10000)); err != nil {
+			t.Error(err)
+		}
+	}
+
+	n, err = s.Pfcount(ctx, "h1", "h2", "missing")
+	if err != nil {
+		t.Error(err)
+	}
+	if !withinError(n, 30000) {
+		t.Errorf("expected count about %d, got %d", 30000, n)
+	}
+}
+
+func TestStorage_Pfmerge(t *testing.T) {
+	s := storage.NewStorage(0)
+
+	if _, err := s.Pfadd(ctx, "h1", "a", "b"); err != nil {
+		t.Error(err)
+	}
+	if _, err := s.Pfadd(ctx, "h2", "b", "c"); err != nil {
+		t.Error(err)
+	}
+	if _, err := s.Pfadd(ctx, "dest", "d"); err != nil {
+		t.Error(err)
+	}
+
+	if err := s.Pfmerge(ctx, "dest", "h1", "h2", "missing"); err != nil {
+		t.Error(err)
+	}
+
+	n, err := s.Pfcount(ctx, "dest")
+	if err != nil {
+		t.Error(err)
+	}
+	if n != 4 {
+		t.Errorf("expected count = %d, got %d", 4, n)
+	}
+
+	// test wrong type
+	s.Set(ctx, "string", "v", 0)
+	if err = s.Pfmerge(ctx, "string", "h1"); err != storage.ErrorWrongType {
+		t.Error(err)
+	}
+	if err = s.Pfmerge(ctx, "dest", "string"); err != storage.ErrorWrongType {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,222 @@
+package storage
+
+import "context"
+
+// set is an unordered set of unique members
+type set map[string]struct{}
+
+// members returns set members
+func (st set) members() []string {
+	res := make([]string, 0, len(st))
+	for m := range st {
+		res = append(res, m)
+	}
+	return res
+}
+
+// intersect returns members of all sets, nil set is empty
+func intersect(sets []set) []string {
+	res := []string{}
+	if len(sets) == 0 {
+		return res
+	}
+
+	// iterate over the smallest set
+	smallest := sets[0]
+	for _, st := range sets {
+		if len(st) < len(smallest) {
+			smallest = st
+		}
+	}
+
+loop:
+	for m := range smallest {
+		for _, st := range sets {
+			if _, ok := st[m]; !ok {
+				continue loop
+			}
+		}
+		res = append(res, m)
+	}
+	return res
+}
+
+// union returns members of any set, nil set is empty
+func union(sets []set) []string {
+	u := make(set)
+	for _, st := range sets {
+		for m := range st {
+			u[m] = struct{}{}
+		}
+	}
+	return u.members()
+}
+
+// diff returns members of the first set that are not in the other sets, nil set is empty
+func diff(sets []set) []string {
+	res := []string{}
+	if len(sets) == 0 {
+		return res
+	}
+
+loop:
+	for m := range sets[0] {
+		for _, st := range sets[1:] {
+			if _, ok := st[m]; ok {
+				continue loop
+			}
+		}
+		res = append(res, m)
+	}
+	return res
+}
+
+// setByKey returns set by key, creates new one if create is true, must be called under lock
+func (s *storage) setByKey(key string, create bool) (set, error) {
+	i, ok := s.items[key]
+	if !ok || i.expired() {
+		if !create {
+			return nil, ErrorNotFound
+		}
+
+		st := make(set)
+		s.setItem(key, item{value: st})
+		return st, nil
+	}
+
+	st, ok := i.value.(set)
+	if !ok {
+		return nil, ErrorWrongType
+	}
+
+	return st, nil
+}
+
+// setsByKeys returns sets by keys, missing sets are nil, must be called under lock
+func (s *storage) setsByKeys(keys []string) ([]set, error) {
+	sets := make([]set, len(keys))
+	for i, key := range keys {
+		st, err := s.setByKey(key, false)
+		if err != nil && err != ErrorNotFound {
+			return nil, err
+		}
+		sets[i] = st
+	}
+	return sets, nil
+}
+
+func (s *storage) Sadd(ctx context.Context, key string, members ...string) (int, error) {
+	s.lock(ctx)
+	defer s.mu.Unlock()
+
+	st, err := s.setByKey(key, true)
+	if err != nil {
+		return 0, err
+	}
+
+	added := 0
+	for _, m := range members {
+		if _, ok := st[m]; !ok {
+			st[m] = struct{}{}
+			added++
+		}
+	}
+
+	if len(st) == 0 {
+		delete(s.items, key)
+	}
+	return added, nil
+}
+
+func (s *storage) Srem(ctx context.Context, key string, members ...string) (int, error) {
+	s.lock(ctx)
+	defer s.mu.Unlock()
+
+	st, err := s.setByKey(key, false)
+	if err == ErrorNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, m := range members {
+		if _, ok := st[m]; ok {
+			delete(st, m)
+			removed++
+		}
+	}
+
+	if len(st) == 0 {
+		delete(s.items, key)
+	}
+	return removed, nil
+}
+
+func (s *storage) Sismember(ctx context.Context, key, member string) (bool, error) {
+	s.rlock(ctx)
+	defer s.mu.RUnlock()
+
+	st, err := s.setByKey(key, false)
+	if err == ErrorNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	_, ok := st[member]
+	return ok, nil
+}
+
+func (s *storage) Smembers(ctx context.Context, key string) ([]string, error) {
+	s.rlock(ctx)
+	defer s.mu.RUnlock()
+
+	st, err := s.setByKey(key, false)
+	if err == ErrorNotFound {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return st.members(), nil
+}
+
+func (s *storage) Sinter(ctx context.Context, keys ...string) ([]string, error) {
+	s.rlock(ctx)
+	defer s.mu.RUnlock()
+
+	sets, err := s.setsByKeys(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return intersect(sets), nil
+}
+
+func (s *storage) Sunion(ctx context.Context, keys ...string) ([]string, error) {
+	s.rlock(ctx)
+	defer s.mu.RUnlock()
+
+	sets, err := s.setsByKeys(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return union(sets), nil
+}
+
+func (s *storage) Sdiff(ctx context.Context, keys ...string) ([]string, error) {
+	s.rlock(ctx)
+	defer s.mu.RUnlock()
+
+	sets, err := s.setsByKeys(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return diff(sets), nil
+}
@@ -0,0 +1,178 @@
+package storage_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/alexxeis/keyval/storage"
+)
+
+func TestStorage_Sadd(t *testing.T) {
+	s := storage.NewStorage(0)
+	key := "s"
+
+	n, err := s.Sadd(ctx, key, "a", "b", "a")
+	if err != nil {
+		t.Error(err)
+	}
+	if n != 2 {
+		t.Errorf("expected added = %d, got %d", 2, n)
+	}
+
+	n, err = s.Sadd(ctx, key, "b", "c")
+	if err != nil {
+		t.Error(err)
+	}
+	if n != 1 {
+		t.Errorf("expected added = %d, got %d", 1, n)
+	}
+
+	members, err := s.Smembers(ctx, key)
+	if err != nil {
+		t.Error(err)
+	}
+	if !equalMembers(members, []string{"a", "b", "c"}) {
+		t.Error("wrong members ", members)
+	}
+
+	// test wrong type
+	s.Set(ctx, "string", "v", 0)
+	if _, err = s.Sadd(ctx, "string", "a"); err != storage.ErrorWrongType {
+		t.Error(err)
+	}
+	if _, err = s.Get(ctx, key); err != storage.ErrorWrongType {
+		t.Error(err)
+	}
+}
+
+func TestStorage_Srem(t *testing.T) {
+	s := storage.NewStorage(0)
+	key := "s"
+
+	// test missing key
+	n, err := s.Srem(ctx, "missing", "a")
+	if err != nil {
+		t.Error(err)
+	}
+	if n != 0 {
+		t.Errorf("expected removed = %d, got %d", 0, n)
+	}
+
+	if _, err = s.Sadd(ctx, key, "a", "b"); err != nil {
+		t.Error(err)
+	}
+	n, err = s.Srem(ctx, key, "a", "missing")
+	if err != nil {
+		t.Error(err)
+	}
+	if n != 1 {
+		t.Errorf("expected removed = %d, got %d", 1, n)
+	}
+
+	// test empty set is removed
+	if _, err = s.Srem(ctx, key, "b"); err != nil {
+		t.Error(err)
+	}
+	if len(allKeys(t, s)) != 0 {
+		t.Error("empty set is not removed")
+	}
+}
+
+func TestStorage_Sismember(t *testing.T) {
+	s := storage.NewStorage(0)
+	key := "s"
+
+	ok, err := s.Sismember(ctx, "missing", "a")
+	if err != nil {
+		t.Error(err)
+	}
+	if ok {
+		t.Error("member of missing set")
+	}
+
+	if _, err = s.Sadd(ctx, key, "a"); err != nil {
+		t.Error(err)
+	}
+	if ok, err = s.Sismember(ctx, key, "a"); err != nil || !ok {
+		t.Error("missing member ", err)
+	}
+	if ok, err = s.Sismember(ctx, key, "b"); err != nil || ok {
+		t.Error("unexpected member ", err)
+	}
+
+	// test wrong type
+	if err = s.Hset(ctx, "hset", "f", "v"); err != nil {
+		t.Error(err)
+	}
+	if _, err = s.Sismember(ctx, "hset", "a"); err != storage.ErrorWrongType {
+		t.Error(err)
+	}
+}
+
+func TestStorage_SetAlgebra(t *testing.T) {
+	s := storage.NewStorage(0)
+
+	if _, err := s.Sadd(ctx, "s1", "a", "b", "c"); err != nil {
+		t.Error(err)
+	}
+	if _, err := s.Sadd(ctx, "s2", "b", "c", "d"); err != nil {
+		t.Error(err)
+	}
+
+	members, err := s.Sinter(ctx, "s1", "s2")
+	if err != nil {
+		t.Error(err)
+	}
+	if !equalMembers(members, []string{"b", "c"}) {
+		t.Error("wrong intersection ", members)
+	}
+
+	members, err = s.Sunion(ctx, "s1", "s2", "missing")
+	if err != nil {
+		t.Error(err)
+	}
+	if !equalMembers(members, []string{"a", "b", "c", "d"}) {
+		t.Error("wrong union ", members)
+	}
+
+	members, err = s.Sdiff(ctx, "s1", "s2")
+	if err != nil {
+		t.Error(err)
+	}
+	if !equalMembers(members, []string{"a"}) {
+		t.Error("wrong difference ", members)
+	}
+
+	// test missing key
+	members, err = s.Sinter(ctx, "s1", "missing")
+	if err != nil {
+		t.Error(err)
+	}
+	if len(members) != 0 {
+		t.Error("not empty intersection ", members)
+	}
+
+	// test wrong type
+	s.Set(ctx, "string", "v", 0)
+	if _, err = s.Sunion(ctx, "s1", "string"); err != storage.ErrorWrongType {
+		t.Error(err)
+	}
+}
+
+// equalMembers returns true if members are equal regardless of order
+func equalMembers(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	a = append([]string(nil), a...)
+	b = append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -1,38 +1,293 @@
 package storage
 
 import (
+	"container/list"
+	"context"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/alexxeis/keyval/tracing"
 )
 
-// Storage is the interface for key-value storage
+// cancelCheck is a count of items scanned between checks of context cancellation
+const cancelCheck = 1024
+
+// Storage is the interface for key-value storage, commands take context of request.
+// Long read operations return context error if it's done, writes are completed anyway
 type Storage interface {
 	// Shutdown finish storage work
 	Shutdown()
 
+	// SetCleanInterval changes interval of expired items deletion, zero interval disables it
+	SetCleanInterval(interval time.Duration)
+
 	// Expire sets key expiration time
-	Expire(key string, ttl time.Duration) bool
+	Expire(ctx context.Context, key string, ttl time.Duration) bool
 
 	// Set adds value to the storage with the given key that's expire after ttl
-	Set(key, val string, ttl time.Duration)
+	Set(ctx context.Context, key, val string, ttl time.Duration)
+
+	// Get returns value from the storage by the key or ErrorNotFound if key is missing
+	Get(ctx context.Context, key string) (string, error)
 
-	// Get returns value from the storage by the key
-	Get(key string) (string, error)
+	// SetRaw adds binary value with its content type to the storage with the given key that's expire after ttl.
+	// Value is stored without copying, so it must not be modified after the call
+	SetRaw(ctx context.Context, key string, val []byte, contentType string, ttl time.Duration)
+
+	// GetRaw returns binary value and its content type from the storage by the key or ErrorNotFound if key is missing.
+	// Returned value must not be modified
+	GetRaw(ctx context.Context, key string) ([]byte, string, error)
 
 	// Remove deletes item from the storage by the key
-	Remove(key string)
+	Remove(ctx context.Context, key string)
+
+	// Keys returns all key names from the storage, scan is interrupted if ctx is done
+	Keys(ctx context.Context) ([]string, error)
+
+	// Flush deletes all keys from the storage, declared indexes are kept empty
+	Flush(ctx context.Context)
 
-	// Keys returns all key names from the storage
-	Keys() []string
+	// Exists returns count of existing keys, repeated keys are counted every time
+	Exists(ctx context.Context, keys ...string) int
 
-	// Hget returns value by key and field
-	Hget(key, field string) (string, error)
+	// Type returns type name of value by key or TypeNone if key is missing
+	Type(ctx context.Context, key string) string
+
+	// Dbsize returns count of not expired keys
+	Dbsize(ctx context.Context) int
+
+	// Rename renames key with its TTL replacing existing dst, returns ErrorNotFound if src is missing
+	Rename(ctx context.Context, src, dst string) error
+
+	// Copy copies value with its TTL to dst, existing dst is replaced only if replace is true.
+	// Returns false if value isn't copied or ErrorNotFound if src is missing
+	Copy(ctx context.Context, src, dst string, replace bool) (bool, error)
+
+	// Hget returns value by key and field or ErrorNotFound if key or field is missing
+	Hget(ctx context.Context, key, field string) (string, error)
 
 	// Hset adds value for key and field
-	Hset(key, field, val string) error
+	Hset(ctx context.Context, key, field, val string) error
 
 	// Hdel deletes value by key and field
-	Hdel(key, field string) error
+	Hdel(ctx context.Context, key, field string) error
+
+	// Hexpire sets hash field expiration time, returns false if key or field is missing
+	Hexpire(ctx context.Context, key, field string, ttl time.Duration) (bool, error)
+
+	// Httl returns remaining TTL of hash field, NoTtl if field doesn't expire or ErrorNotFound if key or field is missing
+	Httl(ctx context.Context, key, field string) (time.Duration, error)
+
+	// Hpersist removes hash field expiration, returns false if key or field is missing
+	Hpersist(ctx context.Context, key, field string) (bool, error)
+
+	// Zadd adds members to the sorted set or updates their scores, returns count of new members
+	Zadd(ctx context.Context, key string, members ...ZMember) (int, error)
+
+	// Zrem deletes members from the sorted set, returns count of deleted members
+	Zrem(ctx context.Context, key string, members ...string) (int, error)
+
+	// Zscore returns score of the sorted set member or ErrorNotFound if key or member is missing
+	Zscore(ctx context.Context, key, member string) (float64, error)
+
+	// Zrank returns 0-based rank of the sorted set member ordered by score or ErrorNotFound if key or member is missing
+	Zrank(ctx context.Context, key, member string) (int, error)
+
+	// Zrange returns sorted set members with ranks from start to stop inclusive, negative ranks count from the end
+	Zrange(ctx context.Context, key string, start, stop int) ([]ZMember, error)
+
+	// ZrangeByScore returns sorted set members with score from min to max inclusive
+	ZrangeByScore(ctx context.Context, key string, min, max float64) ([]ZMember, error)
+
+	// Zincrby increments score of the sorted set member, returns new score
+	Zincrby(ctx context.Context, key, member string, incr float64) (float64, error)
+
+	// Zpopmin deletes and returns up to count sorted set members with the lowest scores
+	Zpopmin(ctx context.Context, key string, count int) ([]ZMember, error)
+
+	// Sadd adds members to the set, returns count of new members
+	Sadd(ctx context.Context, key string, members ...string) (int, error)
+
+	// Srem deletes members from the set, returns count of deleted members
+	Srem(ctx context.Context, key string, members ...string) (int, error)
+
+	// Sismember returns true if member is in the set
+	Sismember(ctx context.Context, key, member string) (bool, error)
+
+	// Smembers returns all set members
+	Smembers(ctx context.Context, key string) ([]string, error)
+
+	// Sinter returns members of all sets by keys, missing key is an empty set
+	Sinter(ctx context.Context, keys ...string) ([]string, error)
+
+	// Sunion returns members of any set by keys, missing key is an empty set
+	Sunion(ctx context.Context, keys ...string) ([]string, error)
+
+	// Sdiff returns members of the first set that are not in the sets by other keys, missing key is an empty set
+	Sdiff(ctx context.Context, keys ...string) ([]string, error)
+
+	// Lpush prepends values to the list, returns list length
+	Lpush(ctx context.Context, key string, vals ...string) (int, error)
+
+	// Rpush appends values to the list, returns list length
+	Rpush(ctx context.Context, key string, vals ...string) (int, error)
+
+	// Lpop deletes and returns first list element or ErrorNotFound if list is empty
+	Lpop(ctx context.Context, key string) (string, error)
+
+	// Rpop deletes and returns last list element or ErrorNotFound if list is empty
+	Rpop(ctx context.Context, key string) (string, error)
+
+	// Blpop deletes and returns first list element, blocks until element is pushed.
+	// Returns ErrorTimeout if timeout is passed, zero timeout blocks until ctx is done
+	Blpop(ctx context.Context, key string, timeout time.Duration) (string, error)
+
+	// Brpop deletes and returns last list element, blocks until element is pushed.
+	// Returns ErrorTimeout if timeout is passed, zero timeout blocks until ctx is done
+	Brpop(ctx context.Context, key string, timeout time.Duration) (string, error)
+
+	// Llen returns list length
+	Llen(ctx context.Context, key string) (int, error)
+
+	// Lrange returns list elements from start to stop inclusive, negative indexes count from the end
+	Lrange(ctx context.Context, key string, start, stop int) ([]string, error)
+
+	// Xadd appends entry to the stream, "*" id is generated automatically, returns entry ID.
+	// Returns ErrorStreamID if id isn't greater than the last one
+	Xadd(ctx context.Context, key, id string, fields map[string]string) (string, error)
+
+	// Xlen returns stream entries count
+	Xlen(ctx context.Context, key string) (int, error)
+
+	// Xrange returns up to count stream entries with IDs from start to end inclusive, "-" and "+" are
+	// the minimal and the maximal IDs, zero count is unlimited
+	Xrange(ctx context.Context, key, start, end string, count int) ([]StreamEntry, error)
+
+	// Xread returns up to count stream entries with IDs greater than after, "$" is the last ID.
+	// Blocks until entry is added, negative timeout doesn't block, zero timeout blocks until ctx is done
+	Xread(ctx context.Context, key, after string, count int, timeout time.Duration) ([]StreamEntry, error)
+
+	// XgroupCreate creates stream consumer group delivering entries after start ID, "$" is the last ID.
+	// Creates empty stream if key is missing
+	XgroupCreate(ctx context.Context, key, group, start string) error
+
+	// Xreadgroup returns up to count new entries for group consumer if id is ">", blocks like Xread.
+	// Otherwise returns consumer's pending entries with ID greater than id without blocking
+	Xreadgroup(ctx context.Context, key, group, consumer, id string, count int, timeout time.Duration) ([]StreamEntry, error)
+
+	// Xack acknowledges pending entries of the group, returns count of acknowledged entries
+	Xack(ctx context.Context, key, group string, ids ...string) (int, error)
+
+	// Xpending returns pending entries of the group ordered by ID
+	Xpending(ctx context.Context, key, group string) ([]PendingEntry, error)
+
+	// Pfadd adds elements to the HyperLogLog, returns true if its estimation is changed or key is created
+	Pfadd(ctx context.Context, key string, elements ...string) (bool, error)
+
+	// Pfcount returns estimated cardinality of union of HyperLogLogs by keys, missing key is empty
+	Pfcount(ctx context.Context, keys ...string) (int, error)
+
+	// Pfmerge merges HyperLogLogs by sources into dest HyperLogLog, creates dest if it's missing
+	Pfmerge(ctx context.Context, dest string, sources ...string) error
+
+	// Pfget returns a copy of union of HyperLogLogs by keys, missing key is empty
+	Pfget(ctx context.Context, keys ...string) (*HyperLogLog, error)
+
+	// Pfstore merges h into HyperLogLog by key, creates it if key is missing
+	Pfstore(ctx context.Context, key string, h *HyperLogLog) error
+
+	// Setbit sets or clears bit at offset of the string value, returns the previous bit.
+	// Value is grown with zero bytes if needed, returns ErrorBitOffset if offset is out of range
+	Setbit(ctx context.Context, key string, offset int, bit bool) (bool, error)
+
+	// Getbit returns bit at offset of the string value, bits after the end of value are zero
+	Getbit(ctx context.Context, key string, offset int) (bool, error)
+
+	// Bitcount returns count of set bits in bytes from start to end inclusive, negative indexes count from the end
+	Bitcount(ctx context.Context, key string, start, end int) (int, error)
+
+	// Bitpos returns position of the first bit equal to bit in bytes from start to end inclusive or -1 if
+	// it's missing. Clear bit is found right after the value if end is -1
+	Bitpos(ctx context.Context, key string, bit bool, start, end int) (int, error)
+
+	// Bitop stores result of bitwise operation over string values by keys in dest, returns its length.
+	// Missing key is a zero value, empty result deletes dest
+	Bitop(ctx context.Context, op BitOp, dest string, keys ...string) (int, error)
+
+	// Geoadd adds members with their coordinates to the geo set or updates them, returns count of new members.
+	// Geo set is a sorted set with geohash scores
+	Geoadd(ctx context.Context, key string, locations ...GeoLocation) (int, error)
+
+	// Geopos returns coordinates of geo set members, missing members are nil
+	Geopos(ctx context.Context, key string, members ...string) ([]*GeoLocation, error)
+
+	// Geodist returns distance in meters between geo set members or ErrorNotFound if key or member is missing
+	Geodist(ctx context.Context, key, member1, member2 string) (float64, error)
+
+	// Geosearch returns geo set members within radius or box around the center ordered by distance
+	Geosearch(ctx context.Context, key string, q GeoQuery) ([]GeoResult, error)
+
+	// JSONSet sets JSON value at path like $.a.b[0] of the JSON document, "$" path creates the document.
+	// Returns ErrorNoPath if parent of the path is missing
+	JSONSet(ctx context.Context, key, path string, value []byte) error
+
+	// JSONGet returns JSON value at path of the JSON document or ErrorNoPath if path is missing
+	JSONGet(ctx context.Context, key, path string) ([]byte, error)
+
+	// JSONDel deletes value at path of the JSON document, "$" path deletes the document.
+	// Returns count of deleted values
+	JSONDel(ctx context.Context, key, path string) (int, error)
+
+	// JSONArrAppend appends JSON values to the array at path of the JSON document, returns array length
+	JSONArrAppend(ctx context.Context, key, path string, values ...[]byte) (int, error)
+
+	// JSONNumIncrBy increments number at path of the JSON document, returns new number
+	JSONNumIncrBy(ctx context.Context, key, path string, incr float64) ([]byte, error)
+
+	// IndexCreate declares secondary index of hash field for keys with prefix, existing hashes are indexed.
+	// Returns ErrorIndexExists if index with the name exists
+	IndexCreate(ctx context.Context, name, prefix, field string) error
+
+	// IndexDrop removes secondary index or returns ErrorNoIndex if it's missing
+	IndexDrop(ctx context.Context, name string) error
+
+	// IndexQuery returns up to count keys greater than after of hashes with indexed field equal to value
+	// ordered by key, zero count is unlimited. Returns ErrorNoIndex if index is missing
+	IndexQuery(ctx context.Context, name, value, after string, count int) ([]string, error)
+
+	// Stats returns storage statistics
+	Stats(ctx context.Context) Stats
+}
+
+// Stats is a storage statistics
+type Stats struct {
+	// Keys is a count of not expired keys
+	Keys int
+	// Compressed is a count of compressed values
+	Compressed int
+	// RawSize is a total size of compressed values before compression
+	RawSize int64
+	// CompressedSize is a total size of compressed values
+	CompressedSize int64
+}
+
+// Option is a storage instance option
+type Option func(*storage)
+
+// WithCompression enables compression of string values with size not less than threshold bytes
+func WithCompression(c Codec, threshold int) Option {
+	return func(s *storage) {
+		s.codec = c
+		s.compressThreshold = threshold
+	}
+}
+
+// WithPartition sets index of instance in cluster, it's recorded in lock spans of instance
+func WithPartition(idx int) Option {
+	return func(s *storage) {
+		s.partition = idx
+	}
 }
 
 // item is a basic storage element with data
@@ -41,6 +296,12 @@ type item struct {
 	expiration int64
 }
 
+// raw is a binary value with content type
+type raw struct {
+	data        []byte
+	contentType string
+}
+
 // expired returns true if item is expired
 func (i item) expired() bool {
 	return i.expiration != 0 && time.Now().UnixNano() > i.expiration
@@ -48,45 +309,117 @@ func (i item) expired() bool {
 
 // storage is a data storage instance
 type storage struct {
-	items         map[string]item
-	mu            sync.RWMutex
-	cleanInterval time.Duration
-	done          chan interface{}
+	items map[string]item
+	mu    sync.RWMutex
+	done  chan interface{}
+	// intervals pass changed clean interval to cleaner
+	intervals chan time.Duration
+
+	codec             Codec
+	compressThreshold int
+
+	// id orders locking of instances in multi-instance operations
+	id uint64
+	// partition is an index of instance in cluster, it's negative for standalone instance
+	partition int
+
+	// waiters are clients blocked on list pop by key
+	waiters map[string]*list.List
+	// signals wake up clients blocked on stream read by key
+	signals map[string]*signal
+	// indexes are secondary indexes of hash fields by name
+	indexes map[string]*index
 }
 
+// lastStorageID is the last assigned storage instance id
+var lastStorageID uint64
+
 // NewStorage returns new storage instance
-func NewStorage(cleanInterval time.Duration) *storage {
+func NewStorage(cleanInterval time.Duration, opts ...Option) *storage {
 	if cleanInterval < 0 {
 		panic("non-positive clean interval")
 	}
 
 	s := &storage{
-		items:         make(map[string]item),
-		cleanInterval: cleanInterval,
-		done:          make(chan interface{}),
+		id:        atomic.AddUint64(&lastStorageID, 1),
+		items:     make(map[string]item),
+		done:      make(chan interface{}),
+		intervals: make(chan time.Duration),
+		waiters:   make(map[string]*list.List),
+		signals:   make(map[string]*signal),
+		indexes:   make(map[string]*index),
+		partition: -1,
 	}
 
-	if cleanInterval > 0 {
-		go s.runCleaner()
+	for _, opt := range opts {
+		opt(s)
 	}
 
+	go s.runCleaner(cleanInterval)
+
 	return s
 }
 
+// lock locks storage for writing recording span of waiting in trace of ctx
+func (s *storage) lock(ctx context.Context) {
+	_, span := tracing.Start(ctx, "storage.lock")
+	if s.partition >= 0 {
+		span.SetAttribute("keyval.partition", s.partition)
+	}
+	s.mu.Lock()
+	span.End()
+}
+
+// rlock locks storage for reading recording span of waiting in trace of ctx
+func (s *storage) rlock(ctx context.Context) {
+	_, span := tracing.Start(ctx, "storage.rlock")
+	if s.partition >= 0 {
+		span.SetAttribute("keyval.partition", s.partition)
+	}
+	s.mu.RLock()
+	span.End()
+}
+
 // Shutdown stops storage's cleaner
 func (s *storage) Shutdown() {
 	close(s.done)
 }
 
-// runCleaner starts cleaner work
-func (s *storage) runCleaner() {
-	ticker := time.NewTicker(s.cleanInterval)
+func (s *storage) SetCleanInterval(interval time.Duration) {
+	if interval < 0 {
+		panic("negative clean interval")
+	}
+
+	select {
+	case s.intervals <- interval:
+	case <-s.done:
+	}
+}
+
+// runCleaner starts cleaner work, zero interval pauses cleaning until interval is changed
+func (s *storage) runCleaner(interval time.Duration) {
+	var ticker *time.Ticker
+	var tick <-chan time.Time
+	reset := func(interval time.Duration) {
+		if ticker != nil {
+			ticker.Stop()
+			ticker, tick = nil, nil
+		}
+		if interval > 0 {
+			ticker = time.NewTicker(interval)
+			tick = ticker.C
+		}
+	}
+
+	reset(interval)
 	for {
 		select {
-		case <-ticker.C:
+		case <-tick:
 			s.deleteExpiredItems()
+		case interval = <-s.intervals:
+			reset(interval)
 		case <-s.done:
-			ticker.Stop()
+			reset(0)
 			return
 		}
 	}
@@ -96,9 +429,21 @@ func (s *storage) runCleaner() {
 func (s *storage) deleteExpiredItems() {
 	s.mu.Lock()
 
+	now := time.Now().UnixNano()
 	for k, v := range s.items {
 		if v.expired() {
-			delete(s.items, k)
+			s.deleteItem(k)
+			continue
+		}
+
+		// delete expired hash fields and hashes left without fields
+		if h, ok := v.value.(*hash); ok && len(h.expirations) > 0 {
+			s.indexHash(k, h, false)
+			h.deleteExpired(now)
+			s.indexHash(k, h, true)
+			if h.len() == 0 {
+				s.deleteItem(k)
+			}
 		}
 	}
 
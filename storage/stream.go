@@ -0,0 +1,164 @@
+package storage
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StreamEntry is a stream entry with its ID
+type StreamEntry struct {
+	ID     string
+	Fields map[string]string
+}
+
+// PendingEntry is a stream entry delivered to group consumer and not acknowledged yet
+type PendingEntry struct {
+	ID         string
+	Consumer   string
+	Deliveries int
+	Idle       time.Duration
+}
+
+// streamID is a stream entry ID: milliseconds timestamp and sequence number
+type streamID struct {
+	ms  uint64
+	seq uint64
+}
+
+var (
+	minStreamID = streamID{}
+	maxStreamID = streamID{math.MaxUint64, math.MaxUint64}
+)
+
+func (id streamID) String() string {
+	return strconv.FormatUint(id.ms, 10) + "-" + strconv.FormatUint(id.seq, 10)
+}
+
+// less returns true if id precedes other
+func (id streamID) less(other streamID) bool {
+	return id.ms < other.ms || (id.ms == other.ms && id.seq < other.seq)
+}
+
+// next returns the smallest ID greater than id
+func (id streamID) next() streamID {
+	if id.seq == math.MaxUint64 {
+		return streamID{id.ms + 1, 0}
+	}
+	return streamID{id.ms, id.seq + 1}
+}
+
+// parseStreamID parses "ms-seq" or "ms" ID, missing sequence is defSeq
+func parseStreamID(s string, defSeq uint64) (streamID, error) {
+	parts := strings.SplitN(s, "-", 2)
+
+	ms, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return streamID{}, ErrorStreamID
+	}
+
+	seq := defSeq
+	if len(parts) == 2 {
+		if seq, err = strconv.ParseUint(parts[1], 10, 64); err != nil {
+			return streamID{}, ErrorStreamID
+		}
+	}
+
+	return streamID{ms, seq}, nil
+}
+
+// streamEntry is a stored stream entry
+type streamEntry struct {
+	id     streamID
+	fields map[string]string
+}
+
+// export returns entry for storage clients
+func (e streamEntry) export() StreamEntry {
+	fields := make(map[string]string, len(e.fields))
+	for k, v := range e.fields {
+		fields[k] = v
+	}
+	return StreamEntry{ID: e.id.String(), Fields: fields}
+}
+
+// pendingEntry is a stored pending entry
+type pendingEntry struct {
+	consumer    string
+	deliveries  int
+	deliveredAt time.Time
+}
+
+// streamGroup is a stream consumer group
+type streamGroup struct {
+	lastID  streamID
+	pending map[streamID]*pendingEntry
+}
+
+// stream is an append only log of entries ordered by ID
+type stream struct {
+	entries []streamEntry
+	lastID  streamID
+	groups  map[string]*streamGroup
+}
+
+// newStream returns new empty stream
+func newStream() *stream {
+	return &stream{
+		groups: make(map[string]*streamGroup),
+	}
+}
+
+// nextID returns auto generated ID for new entry
+func (st *stream) nextID() streamID {
+	ms := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	if ms <= st.lastID.ms {
+		return st.lastID.next()
+	}
+	return streamID{ms, 0}
+}
+
+// add appends entry, id must be greater than the last one
+func (st *stream) add(id streamID, fields map[string]string) {
+	st.entries = append(st.entries, streamEntry{id: id, fields: fields})
+	st.lastID = id
+}
+
+// search returns index of the first entry with ID not less than id
+func (st *stream) search(id streamID) int {
+	return sort.Search(len(st.entries), func(i int) bool {
+		return !st.entries[i].id.less(id)
+	})
+}
+
+// entry returns entry by ID
+func (st *stream) entry(id streamID) (streamEntry, bool) {
+	i := st.search(id)
+	if i < len(st.entries) && st.entries[i].id == id {
+		return st.entries[i], true
+	}
+	return streamEntry{}, false
+}
+
+// rangeEntries returns up to count entries with IDs from start to end inclusive, zero count is unlimited
+func (st *stream) rangeEntries(start, end streamID, count int) []streamEntry {
+	res := []streamEntry{}
+	for i := st.search(start); i < len(st.entries) && !end.less(st.entries[i].id); i++ {
+		if count > 0 && len(res) == count {
+			break
+		}
+		res = append(res, st.entries[i])
+	}
+	return res
+}
+
+// exportEntries returns entries for storage clients
+func exportEntries(entries []streamEntry) []StreamEntry {
+	res := make([]StreamEntry, len(entries))
+	for i, e := range entries {
+		res[i] = e.export()
+	}
+	return res
+}
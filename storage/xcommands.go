@@ -0,0 +1,401 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// signal wakes up clients blocked on stream read
+type signal struct {
+	ch      chan struct{}
+	waiters int
+}
+
+// subscribe returns signal of the next stream update by key, must be called under lock
+func (s *storage) subscribe(key string) *signal {
+	sig, ok := s.signals[key]
+	if !ok {
+		sig = &signal{ch: make(chan struct{})}
+		s.signals[key] = sig
+	}
+	sig.waiters++
+	return sig
+}
+
+// unsubscribe releases signal, must be called under lock
+func (s *storage) unsubscribe(key string, sig *signal) {
+	sig.waiters--
+	if sig.waiters == 0 && s.signals[key] == sig {
+		delete(s.signals, key)
+	}
+}
+
+// notify wakes up clients blocked on stream read by key, must be called under lock
+func (s *storage) notify(key string) {
+	if sig, ok := s.signals[key]; ok {
+		close(sig.ch)
+		delete(s.signals, key)
+	}
+}
+
+// streamByKey returns stream by key, creates new one if create is true, must be called under lock
+func (s *storage) streamByKey(key string, create bool) (*stream, error) {
+	i, ok := s.items[key]
+	if !ok || i.expired() {
+		if !create {
+			return nil, ErrorNotFound
+		}
+
+		st := newStream()
+		s.setItem(key, item{value: st})
+		return st, nil
+	}
+
+	st, ok := i.value.(*stream)
+	if !ok {
+		return nil, ErrorWrongType
+	}
+
+	return st, nil
+}
+
+// groupByName returns stream consumer group, must be called under lock
+func (s *storage) groupByName(key, group string) (*stream, *streamGroup, error) {
+	st, err := s.streamByKey(key, false)
+	if err == ErrorNotFound {
+		return nil, nil, ErrorNoGroup
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	g, ok := st.groups[group]
+	if !ok {
+		return nil, nil, ErrorNoGroup
+	}
+
+	return st, g, nil
+}
+
+// blockingRead repeats read under lock until it returns entries, timeout is passed or ctx is done.
+// Negative timeout returns the first read result without blocking, zero timeout blocks until ctx is done
+func (s *storage) blockingRead(ctx context.Context, key string, timeout time.Duration, read func() ([]StreamEntry, error)) ([]StreamEntry, error) {
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	for {
+		s.lock(ctx)
+		entries, err := read()
+		if err != nil || len(entries) > 0 || timeout < 0 {
+			s.mu.Unlock()
+			return entries, err
+		}
+		sig := s.subscribe(key)
+		s.mu.Unlock()
+
+		select {
+		case <-sig.ch:
+		case <-expired:
+			err = ErrorTimeout
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+
+		s.lock(ctx)
+		s.unsubscribe(key, sig)
+		s.mu.Unlock()
+
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (s *storage) Xadd(ctx context.Context, key, id string, fields map[string]string) (string, error) {
+	values := make(map[string]string, len(fields))
+	for k, v := range fields {
+		values[k] = v
+	}
+
+	s.lock(ctx)
+	defer s.mu.Unlock()
+
+	st, err := s.streamByKey(key, false)
+	created := err == ErrorNotFound
+	if created {
+		st = newStream()
+	} else if err != nil {
+		return "", err
+	}
+
+	var sid streamID
+	if id == "*" {
+		sid = st.nextID()
+	} else {
+		if sid, err = parseStreamID(id, 0); err != nil {
+			return "", err
+		}
+		if !st.lastID.less(sid) {
+			return "", ErrorStreamID
+		}
+	}
+
+	st.add(sid, values)
+	if created {
+		s.setItem(key, item{value: st})
+	}
+
+	s.notify(key)
+	return sid.String(), nil
+}
+
+func (s *storage) Xlen(ctx context.Context, key string) (int, error) {
+	s.rlock(ctx)
+	defer s.mu.RUnlock()
+
+	st, err := s.streamByKey(key, false)
+	if err == ErrorNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return len(st.entries), nil
+}
+
+func (s *storage) Xrange(ctx context.Context, key, start, end string, count int) ([]StreamEntry, error) {
+	from := minStreamID
+	if start != "-" {
+		id, err := parseStreamID(start, 0)
+		if err != nil {
+			return nil, err
+		}
+		from = id
+	}
+
+	to := maxStreamID
+	if end != "+" {
+		id, err := parseStreamID(end, maxStreamID.seq)
+		if err != nil {
+			return nil, err
+		}
+		to = id
+	}
+
+	s.rlock(ctx)
+	defer s.mu.RUnlock()
+
+	st, err := s.streamByKey(key, false)
+	if err == ErrorNotFound {
+		return []StreamEntry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return exportEntries(st.rangeEntries(from, to, count)), nil
+}
+
+func (s *storage) Xread(ctx context.Context, key, after string, count int, timeout time.Duration) ([]StreamEntry, error) {
+	var from streamID
+	if after == "$" {
+		s.rlock(ctx)
+		st, err := s.streamByKey(key, false)
+		if err == nil {
+			from = st.lastID.next()
+		}
+		s.mu.RUnlock()
+
+		if err != nil && err != ErrorNotFound {
+			return nil, err
+		}
+	} else {
+		id, err := parseStreamID(after, 0)
+		if err != nil {
+			return nil, err
+		}
+		from = id.next()
+	}
+
+	return s.blockingRead(ctx, key, timeout, func() ([]StreamEntry, error) {
+		st, err := s.streamByKey(key, false)
+		if err == ErrorNotFound {
+			return []StreamEntry{}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return exportEntries(st.rangeEntries(from, maxStreamID, count)), nil
+	})
+}
+
+func (s *storage) XgroupCreate(ctx context.Context, key, group, start string) error {
+	var from streamID
+	if start != "$" {
+		id, err := parseStreamID(start, 0)
+		if err != nil {
+			return err
+		}
+		from = id
+	}
+
+	s.lock(ctx)
+	defer s.mu.Unlock()
+
+	st, err := s.streamByKey(key, true)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := st.groups[group]; ok {
+		return ErrorGroupExists
+	}
+
+	if start == "$" {
+		from = st.lastID
+	}
+
+	st.groups[group] = &streamGroup{
+		lastID:  from,
+		pending: make(map[streamID]*pendingEntry),
+	}
+	return nil
+}
+
+func (s *storage) Xreadgroup(ctx context.Context, key, group, consumer, id string, count int, timeout time.Duration) ([]StreamEntry, error) {
+	if id != ">" {
+		after, err := parseStreamID(id, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		s.lock(ctx)
+		defer s.mu.Unlock()
+		return s.readPending(key, group, consumer, after, count)
+	}
+
+	return s.blockingRead(ctx, key, timeout, func() ([]StreamEntry, error) {
+		st, g, err := s.groupByName(key, group)
+		if err != nil {
+			return nil, err
+		}
+
+		entries := st.rangeEntries(g.lastID.next(), maxStreamID, count)
+		now := time.Now()
+		for _, e := range entries {
+			g.pending[e.id] = &pendingEntry{
+				consumer:    consumer,
+				deliveries:  1,
+				deliveredAt: now,
+			}
+		}
+		if len(entries) > 0 {
+			g.lastID = entries[len(entries)-1].id
+		}
+
+		return exportEntries(entries), nil
+	})
+}
+
+// readPending returns entries pending for consumer with ID greater than after, must be called under lock
+func (s *storage) readPending(key, group, consumer string, after streamID, count int) ([]StreamEntry, error) {
+	st, g, err := s.groupByName(key, group)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]streamID, 0, len(g.pending))
+	for id, p := range g.pending {
+		if p.consumer == consumer && after.less(id) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].less(ids[j])
+	})
+
+	res := []StreamEntry{}
+	now := time.Now()
+	for _, id := range ids {
+		if count > 0 && len(res) == count {
+			break
+		}
+
+		e, ok := st.entry(id)
+		if !ok {
+			continue
+		}
+
+		p := g.pending[id]
+		p.deliveries++
+		p.deliveredAt = now
+		res = append(res, e.export())
+	}
+	return res, nil
+}
+
+func (s *storage) Xack(ctx context.Context, key, group string, ids ...string) (int, error) {
+	sids := make([]streamID, len(ids))
+	for i, id := range ids {
+		sid, err := parseStreamID(id, 0)
+		if err != nil {
+			return 0, err
+		}
+		sids[i] = sid
+	}
+
+	s.lock(ctx)
+	defer s.mu.Unlock()
+
+	_, g, err := s.groupByName(key, group)
+	if err != nil {
+		return 0, err
+	}
+
+	acked := 0
+	for _, id := range sids {
+		if _, ok := g.pending[id]; ok {
+			delete(g.pending, id)
+			acked++
+		}
+	}
+	return acked, nil
+}
+
+func (s *storage) Xpending(ctx context.Context, key, group string) ([]PendingEntry, error) {
+	s.rlock(ctx)
+	defer s.mu.RUnlock()
+
+	_, g, err := s.groupByName(key, group)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]streamID, 0, len(g.pending))
+	for id := range g.pending {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].less(ids[j])
+	})
+
+	now := time.Now()
+	res := make([]PendingEntry, len(ids))
+	for i, id := range ids {
+		p := g.pending[id]
+		res[i] = PendingEntry{
+			ID:         id.String(),
+			Consumer:   p.consumer,
+			Deliveries: p.deliveries,
+			Idle:       now.Sub(p.deliveredAt),
+		}
+	}
+	return res, nil
+}
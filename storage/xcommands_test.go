@@ -0,0 +1,213 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alexxeis/keyval/storage"
+)
+
+func TestStorage_Xadd(t *testing.T) {
+	s := storage.NewStorage(0)
+	key := "x"
+
+	id1, err := s.Xadd(ctx, key, "*", map[string]string{"f": "v1"})
+	if err != nil {
+		t.Error(err)
+	}
+	id2, err := s.Xadd(ctx, key, "*", map[string]string{"f": "v2"})
+	if err != nil {
+		t.Error(err)
+	}
+	if id1 == id2 {
+		t.Error("duplicate id ", id1)
+	}
+
+	// test explicit id
+	if _, err = s.Xadd(ctx, key, "1-1", map[string]string{"f": "v"}); err != storage.ErrorStreamID {
+		t.Error(err)
+	}
+	if _, err = s.Xadd(ctx, "other", "5-1", map[string]string{"f": "v"}); err != nil {
+		t.Error(err)
+	}
+	if _, err = s.Xadd(ctx, "other", "5", map[string]string{"f": "v"}); err != storage.ErrorStreamID {
+		t.Error(err)
+	}
+	if _, err = s.Xadd(ctx, "other", "wrong", map[string]string{"f": "v"}); err != storage.ErrorStreamID {
+		t.Error(err)
+	}
+
+	n, err := s.Xlen(ctx, key)
+	if err != nil || n != 2 {
+		t.Errorf("expected len = %d, got %d (%v)", 2, n, err)
+	}
+
+	// test wrong type
+	s.Set(ctx, "string", "v", 0)
+	if _, err = s.Xadd(ctx, "string", "*", map[string]string{"f": "v"}); err != storage.ErrorWrongType {
+		t.Error(err)
+	}
+}
+
+func TestStorage_Xrange(t *testing.T) {
+	s := storage.NewStorage(0)
+	key := "x"
+
+	for _, id := range []string{"1-0", "1-1", "2-0", "3-5"} {
+		if _, err := s.Xadd(ctx, key, id, map[string]string{"id": id}); err != nil {
+			t.Error(err)
+		}
+	}
+
+	cases := []struct {
+		start, end string
+		count      int
+		expected   []string
+	}{
+		{"-", "+", 0, []string{"1-0", "1-1", "2-0", "3-5"}},
+		{"1", "1", 0, []string{"1-0", "1-1"}},
+		{"1-1", "3", 0, []string{"1-1", "2-0", "3-5"}},
+		{"-", "+", 2, []string{"1-0", "1-1"}},
+		{"4", "+", 0, []string{}},
+	}
+	for _, c := range cases {
+		entries, err := s.Xrange(ctx, key, c.start, c.end, c.count)
+		if err != nil {
+			t.Error(err)
+		}
+		if len(entries) != len(c.expected) {
+			t.Errorf("%s..%s: expected %v, got %v", c.start, c.end, c.expected, entries)
+			continue
+		}
+		for i, e := range entries {
+			if e.ID != c.expected[i] || e.Fields["id"] != c.expected[i] {
+				t.Errorf("%s..%s: expected %v, got %v", c.start, c.end, c.expected, entries)
+			}
+		}
+	}
+}
+
+func TestStorage_Xread(t *testing.T) {
+	s := storage.NewStorage(0)
+	key := "x"
+
+	// test non-blocking read of missing stream
+	entries, err := s.Xread(context.Background(), key, "0", 0, -1)
+	if err != nil || len(entries) != 0 {
+		t.Error("not empty result ", entries, err)
+	}
+
+	if _, err = s.Xadd(ctx, key, "1-0", map[string]string{"f": "v"}); err != nil {
+		t.Error(err)
+	}
+	entries, err = s.Xread(context.Background(), key, "0", 0, time.Second)
+	if err != nil || len(entries) != 1 || entries[0].ID != "1-0" {
+		t.Error("wrong result ", entries, err)
+	}
+
+	// test timeout
+	if _, err = s.Xread(context.Background(), key, "$", 0, time.Millisecond); err != storage.ErrorTimeout {
+		t.Error(err)
+	}
+
+	// test blocking read
+	done := make(chan []storage.StreamEntry)
+	go func() {
+		entries, err := s.Xread(context.Background(), key, "$", 0, time.Second)
+		if err != nil {
+			t.Error(err)
+		}
+		done <- entries
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	if _, err = s.Xadd(ctx, key, "2-0", map[string]string{"f": "v2"}); err != nil {
+		t.Error(err)
+	}
+	entries = <-done
+	if len(entries) != 1 || entries[0].ID != "2-0" || entries[0].Fields["f"] != "v2" {
+		t.Error("wrong result ", entries)
+	}
+
+	// test cancel
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err = s.Xread(ctx, key, "$", 0, 0); err != context.Canceled {
+		t.Error(err)
+	}
+}
+
+func TestStorage_Xreadgroup(t *testing.T) {
+	s := storage.NewStorage(0)
+	key := "x"
+	ctx := context.Background()
+
+	// test missing group
+	if _, err := s.Xreadgroup(ctx, key, "g", "c1", ">", 0, -1); err != storage.ErrorNoGroup {
+		t.Error(err)
+	}
+
+	if err := s.XgroupCreate(ctx, key, "g", "$"); err != nil {
+		t.Error(err)
+	}
+	if err := s.XgroupCreate(ctx, key, "g", "$"); err != storage.ErrorGroupExists {
+		t.Error(err)
+	}
+
+	for _, id := range []string{"1-0", "2-0", "3-0"} {
+		if _, err := s.Xadd(ctx, key, id, map[string]string{"id": id}); err != nil {
+			t.Error(err)
+		}
+	}
+
+	// consumers get different entries
+	entries, err := s.Xreadgroup(ctx, key, "g", "c1", ">", 2, -1)
+	if err != nil || len(entries) != 2 || entries[0].ID != "1-0" || entries[1].ID != "2-0" {
+		t.Error("wrong c1 entries ", entries, err)
+	}
+	entries, err = s.Xreadgroup(ctx, key, "g", "c2", ">", 0, time.Second)
+	if err != nil || len(entries) != 1 || entries[0].ID != "3-0" {
+		t.Error("wrong c2 entries ", entries, err)
+	}
+	if _, err = s.Xreadgroup(ctx, key, "g", "c2", ">", 0, time.Millisecond); err != storage.ErrorTimeout {
+		t.Error(err)
+	}
+
+	// test pending
+	pending, err := s.Xpending(ctx, key, "g")
+	if err != nil {
+		t.Error(err)
+	}
+	if len(pending) != 3 || pending[0].ID != "1-0" || pending[0].Consumer != "c1" || pending[2].Consumer != "c2" {
+		t.Errorf("wrong pending %+v", pending)
+	}
+
+	// test pending re-read
+	entries, err = s.Xreadgroup(ctx, key, "g", "c1", "0", 0, 0)
+	if err != nil || len(entries) != 2 {
+		t.Error("wrong pending entries ", entries, err)
+	}
+
+	// test ack
+	n, err := s.Xack(ctx, key, "g", "1-0", "3-0", "9-0")
+	if err != nil || n != 2 {
+		t.Errorf("expected acked = %d, got %d (%v)", 2, n, err)
+	}
+	pending, err = s.Xpending(ctx, key, "g")
+	if err != nil {
+		t.Error(err)
+	}
+	if len(pending) != 1 || pending[0].ID != "2-0" || pending[0].Deliveries != 2 {
+		t.Errorf("wrong pending %+v", pending)
+	}
+
+	// test group created from the start
+	if err = s.XgroupCreate(ctx, key, "all", "0"); err != nil {
+		t.Error(err)
+	}
+	entries, err = s.Xreadgroup(ctx, key, "all", "c", ">", 0, -1)
+	if err != nil || len(entries) != 3 {
+		t.Error("wrong entries ", entries, err)
+	}
+}
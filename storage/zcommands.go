@@ -0,0 +1,204 @@
+package storage
+
+import (
+	"context"
+	"math"
+)
+
+// zsetByKey returns sorted set by key, creates new one if create is true, must be called under lock
+func (s *storage) zsetByKey(key string, create bool) (*zset, error) {
+	i, ok := s.items[key]
+	if !ok || i.expired() {
+		if !create {
+			return nil, ErrorNotFound
+		}
+
+		z := newZset()
+		s.setItem(key, item{value: z})
+		return z, nil
+	}
+
+	z, ok := i.value.(*zset)
+	if !ok {
+		return nil, ErrorWrongType
+	}
+
+	return z, nil
+}
+
+func (s *storage) Zadd(ctx context.Context, key string, members ...ZMember) (int, error) {
+	for _, m := range members {
+		if math.IsNaN(m.Score) {
+			return 0, ErrorNotANumber
+		}
+	}
+
+	s.lock(ctx)
+	defer s.mu.Unlock()
+
+	z, err := s.zsetByKey(key, true)
+	if err != nil {
+		return 0, err
+	}
+
+	added := 0
+	for _, m := range members {
+		if z.add(m.Member, m.Score) {
+			added++
+		}
+	}
+
+	if z.len() == 0 {
+		delete(s.items, key)
+	}
+	return added, nil
+}
+
+func (s *storage) Zrem(ctx context.Context, key string, members ...string) (int, error) {
+	s.lock(ctx)
+	defer s.mu.Unlock()
+
+	z, err := s.zsetByKey(key, false)
+	if err == ErrorNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, m := range members {
+		if z.remove(m) {
+			removed++
+		}
+	}
+
+	if z.len() == 0 {
+		delete(s.items, key)
+	}
+	return removed, nil
+}
+
+func (s *storage) Zscore(ctx context.Context, key, member string) (float64, error) {
+	s.rlock(ctx)
+	defer s.mu.RUnlock()
+
+	z, err := s.zsetByKey(key, false)
+	if err != nil {
+		return 0, err
+	}
+
+	score, ok := z.dict[member]
+	if !ok {
+		return 0, ErrorNotFound
+	}
+
+	return score, nil
+}
+
+func (s *storage) Zrank(ctx context.Context, key, member string) (int, error) {
+	s.rlock(ctx)
+	defer s.mu.RUnlock()
+
+	z, err := s.zsetByKey(key, false)
+	if err != nil {
+		return 0, err
+	}
+
+	rank, ok := z.rank(member)
+	if !ok {
+		return 0, ErrorNotFound
+	}
+
+	return rank, nil
+}
+
+func (s *storage) Zrange(ctx context.Context, key string, start, stop int) ([]ZMember, error) {
+	s.rlock(ctx)
+	defer s.mu.RUnlock()
+
+	z, err := s.zsetByKey(key, false)
+	if err == ErrorNotFound {
+		return []ZMember{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return z.rangeByRank(start, stop), nil
+}
+
+func (s *storage) ZrangeByScore(ctx context.Context, key string, min, max float64) ([]ZMember, error) {
+	s.rlock(ctx)
+	defer s.mu.RUnlock()
+
+	z, err := s.zsetByKey(key, false)
+	if err == ErrorNotFound {
+		return []ZMember{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return z.rangeByScore(min, max), nil
+}
+
+func (s *storage) Zincrby(ctx context.Context, key, member string, incr float64) (float64, error) {
+	if math.IsNaN(incr) {
+		return 0, ErrorNotANumber
+	}
+
+	s.lock(ctx)
+	defer s.mu.Unlock()
+
+	z, err := s.zsetByKey(key, true)
+	if err != nil {
+		return 0, err
+	}
+
+	// infinite scores are kept like in Zadd, only finite scores can't overflow to infinity
+	cur := z.dict[member]
+	score := cur + incr
+	err = nil
+	if math.IsNaN(score) {
+		err = ErrorNotANumber
+	} else if math.IsInf(score, 0) && !math.IsInf(cur, 0) && !math.IsInf(incr, 0) {
+		err = ErrorScoreOverflow
+	}
+	if err != nil {
+		if z.len() == 0 {
+			delete(s.items, key)
+		}
+		return 0, err
+	}
+
+	z.add(member, score)
+	return score, nil
+}
+
+func (s *storage) Zpopmin(ctx context.Context, key string, count int) ([]ZMember, error) {
+	s.lock(ctx)
+	defer s.mu.Unlock()
+
+	z, err := s.zsetByKey(key, false)
+	if err == ErrorNotFound {
+		return []ZMember{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if count <= 0 {
+		return []ZMember{}, nil
+	}
+
+	members := z.rangeByRank(0, count-1)
+	for _, m := range members {
+		z.remove(m.Member)
+	}
+
+	if z.len() == 0 {
+		delete(s.items, key)
+	}
+	return members, nil
+}
@@ -0,0 +1,375 @@
+package storage_test
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/alexxeis/keyval/storage"
+)
+
+func TestStorage_Zadd(t *testing.T) {
+	s := storage.NewStorage(0)
+	key := "z"
+
+	n, err := s.Zadd(ctx, key, storage.ZMember{Member: "a", Score: 1}, storage.ZMember{Member: "b", Score: 2})
+	if err != nil {
+		t.Error(err)
+	}
+	if n != 2 {
+		t.Errorf("expected added = %d, got %d", 2, n)
+	}
+
+	// test update
+	n, err = s.Zadd(ctx, key, storage.ZMember{Member: "a", Score: 3}, storage.ZMember{Member: "c", Score: 0})
+	if err != nil {
+		t.Error(err)
+	}
+	if n != 1 {
+		t.Errorf("expected added = %d, got %d", 1, n)
+	}
+
+	members, err := s.Zrange(ctx, key, 0, -1)
+	if err != nil {
+		t.Error(err)
+	}
+	expected := []storage.ZMember{{"c", 0}, {"b", 2}, {"a", 3}}
+	if !equalZMembers(members, expected) {
+		t.Errorf("expected %v, got %v", expected, members)
+	}
+
+	// test NaN
+	if _, err = s.Zadd(ctx, key, storage.ZMember{Member: "d", Score: math.NaN()}); err != storage.ErrorNotANumber {
+		t.Error(err)
+	}
+
+	// test wrong type
+	s.Set(ctx, "string", "v", 0)
+	if _, err = s.Zadd(ctx, "string", storage.ZMember{Member: "a", Score: 1}); err != storage.ErrorWrongType {
+		t.Error(err)
+	}
+	if _, err = s.Get(ctx, key); err != storage.ErrorWrongType {
+		t.Error(err)
+	}
+}
+
+func TestStorage_Zrem(t *testing.T) {
+	s := storage.NewStorage(0)
+	key := "z"
+
+	// test missing key
+	n, err := s.Zrem(ctx, "missing", "a")
+	if err != nil {
+		t.Error(err)
+	}
+	if n != 0 {
+		t.Errorf("expected removed = %d, got %d", 0, n)
+	}
+
+	if _, err = s.Zadd(ctx, key, storage.ZMember{Member: "a", Score: 1}, storage.ZMember{Member: "b", Score: 2}); err != nil {
+		t.Error(err)
+	}
+	n, err = s.Zrem(ctx, key, "a", "missing")
+	if err != nil {
+		t.Error(err)
+	}
+	if n != 1 {
+		t.Errorf("expected removed = %d, got %d", 1, n)
+	}
+	if _, err = s.Zscore(ctx, key, "a"); err != storage.ErrorNotFound {
+		t.Error(err)
+	}
+
+	// test empty set is removed
+	if _, err = s.Zrem(ctx, key, "b"); err != nil {
+		t.Error(err)
+	}
+	if len(allKeys(t, s)) != 0 {
+		t.Error("empty sorted set is not removed")
+	}
+}
+
+func TestStorage_Zscore(t *testing.T) {
+	s := storage.NewStorage(0)
+	key := "z"
+
+	if _, err := s.Zscore(ctx, "missing", "a"); err != storage.ErrorNotFound {
+		t.Error(err)
+	}
+
+	if _, err := s.Zadd(ctx, key, storage.ZMember{Member: "a", Score: 1.5}); err != nil {
+		t.Error(err)
+	}
+	score, err := s.Zscore(ctx, key, "a")
+	if err != nil {
+		t.Error(err)
+	}
+	if score != 1.5 {
+		t.Errorf("expected score = %v, got %v", 1.5, score)
+	}
+
+	if _, err = s.Zscore(ctx, key, "missing"); err != storage.ErrorNotFound {
+		t.Error(err)
+	}
+}
+
+func TestStorage_Zrank(t *testing.T) {
+	s := storage.NewStorage(0)
+	key := "z"
+
+	if _, err := s.Zrank(ctx, "missing", "a"); err != storage.ErrorNotFound {
+		t.Error(err)
+	}
+
+	if _, err := s.Zadd(ctx, key, storage.ZMember{Member: "b", Score: 1}, storage.ZMember{Member: "a", Score: 1}, storage.ZMember{Member: "c", Score: 0}); err != nil {
+		t.Error(err)
+	}
+	for i, m := range []string{"c", "a", "b"} {
+		rank, err := s.Zrank(ctx, key, m)
+		if err != nil {
+			t.Error(err)
+		}
+		if rank != i {
+			t.Errorf("expected %s rank = %d, got %d", m, i, rank)
+		}
+	}
+
+	if _, err := s.Zrank(ctx, key, "missing"); err != storage.ErrorNotFound {
+		t.Error(err)
+	}
+}
+
+func TestStorage_Zrange(t *testing.T) {
+	s := storage.NewStorage(0)
+	key := "z"
+
+	members, err := s.Zrange(ctx, "missing", 0, -1)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(members) != 0 {
+		t.Error("not empty range")
+	}
+
+	for i := 0; i < 5; i++ {
+		if _, err = s.Zadd(ctx, key, storage.ZMember{Member: strconv.Itoa(i), Score: float64(i)}); err != nil {
+			t.Error(err)
+		}
+	}
+
+	cases := []struct {
+		start, stop int
+		expected    []storage.ZMember
+	}{
+		{0, 1, []storage.ZMember{{"0", 0}, {"1", 1}}},
+		{-2, -1, []storage.ZMember{{"3", 3}, {"4", 4}}},
+		{3, 100, []storage.ZMember{{"3", 3}, {"4", 4}}},
+		{-100, 0, []storage.ZMember{{"0", 0}}},
+		{3, 1, []storage.ZMember{}},
+		{5, 10, []storage.ZMember{}},
+	}
+	for _, c := range cases {
+		members, err = s.Zrange(ctx, key, c.start, c.stop)
+		if err != nil {
+			t.Error(err)
+		}
+		if !equalZMembers(members, c.expected) {
+			t.Errorf("range %d..%d: expected %v, got %v", c.start, c.stop, c.expected, members)
+		}
+	}
+}
+
+func TestStorage_ZrangeByScore(t *testing.T) {
+	s := storage.NewStorage(0)
+	key := "z"
+
+	for i := 0; i < 5; i++ {
+		if _, err := s.Zadd(ctx, key, storage.ZMember{Member: strconv.Itoa(i), Score: float64(i)}); err != nil {
+			t.Error(err)
+		}
+	}
+
+	members, err := s.ZrangeByScore(ctx, key, 1.5, 3)
+	if err != nil {
+		t.Error(err)
+	}
+	expected := []storage.ZMember{{"2", 2}, {"3", 3}}
+	if !equalZMembers(members, expected) {
+		t.Errorf("expected %v, got %v", expected, members)
+	}
+
+	members, err = s.ZrangeByScore(ctx, key, math.Inf(-1), math.Inf(1))
+	if err != nil {
+		t.Error(err)
+	}
+	if len(members) != 5 {
+		t.Errorf("expected len = %d, got %d", 5, len(members))
+	}
+
+	members, err = s.ZrangeByScore(ctx, key, 10, 20)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(members) != 0 {
+		t.Error("not empty range")
+	}
+}
+
+func TestStorage_Zincrby(t *testing.T) {
+	s := storage.NewStorage(0)
+	key := "z"
+
+	score, err := s.Zincrby(ctx, key, "a", 2)
+	if err != nil {
+		t.Error(err)
+	}
+	if score != 2 {
+		t.Errorf("expected score = %v, got %v", 2, score)
+	}
+
+	score, err = s.Zincrby(ctx, key, "a", -0.5)
+	if err != nil {
+		t.Error(err)
+	}
+	if score != 1.5 {
+		t.Errorf("expected score = %v, got %v", 1.5, score)
+	}
+
+	// test overflow of finite score
+	if _, err = s.Zincrby(ctx, key, "b", math.MaxFloat64); err != nil {
+		t.Error(err)
+	}
+	if _, err = s.Zincrby(ctx, key, "b", math.MaxFloat64); err != storage.ErrorScoreOverflow {
+		t.Error(err)
+	}
+	if score, err := s.Zscore(ctx, key, "b"); err != nil || score != math.MaxFloat64 {
+		t.Errorf("expected score = %v, got %v (%v)", math.MaxFloat64, score, err)
+	}
+
+	// test infinite scores are kept
+	if _, err = s.Zadd(ctx, key, storage.ZMember{Member: "c", Score: math.Inf(1)}); err != nil {
+		t.Error(err)
+	}
+	if score, err = s.Zincrby(ctx, key, "c", 1); err != nil || !math.IsInf(score, 1) {
+		t.Errorf("expected score = +Inf, got %v (%v)", score, err)
+	}
+	if score, err = s.Zincrby(ctx, key, "d", math.Inf(-1)); err != nil || !math.IsInf(score, -1) {
+		t.Errorf("expected score = -Inf, got %v (%v)", score, err)
+	}
+
+	// test NaN result
+	if _, err = s.Zincrby(ctx, key, "c", math.Inf(-1)); err != storage.ErrorNotANumber {
+		t.Error(err)
+	}
+
+	// test failed increment doesn't create key
+	if _, err = s.Zincrby(ctx, "missing", "a", math.NaN()); err != storage.ErrorNotANumber {
+		t.Error(err)
+	}
+	if n := s.Exists(ctx, "missing"); n != 0 {
+		t.Error("expected missing key isn't created")
+	}
+}
+
+func TestStorage_Zpopmin(t *testing.T) {
+	s := storage.NewStorage(0)
+	key := "z"
+
+	for i := 0; i < 3; i++ {
+		if _, err := s.Zadd(ctx, key, storage.ZMember{Member: strconv.Itoa(i), Score: float64(i)}); err != nil {
+			t.Error(err)
+		}
+	}
+
+	members, err := s.Zpopmin(ctx, key, 2)
+	if err != nil {
+		t.Error(err)
+	}
+	expected := []storage.ZMember{{"0", 0}, {"1", 1}}
+	if !equalZMembers(members, expected) {
+		t.Errorf("expected %v, got %v", expected, members)
+	}
+
+	members, err = s.Zpopmin(ctx, key, 2)
+	if err != nil {
+		t.Error(err)
+	}
+	expected = []storage.ZMember{{"2", 2}}
+	if !equalZMembers(members, expected) {
+		t.Errorf("expected %v, got %v", expected, members)
+	}
+
+	members, err = s.Zpopmin(ctx, key, 2)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(members) != 0 {
+		t.Error("not empty result")
+	}
+}
+
+func TestStorage_ZsetRandom(t *testing.T) {
+	s := storage.NewStorage(0)
+	key := "z"
+	scores := make(map[string]float64)
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 5000; i++ {
+		m := strconv.Itoa(r.Intn(300))
+		if r.Intn(3) == 0 {
+			if _, err := s.Zrem(ctx, key, m); err != nil {
+				t.Fatal(err)
+			}
+			delete(scores, m)
+			continue
+		}
+
+		score := float64(r.Intn(50))
+		if _, err := s.Zadd(ctx, key, storage.ZMember{Member: m, Score: score}); err != nil {
+			t.Fatal(err)
+		}
+		scores[m] = score
+	}
+
+	expected := make([]storage.ZMember, 0, len(scores))
+	for m, score := range scores {
+		expected = append(expected, storage.ZMember{Member: m, Score: score})
+	}
+	sort.Slice(expected, func(i, j int) bool {
+		a, b := expected[i], expected[j]
+		return a.Score < b.Score || (a.Score == b.Score && a.Member < b.Member)
+	})
+
+	members, err := s.Zrange(ctx, key, 0, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalZMembers(members, expected) {
+		t.Fatal("wrong order")
+	}
+
+	for i, m := range expected {
+		rank, err := s.Zrank(ctx, key, m.Member)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rank != i {
+			t.Fatalf("expected %s rank = %d, got %d", m.Member, i, rank)
+		}
+	}
+}
+
+// equalZMembers returns true if sorted set members are equal
+func equalZMembers(a, b []storage.ZMember) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,290 @@
+package storage
+
+import (
+	"math/rand"
+)
+
+const (
+	zslMaxLevel    = 32
+	zslProbability = 0.25
+)
+
+// ZMember is a sorted set member with its score
+type ZMember struct {
+	Member string
+	Score  float64
+}
+
+// zset is a sorted set: skiplist ordered by score and member with member to score map
+type zset struct {
+	dict map[string]float64
+	zsl  *zskiplist
+}
+
+// newZset returns new empty sorted set
+func newZset() *zset {
+	return &zset{
+		dict: make(map[string]float64),
+		zsl:  newZskiplist(),
+	}
+}
+
+// len returns members count
+func (z *zset) len() int {
+	return len(z.dict)
+}
+
+// add adds member or updates its score, returns true if member is new
+func (z *zset) add(member string, score float64) bool {
+	cur, ok := z.dict[member]
+	if ok {
+		if cur != score {
+			z.zsl.delete(cur, member)
+			z.zsl.insert(score, member)
+			z.dict[member] = score
+		}
+		return false
+	}
+
+	z.zsl.insert(score, member)
+	z.dict[member] = score
+	return true
+}
+
+// remove deletes member, returns true if member existed
+func (z *zset) remove(member string) bool {
+	score, ok := z.dict[member]
+	if !ok {
+		return false
+	}
+
+	z.zsl.delete(score, member)
+	delete(z.dict, member)
+	return true
+}
+
+// rank returns 0-based member rank ordered by score
+func (z *zset) rank(member string) (int, bool) {
+	score, ok := z.dict[member]
+	if !ok {
+		return 0, false
+	}
+
+	return z.zsl.rank(score, member) - 1, true
+}
+
+// rangeByRank returns members with 0-based ranks from start to stop inclusive, negative ranks count from the end
+func (z *zset) rangeByRank(start, stop int) []ZMember {
+	l := z.len()
+	if start < 0 {
+		start += l
+	}
+	if stop < 0 {
+		stop += l
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= l {
+		stop = l - 1
+	}
+	if start > stop || start >= l {
+		return []ZMember{}
+	}
+
+	res := make([]ZMember, 0, stop-start+1)
+	for n := z.zsl.byRank(start + 1); n != nil && len(res) < cap(res); n = n.level[0].forward {
+		res = append(res, ZMember{Member: n.member, Score: n.score})
+	}
+	return res
+}
+
+// rangeByScore returns members with score from min to max inclusive
+func (z *zset) rangeByScore(min, max float64) []ZMember {
+	res := []ZMember{}
+	for n := z.zsl.firstFrom(min); n != nil && n.score <= max; n = n.level[0].forward {
+		res = append(res, ZMember{Member: n.member, Score: n.score})
+	}
+	return res
+}
+
+// zskiplistNode is a skiplist node
+type zskiplistNode struct {
+	member   string
+	score    float64
+	backward *zskiplistNode
+	level    []zskiplistLevel
+}
+
+// zskiplistLevel is a node link on one skiplist level
+type zskiplistLevel struct {
+	forward *zskiplistNode
+	span    int
+}
+
+// zskiplist is a skiplist with spans for rank calculation
+type zskiplist struct {
+	header *zskiplistNode
+	tail   *zskiplistNode
+	length int
+	level  int
+}
+
+// newZskiplist returns new empty skiplist
+func newZskiplist() *zskiplist {
+	return &zskiplist{
+		header: &zskiplistNode{level: make([]zskiplistLevel, zslMaxLevel)},
+		level:  1,
+	}
+}
+
+// zslLess returns true if node with score a and member am precedes node with score b and member bm
+func zslLess(a float64, am string, b float64, bm string) bool {
+	return a < b || (a == b && am < bm)
+}
+
+// randomLevel returns level for new node
+func randomLevel() int {
+	level := 1
+	for level < zslMaxLevel && rand.Float64() < zslProbability {
+		level++
+	}
+	return level
+}
+
+// insert adds new node, member must not be in the list
+func (zsl *zskiplist) insert(score float64, member string) {
+	var update [zslMaxLevel]*zskiplistNode
+	var rank [zslMaxLevel]int
+
+	x := zsl.header
+	for i := zsl.level - 1; i >= 0; i-- {
+		if i < zsl.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for f := x.level[i].forward; f != nil && zslLess(f.score, f.member, score, member); f = x.level[i].forward {
+			rank[i] += x.level[i].span
+			x = f
+		}
+		update[i] = x
+	}
+
+	level := randomLevel()
+	if level > zsl.level {
+		for i := zsl.level; i < level; i++ {
+			rank[i] = 0
+			update[i] = zsl.header
+			update[i].level[i].span = zsl.length
+		}
+		zsl.level = level
+	}
+
+	x = &zskiplistNode{
+		member: member,
+		score:  score,
+		level:  make([]zskiplistLevel, level),
+	}
+	for i := 0; i < level; i++ {
+		x.level[i].forward = update[i].level[i].forward
+		update[i].level[i].forward = x
+
+		x.level[i].span = update[i].level[i].span - (rank[0] - rank[i])
+		update[i].level[i].span = rank[0] - rank[i] + 1
+	}
+
+	for i := level; i < zsl.level; i++ {
+		update[i].level[i].span++
+	}
+
+	if update[0] != zsl.header {
+		x.backward = update[0]
+	}
+	if x.level[0].forward != nil {
+		x.level[0].forward.backward = x
+	} else {
+		zsl.tail = x
+	}
+	zsl.length++
+}
+
+// delete removes node with score and member
+func (zsl *zskiplist) delete(score float64, member string) bool {
+	var update [zslMaxLevel]*zskiplistNode
+
+	x := zsl.header
+	for i := zsl.level - 1; i >= 0; i-- {
+		for f := x.level[i].forward; f != nil && zslLess(f.score, f.member, score, member); f = x.level[i].forward {
+			x = f
+		}
+		update[i] = x
+	}
+
+	x = x.level[0].forward
+	if x == nil || x.score != score || x.member != member {
+		return false
+	}
+
+	for i := 0; i < zsl.level; i++ {
+		if update[i].level[i].forward == x {
+			update[i].level[i].span += x.level[i].span - 1
+			update[i].level[i].forward = x.level[i].forward
+		} else {
+			update[i].level[i].span--
+		}
+	}
+
+	if x.level[0].forward != nil {
+		x.level[0].forward.backward = x.backward
+	} else {
+		zsl.tail = x.backward
+	}
+
+	for zsl.level > 1 && zsl.header.level[zsl.level-1].forward == nil {
+		zsl.level--
+	}
+	zsl.length--
+	return true
+}
+
+// rank returns 1-based rank of node with score and member or 0 if it's missing
+func (zsl *zskiplist) rank(score float64, member string) int {
+	rank := 0
+	x := zsl.header
+	for i := zsl.level - 1; i >= 0; i-- {
+		for f := x.level[i].forward; f != nil && !zslLess(score, member, f.score, f.member); f = x.level[i].forward {
+			rank += x.level[i].span
+			x = f
+		}
+		if x != zsl.header && x.score == score && x.member == member {
+			return rank
+		}
+	}
+	return 0
+}
+
+// byRank returns node by 1-based rank
+func (zsl *zskiplist) byRank(rank int) *zskiplistNode {
+	traversed := 0
+	x := zsl.header
+	for i := zsl.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil && traversed+x.level[i].span <= rank {
+			traversed += x.level[i].span
+			x = x.level[i].forward
+		}
+		if traversed == rank {
+			return x
+		}
+	}
+	return nil
+}
+
+// firstFrom returns first node with score not less than min
+func (zsl *zskiplist) firstFrom(min float64) *zskiplistNode {
+	x := zsl.header
+	for i := zsl.level - 1; i >= 0; i-- {
+		for f := x.level[i].forward; f != nil && f.score < min; f = x.level[i].forward {
+			x = f
+		}
+	}
+	return x.level[0].forward
+}
@@ -0,0 +1,258 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// maxBatch is a max count of spans in export request
+	maxBatch = 512
+	// maxQueue is a max count of spans waiting for export, new spans are dropped if queue is full
+	maxQueue = 4096
+	// exportInterval is an interval of export of incomplete batch
+	exportInterval = time.Second
+)
+
+// Exporter sends spans in batches to OTLP/HTTP collector in JSON encoding
+type Exporter struct {
+	// dropped is a count of spans dropped due to full queue, it's first to be aligned for atomic operations
+	dropped uint64
+
+	endpoint string
+	service  string
+	client   *http.Client
+
+	spans   chan *Span
+	flushes chan chan struct{}
+	done    chan struct{}
+}
+
+// NewExporter returns exporter of service spans to collector endpoint like http://localhost:4318/v1/traces,
+// nil client means default client with timeout
+func NewExporter(endpoint, service string, client *http.Client) *Exporter {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	e := &Exporter{
+		endpoint: endpoint,
+		service:  service,
+		client:   client,
+		spans:    make(chan *Span, maxQueue),
+		flushes:  make(chan chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+// add queues span for export
+func (e *Exporter) add(s *Span) {
+	select {
+	case e.spans <- s:
+	default:
+		atomic.AddUint64(&e.dropped, 1)
+	}
+}
+
+// Dropped returns count of spans dropped due to full queue
+func (e *Exporter) Dropped() uint64 {
+	return atomic.LoadUint64(&e.dropped)
+}
+
+// Flush exports queued spans and waits for completion
+func (e *Exporter) Flush() {
+	ch := make(chan struct{})
+	select {
+	case e.flushes <- ch:
+		<-ch
+	case <-e.done:
+	}
+}
+
+// Shutdown exports queued spans and stops exporter, spans ended after it are dropped
+func (e *Exporter) Shutdown() {
+	e.Flush()
+	close(e.done)
+}
+
+// run exports spans by full batches, by interval and by flush requests
+func (e *Exporter) run() {
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, maxBatch)
+	export := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := e.export(batch); err != nil {
+			log.Print("spans export failed: ", err)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case s := <-e.spans:
+			batch = append(batch, s)
+			if len(batch) == maxBatch {
+				export()
+			}
+		case <-ticker.C:
+			export()
+		case ch := <-e.flushes:
+			for n := len(e.spans); n > 0; n-- {
+				batch = append(batch, <-e.spans)
+				if len(batch) == maxBatch {
+					export()
+				}
+			}
+			export()
+			close(ch)
+		case <-e.done:
+			return
+		}
+	}
+}
+
+// export sends spans to collector
+func (e *Exporter) export(spans []*Span) error {
+	payload, err := json.Marshal(e.request(spans))
+	if err != nil {
+		return err
+	}
+
+	resp, err := e.client.Post(e.endpoint, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("collector responded %s", resp.Status)
+	}
+	return nil
+}
+
+// OTLP JSON objects, see opentelemetry-proto trace/v1 and common/v1
+type (
+	ExportRequest struct {
+		ResourceSpans []ResourceSpans `json:"resourceSpans"`
+	}
+
+	ResourceSpans struct {
+		Resource   Resource     `json:"resource"`
+		ScopeSpans []ScopeSpans `json:"scopeSpans"`
+	}
+
+	Resource struct {
+		Attributes []KeyValue `json:"attributes"`
+	}
+
+	ScopeSpans struct {
+		Scope Scope      `json:"scope"`
+		Spans []SpanData `json:"spans"`
+	}
+
+	Scope struct {
+		Name string `json:"name"`
+	}
+
+	SpanData struct {
+		TraceID           string     `json:"traceId"`
+		SpanID            string     `json:"spanId"`
+		ParentSpanID      string     `json:"parentSpanId,omitempty"`
+		Name              string     `json:"name"`
+		Kind              SpanKind   `json:"kind"`
+		StartTimeUnixNano string     `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+		Attributes        []KeyValue `json:"attributes,omitempty"`
+		Status            Status     `json:"status"`
+	}
+
+	// Status code is 0 if unset or 2 on error
+	Status struct {
+		Code    int    `json:"code,omitempty"`
+		Message string `json:"message,omitempty"`
+	}
+
+	KeyValue struct {
+		Key   string   `json:"key"`
+		Value AnyValue `json:"value"`
+	}
+
+	// AnyValue has one of values set, integer is encoded as string
+	AnyValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+	}
+)
+
+// statusError is a span status code of failed operation
+const statusError = 2
+
+// request returns export request of spans
+func (e *Exporter) request(spans []*Span) ExportRequest {
+	data := make([]SpanData, len(spans))
+	for i, s := range spans {
+		d := SpanData{
+			TraceID:           s.context.TraceID.String(),
+			SpanID:            s.context.SpanID.String(),
+			Name:              s.name,
+			Kind:              s.kind,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+		}
+		if s.parent != (SpanID{}) {
+			d.ParentSpanID = s.parent.String()
+		}
+		for _, a := range s.attrs {
+			d.Attributes = append(d.Attributes, keyValue(a.key, a.value))
+		}
+		if s.err != "" {
+			d.Status = Status{Code: statusError, Message: s.err}
+		}
+		data[i] = d
+	}
+
+	return ExportRequest{ResourceSpans: []ResourceSpans{{
+		Resource:   Resource{Attributes: []KeyValue{keyValue("service.name", e.service)}},
+		ScopeSpans: []ScopeSpans{{Scope: Scope{Name: "keyval"}, Spans: data}},
+	}}}
+}
+
+// keyValue returns OTLP attribute, unsupported values are formatted as strings
+func keyValue(key string, value interface{}) KeyValue {
+	var v AnyValue
+	switch val := value.(type) {
+	case string:
+		v.StringValue = &val
+	case bool:
+		v.BoolValue = &val
+	case int:
+		s := strconv.Itoa(val)
+		v.IntValue = &s
+	case int64:
+		s := strconv.FormatInt(val, 10)
+		v.IntValue = &s
+	case float64:
+		v.DoubleValue = &val
+	default:
+		s := fmt.Sprint(val)
+		v.StringValue = &s
+	}
+	return KeyValue{Key: key, Value: v}
+}
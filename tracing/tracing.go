@@ -0,0 +1,272 @@
+// Package tracing records spans of requests and exports them by OTLP,
+// trace context is propagated by W3C traceparent header
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	mrand "math/rand"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// TraceparentHeader is a W3C trace context header
+const TraceparentHeader = "traceparent"
+
+var errorWrongTraceparent = errors.New("wrong traceparent")
+
+// TraceID is a trace identifier
+type TraceID [16]byte
+
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SpanID is a span identifier
+type SpanID [8]byte
+
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SpanContext is a span identity propagated to children and remote services
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid returns true if trace and span ids aren't zero
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != TraceID{} && sc.SpanID != SpanID{}
+}
+
+// Traceparent returns traceparent header value of span context
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+// ParseTraceparent parses traceparent header value of version 00, future versions are parsed by known fields
+func ParseTraceparent(h string) (SpanContext, error) {
+	var sc SpanContext
+	parts := strings.Split(strings.TrimSpace(h), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return sc, errorWrongTraceparent
+	}
+
+	var version, flags [1]byte
+	if err := decodeHex(version[:], parts[0]); err != nil {
+		return sc, err
+	}
+	if err := decodeHex(sc.TraceID[:], parts[1]); err != nil {
+		return sc, err
+	}
+	if err := decodeHex(sc.SpanID[:], parts[2]); err != nil {
+		return sc, err
+	}
+	if err := decodeHex(flags[:], parts[3]); err != nil {
+		return sc, err
+	}
+	if !sc.IsValid() {
+		return sc, errorWrongTraceparent
+	}
+
+	sc.Sampled = flags[0]&1 == 1
+	return sc, nil
+}
+
+// decodeHex decodes lowercase hex string of exactly len(dst) bytes
+func decodeHex(dst []byte, s string) error {
+	if len(s) != hex.EncodedLen(len(dst)) || strings.ToLower(s) != s {
+		return errorWrongTraceparent
+	}
+	if _, err := hex.Decode(dst, []byte(s)); err != nil {
+		return errorWrongTraceparent
+	}
+	return nil
+}
+
+// Extract returns span context from request headers, it's invalid if header is missing or wrong
+func Extract(h http.Header) SpanContext {
+	sc, _ := ParseTraceparent(h.Get(TraceparentHeader))
+	return sc
+}
+
+// Inject sets request headers by span context of ctx if it has span
+func Inject(ctx context.Context, h http.Header) {
+	if s := SpanFromContext(ctx); s != nil {
+		h.Set(TraceparentHeader, s.context.Traceparent())
+	}
+}
+
+// SpanKind is a role of span in trace, values are the same as in OTLP
+type SpanKind int
+
+const (
+	KindInternal SpanKind = iota + 1
+	KindServer
+	KindClient
+)
+
+// attribute is a span attribute, value is string, bool, integer or float
+type attribute struct {
+	key   string
+	value interface{}
+}
+
+// Span is an operation of trace, it must be used by one goroutine.
+// Methods of nil span do nothing, so unsampled operations need no checks
+type Span struct {
+	tracer  *Tracer
+	context SpanContext
+	parent  SpanID
+	name    string
+	kind    SpanKind
+	start   time.Time
+	end     time.Time
+	attrs   []attribute
+	err     string
+}
+
+// Context returns span context
+func (s *Span) Context() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.context
+}
+
+// SetAttribute sets attribute of span
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.attrs = append(s.attrs, attribute{key, value})
+}
+
+// SetError marks span as failed by err
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.err = err.Error()
+}
+
+// End finishes span and passes it to exporter
+func (s *Span) End() {
+	if s == nil || !s.end.IsZero() {
+		return
+	}
+	s.end = time.Now()
+	if s.tracer.exporter != nil {
+		s.tracer.exporter.add(s)
+	}
+}
+
+// spanContextKey is a context key of the current span
+type spanContextKey struct{}
+
+// SpanFromContext returns the current span of ctx or nil
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	s, _ := ctx.Value(spanContextKey{}).(*Span)
+	return s
+}
+
+// Start starts internal span as a child of the current span of ctx,
+// it returns nil span if ctx has no span, so operations are traced only in traced requests
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	return start(ctx, name, KindInternal)
+}
+
+// StartClient starts client span of remote call as a child of the current span of ctx
+func StartClient(ctx context.Context, name string) (context.Context, *Span) {
+	return start(ctx, name, KindClient)
+}
+
+func start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	parent := SpanFromContext(ctx)
+	if parent == nil {
+		return ctx, nil
+	}
+	return parent.tracer.newSpan(ctx, name, kind, parent.context)
+}
+
+// Tracer starts root spans of service, spans are exported by exporter if it's set
+type Tracer struct {
+	// ratio are bits of fraction of sampled traces, it's first to be aligned for atomic operations
+	ratio uint64
+
+	service  string
+	exporter *Exporter
+}
+
+// NewTracer returns tracer of service sampling ratio of new traces, spans are dropped if exporter is nil
+func NewTracer(service string, ratio float64, exporter *Exporter) *Tracer {
+	t := &Tracer{service: service, exporter: exporter}
+	t.SetRatio(ratio)
+	return t
+}
+
+// SetRatio changes fraction of sampled new traces, remote traces are sampled by parent
+func (t *Tracer) SetRatio(ratio float64) {
+	atomic.StoreUint64(&t.ratio, math.Float64bits(ratio))
+}
+
+// Start starts span as a child of remote span if it's valid or as a root of new trace.
+// Nil tracer and unsampled trace return nil span
+func (t *Tracer) Start(ctx context.Context, name string, kind SpanKind, remote SpanContext) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+
+	if !remote.IsValid() {
+		remote = SpanContext{Sampled: mrand.Float64() < math.Float64frombits(atomic.LoadUint64(&t.ratio))}
+		randomID(remote.TraceID[:])
+	}
+	return t.newSpan(ctx, name, kind, remote)
+}
+
+// newSpan starts span of parent trace
+func (t *Tracer) newSpan(ctx context.Context, name string, kind SpanKind, parent SpanContext) (context.Context, *Span) {
+	if !parent.Sampled {
+		return ctx, nil
+	}
+
+	s := &Span{
+		tracer:  t,
+		context: SpanContext{TraceID: parent.TraceID, Sampled: true},
+		parent:  parent.SpanID,
+		name:    name,
+		kind:    kind,
+		start:   time.Now(),
+	}
+	randomID(s.context.SpanID[:])
+	return context.WithValue(ctx, spanContextKey{}, s), s
+}
+
+// randomID fills id by random non-zero bytes
+func randomID(id []byte) {
+	for {
+		if _, err := rand.Read(id); err != nil {
+			panic(fmt.Sprint("random id: ", err))
+		}
+		for _, b := range id {
+			if b != 0 {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package tracing_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/alexxeis/keyval/api"
+	"github.com/alexxeis/keyval/api/client"
+	"github.com/alexxeis/keyval/cluster"
+	"github.com/alexxeis/keyval/tracing"
+	"github.com/gorilla/mux"
+)
+
+// collector is an in-process OTLP collector keeping received spans
+type collector struct {
+	*httptest.Server
+
+	mu    sync.Mutex
+	spans []tracing.SpanData
+	names []string
+}
+
+func newCollector(t *testing.T) *collector {
+	c := &collector{}
+	c.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/traces" {
+			t.Error("wrong request ", r.Method, r.URL)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Error("wrong content type ", ct)
+		}
+
+		var req tracing.ExportRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for _, rs := range req.ResourceSpans {
+			for _, a := range rs.Resource.Attributes {
+				if a.Key == "service.name" {
+					c.names = append(c.names, *a.Value.StringValue)
+				}
+			}
+			for _, ss := range rs.ScopeSpans {
+				c.spans = append(c.spans, ss.Spans...)
+			}
+		}
+	}))
+	return c
+}
+
+func TestParseTraceparent(t *testing.T) {
+	h := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, err := tracing.ParseTraceparent(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanID.String() != "00f067aa0ba902b7" || !sc.Sampled {
+		t.Errorf("wrong span context %+v", sc)
+	}
+	if sc.Traceparent() != h {
+		t.Errorf("expected %s, got %s", h, sc.Traceparent())
+	}
+
+	// test future version with extra fields
+	if _, err := tracing.ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra"); err != nil {
+		t.Error(err)
+	}
+
+	for _, h := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902bx-01",
+	} {
+		if _, err := tracing.ParseTraceparent(h); err == nil {
+			t.Errorf("no error for %q", h)
+		}
+	}
+}
+
+func TestTracer_Start(t *testing.T) {
+	tracer := tracing.NewTracer("test", 1, nil)
+
+	// test spans aren't started out of traced request
+	if _, span := tracing.Start(context.Background(), "op"); span != nil {
+		t.Error("span without parent")
+	}
+	var nilTracer *tracing.Tracer
+	if _, span := nilTracer.Start(context.Background(), "op", tracing.KindServer, tracing.SpanContext{}); span != nil {
+		t.Error("span of nil tracer")
+	}
+
+	remote, _ := tracing.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx, root := tracer.Start(context.Background(), "root", tracing.KindServer, remote)
+	if root.Context().TraceID != remote.TraceID || root.Context().SpanID == remote.SpanID {
+		t.Errorf("wrong root span %+v", root.Context())
+	}
+
+	_, child := tracing.Start(ctx, "child")
+	if child.Context().TraceID != remote.TraceID || child.Context().SpanID == root.Context().SpanID {
+		t.Errorf("wrong child span %+v", child.Context())
+	}
+
+	h := http.Header{}
+	tracing.Inject(ctx, h)
+	if sc := tracing.Extract(h); sc != root.Context() {
+		t.Errorf("expected injected %+v, got %+v", root.Context(), sc)
+	}
+
+	// test unsampled remote trace and zero ratio
+	remote.Sampled = false
+	if _, span := tracer.Start(context.Background(), "root", tracing.KindServer, remote); span != nil {
+		t.Error("span of unsampled trace")
+	}
+	tracer.SetRatio(0)
+	if _, span := tracer.Start(context.Background(), "root", tracing.KindServer, tracing.SpanContext{}); span != nil {
+		t.Error("span of unsampled trace")
+	}
+}
+
+func TestExporter(t *testing.T) {
+	c := newCollector(t)
+	defer c.Close()
+
+	e := tracing.NewExporter(c.URL+"/v1/traces", "keyval", nil)
+	defer e.Shutdown()
+	tracer := tracing.NewTracer("keyval", 1, e)
+
+	ctx, root := tracer.Start(context.Background(), "GET /get/{key}", tracing.KindServer, tracing.SpanContext{})
+	root.SetAttribute("http.status_code", 200)
+	root.SetAttribute("keyval.key", "k")
+	_, child := tracing.Start(ctx, "storage.rlock")
+	child.SetError(errors.New("failed"))
+	child.End()
+	root.End()
+	// test span is exported once
+	root.End()
+
+	e.Flush()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.spans) != 2 || len(c.names) != 1 || c.names[0] != "keyval" {
+		t.Fatalf("wrong exported spans %+v of %v", c.spans, c.names)
+	}
+
+	ch, r := c.spans[0], c.spans[1]
+	if r.Name != "GET /get/{key}" || r.Kind != tracing.KindServer || r.ParentSpanID != "" || r.SpanID != root.Context().SpanID.String() {
+		t.Errorf("wrong root span %+v", r)
+	}
+	if r.StartTimeUnixNano == "" || r.StartTimeUnixNano > r.EndTimeUnixNano {
+		t.Errorf("wrong root span time %s - %s", r.StartTimeUnixNano, r.EndTimeUnixNano)
+	}
+	if len(r.Attributes) != 2 || *r.Attributes[0].Value.IntValue != "200" || *r.Attributes[1].Value.StringValue != "k" {
+		t.Errorf("wrong root span attributes %+v", r.Attributes)
+	}
+	if ch.Name != "storage.rlock" || ch.Kind != tracing.KindInternal || ch.TraceID != r.TraceID || ch.ParentSpanID != r.SpanID {
+		t.Errorf("wrong child span %+v", ch)
+	}
+	if ch.Status.Code != 2 || ch.Status.Message != "failed" {
+		t.Errorf("wrong child span status %+v", ch.Status)
+	}
+}
+
+func TestTrace_EndToEnd(t *testing.T) {
+	c := newCollector(t)
+	defer c.Close()
+
+	e := tracing.NewExporter(c.URL+"/v1/traces", "keyval", nil)
+	defer e.Shutdown()
+	tracer := tracing.NewTracer("keyval", 1, e)
+
+	cl := cluster.NewCluster(4, 0)
+	defer cl.Shutdown()
+	router := mux.NewRouter()
+	router.Use(api.Tracing(tracer))
+	api.NewHandler(cl).Register(router)
+	server := httptest.NewServer(router)
+
+	// client application traces request and passes trace context to keyval
+	ctx, root := tracer.Start(context.Background(), "app", tracing.KindInternal, tracing.SpanContext{})
+	kv, err := client.NewClient(client.WithBaseURL(server.URL), client.WithHTTPClient(server.Client()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := kv.Set(ctx, "k", &api.SetParams{Value: "v"}); err != nil {
+		t.Fatal(err)
+	}
+	root.End()
+	// server span is ended after response, close waits for it
+	server.Close()
+
+	e.Flush()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	spans := make(map[string]tracing.SpanData)
+	for _, s := range c.spans {
+		if s.TraceID != root.Context().TraceID.String() {
+			t.Errorf("span %s of other trace", s.Name)
+		}
+		spans[s.Name] = s
+	}
+
+	parents := map[string]string{
+		"keyval POST":     "app",
+		"POST /set/{key}": "keyval POST",
+		"storage.lock":    "POST /set/{key}",
+	}
+	for name, parent := range parents {
+		s, ok := spans[name]
+		if !ok {
+			t.Errorf("missing span %s in %v", name, c.spans)
+			continue
+		}
+		if s.ParentSpanID != spans[parent].SpanID {
+			t.Errorf("expected parent of %s is %s", name, parent)
+		}
+	}
+
+	lock := spans["storage.lock"]
+	if len(lock.Attributes) != 1 || lock.Attributes[0].Key != "keyval.partition" || lock.Attributes[0].Value.IntValue == nil {
+		t.Errorf("wrong lock span attributes %+v", lock.Attributes)
+	}
+}